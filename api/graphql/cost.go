@@ -0,0 +1,123 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/pkg/errors"
+)
+
+var (
+	errTooDeep   = errors.New("query is too deep")
+	errTooCostly = errors.New("query is too costly")
+)
+
+// cost walks the operation and returns its depth and cost. Every field
+// costs one and the cost of the selections of a list field is
+// multiplied by the number of items it asks for (first). Introspection
+// fields don't touch the database and are free.
+func cost(
+	doc *ast.Document,
+	operation string,
+	vars map[string]interface{},
+) (int, int) {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var op *ast.OperationDefinition
+	for _, d := range doc.Definitions {
+		switch d := d.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+
+		case *ast.OperationDefinition:
+			if op == nil || (d.Name != nil && d.Name.Value == operation) {
+				op = d
+			}
+		}
+	}
+
+	if op == nil {
+		return 0, 0
+	}
+
+	w := &costWalker{fragments: fragments, vars: vars}
+	return w.selections(op.SelectionSet, 1)
+}
+
+type costWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	vars      map[string]interface{}
+}
+
+func (w *costWalker) selections(set *ast.SelectionSet, depth int) (int, int) {
+	if set == nil {
+		return depth - 1, 0
+	}
+
+	maxDepth, total := depth, 0
+	add := func(d, c int) {
+		if d > maxDepth {
+			maxDepth = d
+		}
+		total += c
+	}
+
+	for _, s := range set.Selections {
+		switch s := s.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+
+			d, c := w.selections(s.SelectionSet, depth+1)
+			add(d, 1+c*w.first(s))
+
+		case *ast.InlineFragment:
+			add(w.selections(s.SelectionSet, depth))
+
+		case *ast.FragmentSpread:
+			// Validation rejects the fragment cycles before the cost is
+			// computed.
+			if f, ok := w.fragments[s.Name.Value]; ok {
+				add(w.selections(f.SelectionSet, depth))
+			}
+		}
+	}
+
+	return maxDepth, total
+}
+
+// first returns the number of items a field asks for, one for the
+// fields that aren't lists.
+func (w *costWalker) first(f *ast.Field) int {
+	for _, a := range f.Arguments {
+		if a.Name.Value != argFirst {
+			continue
+		}
+
+		switch v := a.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				return clampFirst(n)
+			}
+
+		case *ast.Variable:
+			if n, ok := w.vars[v.Name.Value].(float64); ok {
+				return clampFirst(int(n))
+			}
+			if n, ok := w.vars[v.Name.Value].(int); ok {
+				return clampFirst(n)
+			}
+		}
+
+		// A variable without a value may have any default, assume the
+		// most.
+		return maxFirst
+	}
+
+	if listFields[f.Name.Value] {
+		return defaultFirst
+	}
+
+	return 1
+}
@@ -0,0 +1,122 @@
+// Package graphql serves the explorer data model over GraphQL. The
+// relations are resolved with per-query loaders batching the lookups of
+// a level into one query, and queries over the depth or cost limits are
+// rejected before they run.
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	errMissingArgument = errors.New("missing argument")
+	errInvalidRole     = errors.New("invalid participant role")
+	errInvalidType     = errors.New("invalid transaction type")
+	errInvalidSkip     = errors.New("skip too large")
+)
+
+// Config defines the GraphQL limits.
+type Config struct {
+	// MaxDepth is the deepest field nesting a query may have.
+	MaxDepth int `yaml:"max_depth"`
+	// MaxCost is the highest cost a query may have, the number of
+	// fields it resolves when every list returns all the items it asks
+	// for.
+	MaxCost int `yaml:"max_cost"`
+}
+
+// DefaultConfig returns the GraphQL configuration used when a field is
+// not set.
+func DefaultConfig() Config {
+	return Config{
+		MaxDepth: 10,
+		MaxCost:  20000,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.MaxDepth == 0 {
+		c.MaxDepth = d.MaxDepth
+	}
+
+	if c.MaxCost == 0 {
+		c.MaxCost = d.MaxCost
+	}
+
+	return c
+}
+
+// Request is a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Executor runs the GraphQL requests against the database.
+type Executor struct {
+	cfg    Config
+	db     *gorm.DB
+	schema gql.Schema
+}
+
+// New returns the new instance of Executor.
+func New(db *gorm.DB, cfg Config) (*Executor, error) {
+	schema, err := newSchema(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Executor{
+		cfg:    cfg.withDefaults(),
+		db:     db,
+		schema: schema,
+	}, nil
+}
+
+// Execute validates the request, checks it against the limits and runs
+// it.
+func (e *Executor) Execute(ctx context.Context, req *Request) *gql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(req.Query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if vr := gql.ValidateDocument(&e.schema, doc, nil); !vr.IsValid {
+		return &gql.Result{Errors: vr.Errors}
+	}
+
+	depth, c := cost(doc, req.OperationName, req.Variables)
+	if depth > e.cfg.MaxDepth {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(
+			errors.Wrapf(errTooDeep, "depth %d, max %d", depth, e.cfg.MaxDepth),
+		)}
+	}
+
+	if c > e.cfg.MaxCost {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(
+			errors.Wrapf(errTooCostly, "cost %d, max %d", c, e.cfg.MaxCost),
+		)}
+	}
+
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, newLoaders(e.db)),
+	})
+}
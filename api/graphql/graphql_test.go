@@ -0,0 +1,222 @@
+package graphql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/database/sqlite"
+)
+
+func TestCost(t *testing.T) {
+	testCases := []struct {
+		name      string
+		query     string
+		vars      map[string]interface{}
+		wantDepth int
+		wantCost  int
+	}{
+		{
+			name:      "single field",
+			query:     `{ chainStatus { currentSlot finalizedSlot } }`,
+			wantDepth: 2,
+			wantCost:  3,
+		},
+		{
+			name:      "list multiplies its selections",
+			query:     `{ blocks(first: 10) { slot transactions(first: 5) { hash } } }`,
+			wantDepth: 3,
+			wantCost:  1 + 10*(1+1+5*1),
+		},
+		{
+			name:      "list without first uses the default",
+			query:     `{ blocks { slot } }`,
+			wantDepth: 2,
+			wantCost:  1 + defaultFirst,
+		},
+		{
+			name:      "first is capped",
+			query:     `{ blocks(first: 100000) { slot } }`,
+			wantDepth: 2,
+			wantCost:  1 + maxFirst,
+		},
+		{
+			name:      "first from a variable",
+			query:     `query q($n: Int) { blocks(first: $n) { slot } }`,
+			vars:      map[string]interface{}{"n": float64(3)},
+			wantDepth: 2,
+			wantCost:  1 + 3,
+		},
+		{
+			name:      "first from a missing variable",
+			query:     `query q($n: Int) { blocks(first: $n) { slot } }`,
+			wantDepth: 2,
+			wantCost:  1 + maxFirst,
+		},
+		{
+			name: "fragments",
+			query: `
+				{ block(slot: 1) { ...b ... on Block { hash } } }
+				fragment b on Block { transactions(first: 2) { hash } }
+			`,
+			wantDepth: 3,
+			wantCost:  1 + (1 + 2*1) + 1,
+		},
+		{
+			name:      "introspection is free",
+			query:     `{ __schema { types { name } } chainStatus { currentSlot } }`,
+			wantDepth: 2,
+			wantCost:  2,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: c.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			depth, cost := cost(doc, "", c.vars)
+			if depth != c.wantDepth || cost != c.wantCost {
+				t.Errorf("depth, cost = %d, %d, want %d, %d",
+					depth, cost, c.wantDepth, c.wantCost)
+			}
+		})
+	}
+}
+
+func TestLoader(t *testing.T) {
+	var batches [][]uint64
+	l := newLoader(func(keys []uint64) (map[uint64]string, error) {
+		batches = append(batches, keys)
+		vs := make(map[uint64]string)
+		for _, k := range keys {
+			if k != 0 {
+				vs[k] = string(rune('a' + k))
+			}
+		}
+		return vs, nil
+	})
+
+	a, b, again, missing := l.load(1), l.load(2), l.load(1), l.load(0)
+	for _, want := range []struct {
+		load func() (string, error)
+		v    string
+	}{
+		{a, "b"},
+		{b, "c"},
+		{again, "b"},
+		{missing, ""},
+	} {
+		v, err := want.load()
+		if err != nil || v != want.v {
+			t.Fatalf("load = %q, %v, want %q", v, err, want.v)
+		}
+	}
+
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("batches = %v, want one batch of the unique keys", batches)
+	}
+
+	// Loaded keys are served from the cache.
+	if v, err := l.load(2)(); err != nil || v != "c" || len(batches) != 1 {
+		t.Fatalf("cached load = %q, %v after %d batches", v, err, len(batches))
+	}
+}
+
+func TestContractTransactions(t *testing.T) {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &orm.Account{PublicKey: "owner"}
+	if err := db.Create(a).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	txs := make([]*orm.Transaction, 4)
+	for i := range txs {
+		txs[i] = &orm.Transaction{
+			Hash:          fmt.Sprintf("tx%d", i),
+			FromAccountID: a.ID,
+			Position:      uint64(i),
+			Raw:           []byte{},
+		}
+		if err := db.Create(txs[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scs := make([]*orm.StorageContract, 2)
+	for i := range scs {
+		scs[i] = &orm.StorageContract{
+			CommitTransactionID: txs[i].ID,
+			OwnerID:             a.ID,
+			DepotID:             a.ID,
+		}
+		if err := db.Create(scs[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first contract lists three transactions, the second one.
+	for _, l := range []struct{ tx, sc int }{{0, 0}, {1, 1}, {2, 0}, {3, 0}} {
+		if err := db.Create(&orm.TransactionContract{
+			TransactionID: txs[l.tx].ID,
+			ContractID:    scs[l.sc].ID,
+		}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := int64(0)
+	if err := db.Callback().Query().After("gorm:query").Register(
+		"test:rows",
+		func(db *gorm.DB) {
+			if db.Statement.Table == "transactions" {
+				read += db.RowsAffected
+			}
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	l := newLoaders(db).contractTransactions
+	first := l.load(contractPage{contractID: scs[0].ID, first: 2})
+	second := l.load(contractPage{contractID: scs[1].ID, first: 2})
+	for _, c := range []struct {
+		load func() ([]*orm.Transaction, error)
+		want []string
+	}{
+		{first, []string{"tx3", "tx2"}},
+		{second, []string{"tx1"}},
+	} {
+		got, err := c.load()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hashes := make([]string, 0, len(got))
+		for _, tx := range got {
+			hashes = append(hashes, tx.Hash)
+		}
+
+		if fmt.Sprint(hashes) != fmt.Sprint(c.want) {
+			t.Fatalf("transactions = %v, want %v", hashes, c.want)
+		}
+	}
+
+	// Only the rows returned are read, not every linked transaction.
+	if read != 3 {
+		t.Fatalf("read %d transactions, want 3", read)
+	}
+}
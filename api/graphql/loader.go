@@ -0,0 +1,310 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+type loadersKey struct{}
+
+// loader batches the keys requested by the resolvers of a query. The
+// executor resolves the returned thunks breadth first, so every key
+// of a level is pending when the first thunk of the level runs and a
+// single fetch loads them all.
+type loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func([]K) (map[K]V, error)
+	pending []K
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](
+	fetch func([]K) (map[K]V, error),
+) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:  fetch,
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// load queues the key and returns the thunk resolving its value, the
+// zero value if there is none.
+func (l *loader[K, V]) load(key K) func() (V, error) {
+	l.mu.Lock()
+	if _, ok := l.values[key]; !ok {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if v, ok := l.values[key]; ok {
+			return v, nil
+		}
+
+		if err, ok := l.errs[key]; ok {
+			var zero V
+			return zero, err
+		}
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			vs, err := l.fetch(unique(keys))
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+				} else {
+					l.values[k] = vs[k]
+				}
+			}
+		}
+
+		if err, ok := l.errs[key]; ok {
+			var zero V
+			return zero, err
+		}
+
+		return l.values[key], nil
+	}
+}
+
+// loaders holds the loaders of a query, they cache what they fetch for
+// its lifetime only.
+type loaders struct {
+	chainStatus          *loader[uint64, *orm.ChainStatus]
+	accounts             *loader[uint64, *orm.Account]
+	blocks               *loader[uint64, *orm.Block]
+	transactions         *loader[uint64, *orm.Transaction]
+	blockTransactions    *loader[uint64, []*orm.Transaction]
+	blockAttestations    *loader[uint64, []*orm.Attestation]
+	contractTransactions *loader[contractPage, []*orm.Transaction]
+	transactionContracts *loader[uint64, []*orm.StorageContract]
+	validators           *loader[uint64, *orm.Validator]
+	accountValidators    *loader[uint64, *orm.Validator]
+	accountAuditors      *loader[uint64, *orm.Auditor]
+}
+
+// contractPage keys the latest transactions of a storage contract, up to
+// first.
+type contractPage struct {
+	contractID uint64
+	first      int
+}
+
+func newLoaders(db *gorm.DB) *loaders {
+	return &loaders{
+		chainStatus: newLoader(func(ids []uint64) (map[uint64]*orm.ChainStatus, error) {
+			return fetchByKey(
+				db.Model(&orm.ChainStatus{}).Where("id in ?", ids),
+				func(cs *orm.ChainStatus) uint64 { return cs.ID },
+			)
+		}),
+		accounts: newLoader(func(ids []uint64) (map[uint64]*orm.Account, error) {
+			return fetchByKey(
+				db.Model(&orm.Account{}).Where("id in ?", ids),
+				func(a *orm.Account) uint64 { return a.ID },
+			)
+		}),
+		blocks: newLoader(func(ids []uint64) (map[uint64]*orm.Block, error) {
+			return fetchByKey(
+				db.Model(&orm.Block{}).Where("id in ?", ids),
+				func(b *orm.Block) uint64 { return b.ID },
+			)
+		}),
+		transactions: newLoader(func(ids []uint64) (map[uint64]*orm.Transaction, error) {
+			return fetchByKey(
+				db.Model(&orm.Transaction{}).Where("id in ?", ids),
+				func(tx *orm.Transaction) uint64 { return tx.ID },
+			)
+		}),
+		blockTransactions: newLoader(func(ids []uint64) (map[uint64][]*orm.Transaction, error) {
+			return fetchGroups(
+				db.Model(&orm.Transaction{}).
+					Where("block_id in ?", ids).
+					Order("position asc"),
+				ids,
+				func(tx *orm.Transaction) uint64 { return tx.BlockID },
+			)
+		}),
+		blockAttestations: newLoader(func(ids []uint64) (map[uint64][]*orm.Attestation, error) {
+			return fetchGroups(
+				db.Model(&orm.Attestation{}).
+					Where("block_id in ?", ids).
+					Order("id asc"),
+				ids,
+				func(a *orm.Attestation) uint64 { return a.BlockID },
+			)
+		}),
+		contractTransactions: newLoader(func(keys []contractPage) (map[contractPage][]*orm.Transaction, error) {
+			// Every contract is fetched with its own limit, so the rows
+			// read stay within the query cost however many transactions
+			// the contracts have.
+			groups := make(map[contractPage][]*orm.Transaction, len(keys))
+			for _, k := range keys {
+				txs := make([]*orm.Transaction, 0)
+				if err := db.Model(&orm.Transaction{}).
+					Joins("join transaction_contracts as tc on tc.transaction_id = transactions.id").
+					Where("tc.contract_id = ? and tc.deleted_at is null", k.contractID).
+					Order("tc.id desc").
+					Limit(k.first).
+					Find(&txs).
+					Error; err != nil {
+					return nil, err
+				}
+
+				groups[k] = txs
+			}
+
+			return groups, nil
+		}),
+		transactionContracts: newLoader(func(ids []uint64) (map[uint64][]*orm.StorageContract, error) {
+			links, err := fetchLinks(db, "transaction_id in ?", ids)
+			if err != nil {
+				return nil, err
+			}
+
+			scs, err := fetchByKey(
+				db.Model(&orm.StorageContract{}).
+					Where("id in ?", linked(links, func(tc *orm.TransactionContract) uint64 {
+						return tc.ContractID
+					})),
+				func(sc *orm.StorageContract) uint64 { return sc.ID },
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			groups := make(map[uint64][]*orm.StorageContract, len(ids))
+			for _, id := range ids {
+				groups[id] = make([]*orm.StorageContract, 0)
+			}
+			for _, l := range links {
+				if sc, ok := scs[l.ContractID]; ok {
+					groups[l.TransactionID] = append(groups[l.TransactionID], sc)
+				}
+			}
+
+			return groups, nil
+		}),
+		validators: newLoader(func(indexes []uint64) (map[uint64]*orm.Validator, error) {
+			return fetchByKey(
+				db.Model(&orm.Validator{}).Where("idx in ?", indexes),
+				func(v *orm.Validator) uint64 { return v.Index },
+			)
+		}),
+		accountValidators: newLoader(func(ids []uint64) (map[uint64]*orm.Validator, error) {
+			return fetchByKey(
+				db.Model(&orm.Validator{}).Where("account_id in ?", ids),
+				func(v *orm.Validator) uint64 { return v.AccountID },
+			)
+		}),
+		accountAuditors: newLoader(func(ids []uint64) (map[uint64]*orm.Auditor, error) {
+			return fetchByKey(
+				db.Model(&orm.Auditor{}).Where("account_id in ?", ids),
+				func(a *orm.Auditor) uint64 { return a.AccountID },
+			)
+		}),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// fetchByKey runs the query and indexes the rows by key.
+func fetchByKey[T any](
+	query *gorm.DB,
+	key func(*T) uint64,
+) (map[uint64]*T, error) {
+	rows := make([]*T, 0)
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	m := make(map[uint64]*T, len(rows))
+	for _, r := range rows {
+		m[key(r)] = r
+	}
+
+	return m, nil
+}
+
+// fetchGroups runs the query and groups the rows by key, keeping their
+// order. Every requested key gets a group, empty if it has no rows.
+func fetchGroups[T any](
+	query *gorm.DB,
+	keys []uint64,
+	key func(*T) uint64,
+) (map[uint64][]*T, error) {
+	rows := make([]*T, 0)
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	m := make(map[uint64][]*T, len(keys))
+	for _, k := range keys {
+		m[k] = make([]*T, 0)
+	}
+	for _, r := range rows {
+		m[key(r)] = append(m[key(r)], r)
+	}
+
+	return m, nil
+}
+
+// fetchLinks returns the transaction_contracts rows matching the
+// condition in id order.
+func fetchLinks(
+	db *gorm.DB,
+	cond string,
+	ids []uint64,
+) ([]*orm.TransactionContract, error) {
+	links := make([]*orm.TransactionContract, 0)
+	if err := db.Model(&orm.TransactionContract{}).
+		Where(cond, ids).
+		Order("id asc").
+		Find(&links).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func linked(
+	links []*orm.TransactionContract,
+	id func(*orm.TransactionContract) uint64,
+) []uint64 {
+	ids := make([]uint64, len(links))
+	for i, l := range links {
+		ids[i] = id(l)
+	}
+
+	return unique(ids)
+}
+
+func unique[K comparable](keys []K) []K {
+	seen := make(map[K]bool, len(keys))
+	u := make([]K, 0, len(keys))
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			u = append(u, k)
+		}
+	}
+
+	return u
+}
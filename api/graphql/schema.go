@@ -0,0 +1,804 @@
+package graphql
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/sak/time/slots"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+const (
+	argFirst     = "first"
+	defaultFirst = 20
+	maxFirst     = 100
+	// maxSkip bounds the rows a page skips over, which the query cost
+	// doesn't account for.
+	maxSkip = 10000
+)
+
+// listFields are the fields returning lists, they all take a first
+// argument.
+var listFields = map[string]bool{
+	"blocks":           true,
+	"transactions":     true,
+	"attestations":     true,
+	"contracts":        true,
+	"validators":       true,
+	"auditors":         true,
+	"storageContracts": true,
+}
+
+var participantRoles = map[string]bool{
+	orm.RoleSender:    true,
+	orm.RoleRecipient: true,
+	orm.RoleOwner:     true,
+	orm.RoleDepot:     true,
+	orm.RoleAuditor:   true,
+}
+
+// uint64Type is a scalar serialized as a decimal string, for the
+// amounts not fitting in the 32-bit Int.
+var uint64Type = gql.NewScalar(gql.ScalarConfig{
+	Name:        "Uint64",
+	Description: "An unsigned 64-bit integer serialized as a decimal string.",
+	Serialize: func(value interface{}) interface{} {
+		if v, ok := value.(uint64); ok {
+			return strconv.FormatUint(v, 10)
+		}
+
+		return nil
+	},
+})
+
+func newSchema(db *gorm.DB) (gql.Schema, error) {
+	var (
+		blockType           *gql.Object
+		transactionType     *gql.Object
+		accountType         *gql.Object
+		validatorType       *gql.Object
+		auditorType         *gql.Object
+		storageContractType *gql.Object
+		attestationType     *gql.Object
+	)
+
+	chainStatusType := gql.NewObject(gql.ObjectConfig{
+		Name: "ChainStatus",
+		Fields: gql.Fields{
+			"currentSlot": field(gql.Int, func(cs *orm.ChainStatus) interface{} {
+				return currentSlot(cs)
+			}),
+			"currentEpoch": field(gql.Int, func(cs *orm.ChainStatus) interface{} {
+				return epoch(currentSlot(cs))
+			}),
+			"currentHash": field(gql.String, func(cs *orm.ChainStatus) interface{} {
+				return cs.CurrentHash
+			}),
+			"finalizedSlot": field(gql.Int, func(cs *orm.ChainStatus) interface{} {
+				return cs.FinalizedSlot
+			}),
+			"finalizedEpoch": field(gql.Int, func(cs *orm.ChainStatus) interface{} {
+				return epoch(cs.FinalizedSlot)
+			}),
+			"finalizedHash": field(gql.String, func(cs *orm.ChainStatus) interface{} {
+				return cs.FinalizedHash
+			}),
+		},
+	})
+
+	blockType = gql.NewObject(gql.ObjectConfig{
+		Name: "Block",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"slot": field(gql.Int, func(b *orm.Block) interface{} {
+					return b.Slot
+				}),
+				"epoch": field(gql.Int, func(b *orm.Block) interface{} {
+					return epoch(b.Slot)
+				}),
+				"hash": field(gql.String, func(b *orm.Block) interface{} {
+					return b.Hash
+				}),
+				"parentHash": field(gql.String, func(b *orm.Block) interface{} {
+					return b.ParentHash
+				}),
+				"stateHash": field(gql.String, func(b *orm.Block) interface{} {
+					return b.StateHash
+				}),
+				"proposerIndex": field(gql.Int, func(b *orm.Block) interface{} {
+					return b.ProposalIndex
+				}),
+				"graffiti": field(gql.String, func(b *orm.Block) interface{} {
+					return b.Graffiti
+				}),
+				"timestamp": field(gql.Int, func(b *orm.Block) interface{} {
+					return b.Timestamp
+				}),
+				"proposer": {
+					Type: validatorType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						b := p.Source.(*orm.Block)
+						if b.ProposalIndex == nil {
+							return nil, nil
+						}
+
+						return thunk(loadersFrom(p.Context).validators.load(*b.ProposalIndex)), nil
+					},
+				},
+				"finalized": {
+					Type: gql.Boolean,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						b := p.Source.(*orm.Block)
+						load := loadersFrom(p.Context).chainStatus.load(1)
+						return func() (interface{}, error) {
+							cs, err := load()
+							if err != nil || cs == nil {
+								return nil, err
+							}
+
+							return b.Slot <= cs.FinalizedSlot, nil
+						}, nil
+					},
+				},
+				"txCount": {
+					Type: gql.Int,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						b := p.Source.(*orm.Block)
+						load := loadersFrom(p.Context).blockTransactions.load(b.ID)
+						return func() (interface{}, error) {
+							txs, err := load()
+							return len(txs), err
+						}, nil
+					},
+				},
+				"transactions": {
+					Type: list(transactionType),
+					Args: listArgs(nil),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						b := p.Source.(*orm.Block)
+						return take(p, loadersFrom(p.Context).blockTransactions.load(b.ID)), nil
+					},
+				},
+				"attestations": {
+					Type: list(attestationType),
+					Args: listArgs(nil),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						b := p.Source.(*orm.Block)
+						return take(p, loadersFrom(p.Context).blockAttestations.load(b.ID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	transactionType = gql.NewObject(gql.ObjectConfig{
+		Name: "Transaction",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"hash": field(gql.String, func(tx *orm.Transaction) interface{} {
+					return tx.Hash
+				}),
+				"type": field(gql.String, func(tx *orm.Transaction) interface{} {
+					return pbc.TxType_name[tx.Type]
+				}),
+				"position": field(gql.Int, func(tx *orm.Transaction) interface{} {
+					return tx.Position
+				}),
+				"gasPrice": field(uint64Type, func(tx *orm.Transaction) interface{} {
+					return tx.GasPrice
+				}),
+				"amount": field(uint64Type, func(tx *orm.Transaction) interface{} {
+					return tx.Amount
+				}),
+				"block": {
+					Type: blockType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						tx := p.Source.(*orm.Transaction)
+						return thunk(loadersFrom(p.Context).blocks.load(tx.BlockID)), nil
+					},
+				},
+				"from": {
+					Type: accountType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						tx := p.Source.(*orm.Transaction)
+						return thunk(loadersFrom(p.Context).accounts.load(tx.FromAccountID)), nil
+					},
+				},
+				"to": {
+					Type: accountType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						tx := p.Source.(*orm.Transaction)
+						if tx.ToAccountID == 0 {
+							return nil, nil
+						}
+
+						return thunk(loadersFrom(p.Context).accounts.load(tx.ToAccountID)), nil
+					},
+				},
+				"contracts": {
+					Type: list(storageContractType),
+					Args: listArgs(nil),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						tx := p.Source.(*orm.Transaction)
+						return take(p, loadersFrom(p.Context).transactionContracts.load(tx.ID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	accountType = gql.NewObject(gql.ObjectConfig{
+		Name: "Account",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"publicKey": field(gql.String, func(a *orm.Account) interface{} {
+					return a.PublicKey
+				}),
+				"balance": field(uint64Type, func(a *orm.Account) interface{} {
+					return a.Balance
+				}),
+				"nonce": field(uint64Type, func(a *orm.Account) interface{} {
+					return a.Nonce
+				}),
+				"validator": {
+					Type: validatorType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*orm.Account)
+						return thunk(loadersFrom(p.Context).accountValidators.load(a.ID)), nil
+					},
+				},
+				"auditor": {
+					Type: auditorType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*orm.Account)
+						return thunk(loadersFrom(p.Context).accountAuditors.load(a.ID)), nil
+					},
+				},
+				// The pages of the accounts are fetched one account at a
+				// time, their cost is bounded by the query cost.
+				"transactions": {
+					Type: list(transactionType),
+					Args: listArgs(gql.FieldConfigArgument{
+						"skip": {Type: gql.Int, DefaultValue: 0},
+						"role": {Type: gql.NewList(gql.NewNonNull(gql.String))},
+					}),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*orm.Account)
+						participants := db.Model(&orm.TransactionParticipant{}).
+							Select("transaction_id").
+							Where("account_id = ?", a.ID)
+						if roles := argList(p.Args["role"]); len(roles) > 0 {
+							for _, r := range roles {
+								if !participantRoles[r] {
+									return nil, errInvalidRole
+								}
+							}
+							participants = participants.Where("role in ?", roles)
+						}
+
+						return page(
+							p,
+							db.Model(&orm.Transaction{}).
+								Where("id in (?)", participants).
+								Order("id desc"),
+							make([]*orm.Transaction, 0),
+						)
+					},
+				},
+				"contracts": {
+					Type:        list(storageContractType),
+					Description: "The storage contracts owned by the account.",
+					Args: listArgs(gql.FieldConfigArgument{
+						"skip": {Type: gql.Int, DefaultValue: 0},
+					}),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*orm.Account)
+						return page(
+							p,
+							db.Model(&orm.StorageContract{}).
+								Where("owner_id = ?", a.ID).
+								Order("id desc"),
+							make([]*orm.StorageContract, 0),
+						)
+					},
+				},
+			}
+		}),
+	})
+
+	validatorType = gql.NewObject(gql.ObjectConfig{
+		Name: "Validator",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"index": field(gql.Int, func(v *orm.Validator) interface{} {
+					return v.Index
+				}),
+				"deposit": field(uint64Type, func(v *orm.Validator) interface{} {
+					return v.Deposit
+				}),
+				"status": field(gql.String, func(v *orm.Validator) interface{} {
+					return pbc.ValidatorStatus_name[v.Status]
+				}),
+				"activationEpoch": field(gql.Int, func(v *orm.Validator) interface{} {
+					return nullEpoch(v.ActivationEpoch)
+				}),
+				"exitEpoch": field(gql.Int, func(v *orm.Validator) interface{} {
+					return nullEpoch(v.ExitEpoch)
+				}),
+				"account": {
+					Type: accountType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						v := p.Source.(*orm.Validator)
+						return thunk(loadersFrom(p.Context).accounts.load(v.AccountID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	auditorType = gql.NewObject(gql.ObjectConfig{
+		Name: "Auditor",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"deposit": field(uint64Type, func(a *orm.Auditor) interface{} {
+					return a.Deposit
+				}),
+				"status": field(gql.String, func(a *orm.Auditor) interface{} {
+					return pbc.AuditorStatus_name[a.Status]
+				}),
+				"activationEpoch": field(gql.Int, func(a *orm.Auditor) interface{} {
+					return nullEpoch(a.ActivationEpoch)
+				}),
+				"exitEpoch": field(gql.Int, func(a *orm.Auditor) interface{} {
+					return nullEpoch(a.ExitEpoch)
+				}),
+				"account": {
+					Type: accountType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*orm.Auditor)
+						return thunk(loadersFrom(p.Context).accounts.load(a.AccountID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	storageContractType = gql.NewObject(gql.ObjectConfig{
+		Name: "StorageContract",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"hash": {
+					Type:        gql.String,
+					Description: "The hash of the commit transaction.",
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						sc := p.Source.(*orm.StorageContract)
+						load := loadersFrom(p.Context).transactions.load(sc.CommitTransactionID)
+						return func() (interface{}, error) {
+							tx, err := load()
+							if err != nil || tx == nil {
+								return nil, err
+							}
+
+							return tx.Hash, nil
+						}, nil
+					},
+				},
+				"objectHash": field(gql.String, func(sc *orm.StorageContract) interface{} {
+					return sc.ObjectHash
+				}),
+				"status": field(gql.String, func(sc *orm.StorageContract) interface{} {
+					return pbc.StorageStatus_name[sc.Status]
+				}),
+				"size": field(uint64Type, func(sc *orm.StorageContract) interface{} {
+					return sc.Size
+				}),
+				"fee": field(uint64Type, func(sc *orm.StorageContract) interface{} {
+					return sc.Fee
+				}),
+				"pledge": field(uint64Type, func(sc *orm.StorageContract) interface{} {
+					return sc.Pledge
+				}),
+				"startSlot": field(gql.Int, func(sc *orm.StorageContract) interface{} {
+					return sc.StartSlot
+				}),
+				"endSlot": field(gql.Int, func(sc *orm.StorageContract) interface{} {
+					return sc.EndSlot
+				}),
+				"startEpoch": field(gql.Int, func(sc *orm.StorageContract) interface{} {
+					return epoch(sc.StartSlot)
+				}),
+				"endEpoch": field(gql.Int, func(sc *orm.StorageContract) interface{} {
+					return epoch(sc.EndSlot)
+				}),
+				"owner": {
+					Type: accountType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						sc := p.Source.(*orm.StorageContract)
+						return thunk(loadersFrom(p.Context).accounts.load(sc.OwnerID)), nil
+					},
+				},
+				"depot": {
+					Type: accountType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						sc := p.Source.(*orm.StorageContract)
+						return thunk(loadersFrom(p.Context).accounts.load(sc.DepotID)), nil
+					},
+				},
+				"auditor": {
+					Type: accountType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						sc := p.Source.(*orm.StorageContract)
+						if sc.AuditorID == 0 {
+							return nil, nil
+						}
+
+						return thunk(loadersFrom(p.Context).accounts.load(sc.AuditorID)), nil
+					},
+				},
+				"commitTransaction": {
+					Type: transactionType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						sc := p.Source.(*orm.StorageContract)
+						return thunk(loadersFrom(p.Context).transactions.load(sc.CommitTransactionID)), nil
+					},
+				},
+				"transactions": {
+					Type: list(transactionType),
+					Args: listArgs(nil),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						sc := p.Source.(*orm.StorageContract)
+						return thunk(loadersFrom(p.Context).contractTransactions.load(contractPage{
+							contractID: sc.ID,
+							first:      clampFirst(p.Args[argFirst].(int)),
+						})), nil
+					},
+				},
+			}
+		}),
+	})
+
+	attestationType = gql.NewObject(gql.ObjectConfig{
+		Name: "Attestation",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"committeeIndex": field(gql.Int, func(a *orm.Attestation) interface{} {
+					return a.CommitteeIndex
+				}),
+				"aggregationBits": field(gql.String, func(a *orm.Attestation) interface{} {
+					return a.AggregationBits
+				}),
+				"sourceEpoch": field(gql.Int, func(a *orm.Attestation) interface{} {
+					return a.SourceEpoch
+				}),
+				"sourceHash": field(gql.String, func(a *orm.Attestation) interface{} {
+					return a.SourceHash
+				}),
+				"targetEpoch": field(gql.Int, func(a *orm.Attestation) interface{} {
+					return a.TargetEpoch
+				}),
+				"targetHash": field(gql.String, func(a *orm.Attestation) interface{} {
+					return a.TargetHash
+				}),
+				"signature": field(gql.String, func(a *orm.Attestation) interface{} {
+					return a.Signature
+				}),
+				"block": {
+					Type: blockType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*orm.Attestation)
+						return thunk(loadersFrom(p.Context).blocks.load(a.BlockID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	skipArgs := gql.FieldConfigArgument{
+		"skip": {Type: gql.Int, DefaultValue: 0},
+	}
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"chainStatus": {
+				Type: chainStatusType,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return thunk(loadersFrom(p.Context).chainStatus.load(1)), nil
+				},
+			},
+			"block": {
+				Type: blockType,
+				Args: gql.FieldConfigArgument{
+					"slot": {Type: gql.Int},
+					"hash": {Type: gql.String},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					query := db.Model(&orm.Block{})
+					if slot, ok := p.Args["slot"].(int); ok {
+						query = query.Where("slot = ?", slot)
+					} else if hash, ok := p.Args["hash"].(string); ok {
+						query = query.Where("hash = ?", hash)
+					} else {
+						return nil, errMissingArgument
+					}
+
+					return findOne(query, &orm.Block{})
+				},
+			},
+			"blocks": {
+				Type: list(blockType),
+				Args: listArgs(skipArgs),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return page(
+						p,
+						db.Model(&orm.Block{}).Order("slot desc"),
+						make([]*orm.Block, 0),
+					)
+				},
+			},
+			"transaction": {
+				Type: transactionType,
+				Args: gql.FieldConfigArgument{
+					"hash": {Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return findOne(
+						db.Model(&orm.Transaction{}).
+							Where("hash = ?", p.Args["hash"]),
+						&orm.Transaction{},
+					)
+				},
+			},
+			"transactions": {
+				Type: list(transactionType),
+				Args: listArgs(gql.FieldConfigArgument{
+					"skip": {Type: gql.Int, DefaultValue: 0},
+					"type": {Type: gql.NewList(gql.NewNonNull(gql.String))},
+				}),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					query := db.Model(&orm.Transaction{}).Order("id desc")
+					if types := argList(p.Args["type"]); len(types) > 0 {
+						values := make([]int32, len(types))
+						for i, t := range types {
+							v, ok := pbc.TxType_value[t]
+							if !ok {
+								return nil, errInvalidType
+							}
+							values[i] = v
+						}
+						query = query.Where("type in ?", values)
+					}
+
+					return page(p, query, make([]*orm.Transaction, 0))
+				},
+			},
+			"account": {
+				Type: accountType,
+				Args: gql.FieldConfigArgument{
+					"publicKey": {Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return findOne(
+						db.Model(&orm.Account{}).
+							Where("public_key = ?", p.Args["publicKey"]),
+						&orm.Account{},
+					)
+				},
+			},
+			"validator": {
+				Type: validatorType,
+				Args: gql.FieldConfigArgument{
+					"index":     {Type: gql.Int},
+					"publicKey": {Type: gql.String},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					query := db.Model(&orm.Validator{})
+					if index, ok := p.Args["index"].(int); ok {
+						query = query.Where("idx = ?", index)
+					} else if pk, ok := p.Args["publicKey"].(string); ok {
+						query = query.
+							Joins("join accounts on accounts.id = validators.account_id").
+							Where("accounts.public_key = ?", pk)
+					} else {
+						return nil, errMissingArgument
+					}
+
+					return findOne(query, &orm.Validator{})
+				},
+			},
+			"validators": {
+				Type: list(validatorType),
+				Args: listArgs(skipArgs),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return page(
+						p,
+						db.Model(&orm.Validator{}).Order("idx asc"),
+						make([]*orm.Validator, 0),
+					)
+				},
+			},
+			"auditors": {
+				Type: list(auditorType),
+				Args: listArgs(skipArgs),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return page(
+						p,
+						db.Model(&orm.Auditor{}).Order("id asc"),
+						make([]*orm.Auditor, 0),
+					)
+				},
+			},
+			"storageContract": {
+				Type: storageContractType,
+				Args: gql.FieldConfigArgument{
+					"hash": {
+						Type:        gql.NewNonNull(gql.String),
+						Description: "The hash of the commit transaction.",
+					},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return findOne(
+						db.Model(&orm.StorageContract{}).
+							Joins("join transactions as t on t.id = storage_contracts.commit_transaction_id").
+							Where("t.hash = ?", p.Args["hash"]),
+						&orm.StorageContract{},
+					)
+				},
+			},
+			"storageContracts": {
+				Type: list(storageContractType),
+				Args: listArgs(skipArgs),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return page(
+						p,
+						db.Model(&orm.StorageContract{}).Order("id desc"),
+						make([]*orm.StorageContract, 0),
+					)
+				},
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: queryType})
+}
+
+// field returns a field resolved from its source by fn.
+func field[T any](typ gql.Output, fn func(T) interface{}) *gql.Field {
+	return &gql.Field{
+		Type: typ,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return fn(p.Source.(T)), nil
+		},
+	}
+}
+
+// thunk adapts a loader thunk to the executor.
+func thunk[V any](load func() (V, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		return load()
+	}
+}
+
+// take adapts a loader thunk of a list to the executor, keeping the
+// first items only.
+func take[V any](
+	p gql.ResolveParams,
+	load func() ([]V, error),
+) func() (interface{}, error) {
+	n := clampFirst(p.Args[argFirst].(int))
+	return func() (interface{}, error) {
+		items, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(items) > n {
+			items = items[:n]
+		}
+
+		return items, nil
+	}
+}
+
+// page runs the query for the page of items selected by the first and
+// skip arguments.
+func page[T any](p gql.ResolveParams, query *gorm.DB, items []T) (interface{}, error) {
+	skip, _ := p.Args["skip"].(int)
+	if skip < 0 {
+		skip = 0
+	}
+
+	if skip > maxSkip {
+		return nil, errors.Wrapf(errInvalidSkip, "max %d", maxSkip)
+	}
+
+	if err := query.
+		Offset(skip).
+		Limit(clampFirst(p.Args[argFirst].(int))).
+		Find(&items).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// findOne returns the first row of the query, nil if there is none.
+func findOne[T any](query *gorm.DB, item *T) (interface{}, error) {
+	if err := query.First(item).Error; err == gorm.ErrRecordNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func list(typ gql.Type) gql.Output {
+	return gql.NewNonNull(gql.NewList(gql.NewNonNull(typ)))
+}
+
+func listArgs(args gql.FieldConfigArgument) gql.FieldConfigArgument {
+	la := gql.FieldConfigArgument{
+		argFirst: {Type: gql.Int, DefaultValue: defaultFirst},
+	}
+	for name, a := range args {
+		la[name] = a
+	}
+
+	return la
+}
+
+func clampFirst(n int) int {
+	if n < 0 {
+		return 0
+	}
+
+	if n > maxFirst {
+		return maxFirst
+	}
+
+	return n
+}
+
+func currentSlot(cs *orm.ChainStatus) uint64 {
+	if cs.NextSlot == 0 {
+		return 0
+	}
+
+	return cs.NextSlot - 1
+}
+
+func epoch(slot uint64) uint64 {
+	return uint64(slots.ToEpoch(pbc.Slot(slot)))
+}
+
+// nullEpoch returns nil for the far future epoch, which is stored capped
+// to the signed 64-bit range.
+func nullEpoch(e uint64) interface{} {
+	if e >= math.MaxInt64 {
+		return nil
+	}
+
+	return e
+}
+
+// argList returns the items of a list argument.
+func argList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, i := range items {
+		if s, ok := i.(string); ok && strings.TrimSpace(s) != "" {
+			values = append(values, strings.TrimSpace(s))
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type base struct {
+	Hash string `json:"hash"`
+}
+
+type item struct {
+	*base
+	Amount  uint64           `json:"amount,string"`
+	Note    string           `json:"note,omitempty"`
+	Tags    []string         `json:"tags"`
+	Child   *item            `json:"child,omitempty"`
+	Extra   map[string]int32 `json:"extra"`
+	Payload json.RawMessage  `json:"payload"`
+	Skipped string           `json:"-"`
+	private string
+}
+
+func TestSchemas(t *testing.T) {
+	s := NewSchemas()
+	if ref := s.Of(reflect.TypeOf([]*item{})); ref.Type != "array" ||
+		ref.Items.Ref != "#/components/schemas/item" {
+		t.Fatalf("schema = %+v, want an array of item references", ref)
+	}
+
+	got, err := json.Marshal(s.Components()["item"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"type":"object","properties":{` +
+		`"amount":{"type":"string"},` +
+		`"child":{"$ref":"#/components/schemas/item"},` +
+		`"extra":{"type":"object","additionalProperties":{"type":"integer","format":"int32"}},` +
+		`"hash":{"type":"string"},` +
+		`"note":{"type":"string"},` +
+		`"payload":{},` +
+		`"tags":{"type":"array","items":{"type":"string"}}},` +
+		`"required":["hash","amount","tags","extra","payload"]}`
+	if string(got) != want {
+		t.Errorf("item schema =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSchemaNameCollision(t *testing.T) {
+	type Info struct {
+		Name string `json:"name"`
+	}
+
+	s := NewSchemas()
+	a, b := s.Of(reflect.TypeOf(Info{})), s.Of(reflect.TypeOf(&Info{}))
+	c := s.Of(reflect.TypeOf(struct{ Info }{}))
+	if a.Ref != b.Ref {
+		t.Errorf("refs of one type = %s, %s", a.Ref, b.Ref)
+	}
+
+	d := NewSchemas()
+	d.Add("Info", &Schema{})
+	if ref := d.Of(reflect.TypeOf(Info{})); ref.Ref != "#/components/schemas/openapi.Info" {
+		t.Errorf("ref of a taken name = %s", ref.Ref)
+	}
+
+	if _, ok := c.Properties["name"]; !ok {
+		t.Errorf("embedded struct fields = %+v, want name", c.Properties)
+	}
+}
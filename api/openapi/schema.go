@@ -0,0 +1,170 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// Schemas reflects the JSON schemas of Go types, following the
+// encoding/json rules. Named structs become component schemas referred
+// to by name.
+type Schemas struct {
+	schemas map[string]*Schema
+	types   map[reflect.Type]string
+}
+
+// NewSchemas returns an empty set of component schemas.
+func NewSchemas() *Schemas {
+	return &Schemas{
+		schemas: make(map[string]*Schema),
+		types:   make(map[reflect.Type]string),
+	}
+}
+
+// Components returns the component schemas reflected so far.
+func (s *Schemas) Components() map[string]*Schema {
+	return s.schemas
+}
+
+// Add registers a component schema built by hand.
+func (s *Schemas) Add(name string, schema *Schema) *Schema {
+	s.schemas[name] = schema
+	return Ref(name)
+}
+
+// Ref returns the reference to a component schema.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Of returns the schema of the type.
+func (s *Schemas) Of(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case rawMessageType:
+		return &Schema{}
+
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.Of(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.Of(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	}
+
+	return &Schema{}
+}
+
+// component returns the reference to the schema of a named struct. The
+// name is qualified by the package if another type already took it.
+func (s *Schemas) component(t reflect.Type) *Schema {
+	if name, ok := s.types[t]; ok {
+		return Ref(name)
+	}
+
+	name := t.Name()
+	if _, ok := s.schemas[name]; ok {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+
+	// Registered before the fields so recursive types terminate.
+	s.types[t] = name
+	s.schemas[name] = &Schema{}
+	*s.schemas[name] = *s.object(t)
+	return Ref(name)
+}
+
+func (s *Schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	s.fields(t, schema)
+	return schema
+}
+
+// fields adds the JSON fields of the struct to the schema, the fields of
+// the embedded structs without a JSON name included.
+func (s *Schemas) fields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, schema)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := s.Of(f.Type)
+		if strings.Contains(opts, "string") && fs.Type != "string" {
+			fs = &Schema{Type: "string"}
+		}
+		schema.Properties[name] = fs
+
+		if !strings.Contains(opts, "omitempty") &&
+			f.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
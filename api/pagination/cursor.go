@@ -0,0 +1,182 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// approxCountCap is the number of rows an approximate count stops at.
+const approxCountCap = 10000
+
+// ErrInvalidCursor is returned for a cursor token that can't be decoded
+// or doesn't match the keys of the list.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a keyset page, the key values of the row
+// next to it. A Prev cursor walks towards the beginning of the list.
+type Cursor struct {
+	Keys []uint64 `json:"k,omitempty"`
+	Prev bool     `json:"p,omitempty"`
+}
+
+// Encode returns the opaque token of the cursor.
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a token returned by Encode. An empty token is the
+// cursor of the first page.
+func DecodeCursor(token string) (*Cursor, error) {
+	c := &Cursor{}
+	if token == "" {
+		return c, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Key is the ordering of a list, by unique columns such as (id) or
+// (slot, position). Values returns the column values of a row.
+type Key[T any] struct {
+	Columns []string
+	Asc     bool
+	Values  func(T) []uint64
+}
+
+// Find loads the page of query requested by page, ordered by key. The
+// count is run on query as given and the page on query with the
+// scopes applied, so scopes is the place for preloads. The returned
+// Result has everything but Data filled.
+func Find[T any](
+	query *gorm.DB,
+	page *Query,
+	key Key[T],
+	scopes ...func(*gorm.DB) *gorm.DB,
+) ([]T, *Result, error) {
+	r := &Result{}
+	if err := count(query.Session(&gorm.Session{}), page.Count, r); err != nil {
+		return nil, nil, err
+	}
+
+	asc, q := key.Asc, query.Session(&gorm.Session{}).Scopes(scopes...)
+	if page.Cursor == nil {
+		q = q.Offset(page.Start)
+	} else {
+		if page.Cursor.Prev {
+			asc = !asc
+		}
+
+		if len(page.Cursor.Keys) > 0 {
+			if len(page.Cursor.Keys) != len(key.Columns) {
+				return nil, nil, ErrInvalidCursor
+			}
+
+			op := "<"
+			if asc {
+				op = ">"
+			}
+
+			args := make([]any, len(page.Cursor.Keys))
+			for i, k := range page.Cursor.Keys {
+				args[i] = k
+			}
+
+			q = q.Where(fmt.Sprintf(
+				"(%s) %s (%s)",
+				strings.Join(key.Columns, ", "),
+				op,
+				strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", "),
+			), args...)
+		}
+	}
+
+	for _, col := range key.Columns {
+		if asc {
+			q = q.Order(col + " asc")
+		} else {
+			q = q.Order(col + " desc")
+		}
+	}
+
+	rows := make([]T, 0)
+	if err := q.Limit(page.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	r.more = len(rows) > page.Limit
+	if r.more {
+		rows = rows[:page.Limit]
+	}
+
+	if page.Cursor == nil || len(rows) == 0 {
+		return rows, r, nil
+	}
+
+	first, last := 0, len(rows)-1
+	if page.Cursor.Prev {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+
+		if r.more {
+			r.Prev = &Cursor{Keys: key.Values(rows[first]), Prev: true}
+		}
+		r.Next = &Cursor{Keys: key.Values(rows[last])}
+	} else {
+		if r.more {
+			r.Next = &Cursor{Keys: key.Values(rows[last])}
+		}
+		if len(page.Cursor.Keys) > 0 {
+			r.Prev = &Cursor{Keys: key.Values(rows[first]), Prev: true}
+		}
+	}
+
+	return rows, r, nil
+}
+
+func count(query *gorm.DB, mode CountMode, r *Result) error {
+	total := int64(0)
+	switch mode {
+	case CountExact:
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+
+	case CountApprox:
+		sub := query.Session(&gorm.Session{}).
+			Select("1").
+			Limit(approxCountCap + 1)
+		if err := query.Session(&gorm.Session{NewDB: true}).
+			Table("(?) AS page_rows", sub).
+			Count(&total).
+			Error; err != nil {
+			return err
+		}
+
+		if total > approxCountCap {
+			total = approxCountCap
+			r.Approximate = true
+		}
+
+	default:
+		return nil
+	}
+
+	r.Total = &total
+	return nil
+}
@@ -1,11 +1,11 @@
 package pagination
 
 import (
-	"fmt"
+	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -14,14 +14,41 @@ const (
 	maxPageLimit    = 100
 )
 
+// CountMode selects how the total of a paginated list is computed.
+type CountMode string
+
+const (
+	// CountNone skips counting, the total is left out of the response.
+	CountNone CountMode = "none"
+	// CountApprox counts up to approxCountCap rows and flags the total
+	// as approximate beyond that.
+	CountApprox CountMode = "approx"
+	// CountExact counts every row of the list.
+	CountExact CountMode = "exact"
+)
+
+// ErrInvalidCount is returned by Parse for an unknown count mode.
+var ErrInvalidCount = errors.New("invalid count mode")
+
+// Query is a page request. A request carrying a cursor parameter, even
+// an empty one for the first page, pages by key and ignores Start.
 type Query struct {
-	Start int
-	Limit int
+	Start  int
+	Limit  int
+	Cursor *Cursor
+	Count  CountMode
 }
 
 type Result struct {
-	Data  any   `json:"data"`
-	Total int64 `json:"total"`
+	Data        any    `json:"data"`
+	Total       *int64 `json:"total,omitempty"`
+	Approximate bool   `json:"approximate,omitempty"`
+
+	// Next and Prev are the cursors of the adjacent pages, only set
+	// for keyset queries.
+	Next *Cursor `json:"-"`
+	Prev *Cursor `json:"-"`
+	more bool
 }
 
 // Response is the response for pagination query request
@@ -54,39 +81,83 @@ func Parse(c *gin.Context) (*Query, error) {
 		limit = maxPageLimit
 	}
 
-	return &Query{
+	q := &Query{
 		Start: int(start),
 		Limit: int(limit),
-	}, nil
+		Count: CountExact,
+	}
+
+	if token, ok := c.GetQuery("cursor"); ok {
+		cursor, err := DecodeCursor(token)
+		if err != nil {
+			return nil, err
+		}
+
+		q.Start = 0
+		q.Cursor = cursor
+		q.Count = CountNone
+	}
+
+	if count := c.Query("count"); count != "" {
+		switch mode := CountMode(count); mode {
+		case CountNone, CountApprox, CountExact:
+			q.Count = mode
+		default:
+			return nil, ErrInvalidCount
+		}
+	}
+
+	return q, nil
 }
 
-// GetLinks returns the prev and next links of the request
-func GetLinks(ctx *gin.Context, total int64, q *Query) links {
-	url := fmt.Sprintf("%v", ctx.Request.URL)
-	baseURL := strings.Split(url, "?")[0]
+// GetLinks returns the prev and next links of the request. Keyset
+// queries link to the adjacent cursors, offset queries to the adjacent
+// start positions. Other query parameters are carried over.
+func GetLinks(ctx *gin.Context, r *Result, q *Query) links {
 	l := links{}
-	if int64(q.Start+q.Limit) <= total {
-		l.Next = fmt.Sprintf(
-			"%s?limit=%d&start=%d",
-			baseURL,
-			q.Limit,
-			q.Start+q.Limit,
-		)
+	if q.Cursor != nil {
+		if r.Next != nil {
+			l.Next = pageURL(ctx, q, func(v url.Values) {
+				v.Set("cursor", r.Next.Encode())
+			})
+		}
+
+		if r.Prev != nil {
+			l.Prev = pageURL(ctx, q, func(v url.Values) {
+				v.Set("cursor", r.Prev.Encode())
+			})
+		}
+
+		return l
+	}
+
+	if r.more {
+		l.Next = pageURL(ctx, q, func(v url.Values) {
+			v.Set("start", strconv.Itoa(q.Start+q.Limit))
+		})
 	}
 
 	if q.Start != 0 {
 		prevStart := q.Start - q.Limit
-		if prevStart < 0 || q.Start < q.Limit {
+		if prevStart < 0 {
 			prevStart = 0
 		}
 
-		l.Prev = fmt.Sprintf(
-			"%s?limit=%d&start=%d",
-			baseURL,
-			q.Limit,
-			prevStart,
-		)
+		l.Prev = pageURL(ctx, q, func(v url.Values) {
+			v.Set("start", strconv.Itoa(prevStart))
+		})
 	}
 
 	return l
 }
+
+func pageURL(ctx *gin.Context, q *Query, set func(url.Values)) string {
+	u := *ctx.Request.URL
+	v := u.Query()
+	v.Del("start")
+	v.Del("cursor")
+	v.Set("limit", strconv.Itoa(q.Limit))
+	set(v)
+	u.RawQuery = v.Encode()
+	return u.String()
+}
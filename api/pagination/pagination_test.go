@@ -0,0 +1,139 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newContext(target string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+func TestCursor(t *testing.T) {
+	for _, c := range []*Cursor{
+		{},
+		{Keys: []uint64{42}},
+		{Keys: []uint64{7, 3}, Prev: true},
+	} {
+		got, err := DecodeCursor(c.Encode())
+		if err != nil {
+			t.Fatalf("decode %v: %v", c, err)
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("decode %v got %v", c, got)
+		}
+	}
+
+	if _, err := DecodeCursor("not a cursor!"); err != ErrInvalidCursor {
+		t.Errorf("decode invalid token got error %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		target  string
+		want    *Query
+		wantErr error
+	}{
+		{
+			name:   "offset defaults",
+			target: "/blocks",
+			want:   &Query{Start: 0, Limit: 10, Count: CountExact},
+		},
+		{
+			name:   "offset with limit cap",
+			target: "/blocks?start=20&limit=1000",
+			want:   &Query{Start: 20, Limit: 100, Count: CountExact},
+		},
+		{
+			name:   "first cursor page",
+			target: "/blocks?cursor=&start=20",
+			want:   &Query{Limit: 10, Cursor: &Cursor{}, Count: CountNone},
+		},
+		{
+			name:   "cursor with count",
+			target: "/blocks?cursor=" + (&Cursor{Keys: []uint64{5}}).Encode() + "&count=approx",
+			want: &Query{
+				Limit:  10,
+				Cursor: &Cursor{Keys: []uint64{5}},
+				Count:  CountApprox,
+			},
+		},
+		{
+			name:    "invalid count",
+			target:  "/blocks?count=some",
+			wantErr: ErrInvalidCount,
+		},
+		{
+			name:    "invalid cursor",
+			target:  "/blocks?cursor=%25",
+			wantErr: ErrInvalidCursor,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Parse(newContext(c.target))
+			if err != c.wantErr {
+				t.Fatalf("parse error = %v, want %v", err, c.wantErr)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("parse = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestGetLinks(t *testing.T) {
+	next := &Cursor{Keys: []uint64{5}}
+	prev := &Cursor{Keys: []uint64{14}, Prev: true}
+	testCases := []struct {
+		name   string
+		target string
+		result *Result
+		query  *Query
+		want   links
+	}{
+		{
+			name:   "offset first page",
+			target: "/transactions?public_key=pk",
+			result: &Result{more: true},
+			query:  &Query{Start: 0, Limit: 10},
+			want: links{
+				Next: "/transactions?limit=10&public_key=pk&start=10",
+			},
+		},
+		{
+			name:   "offset last page",
+			target: "/transactions?start=15&limit=10",
+			result: &Result{},
+			query:  &Query{Start: 15, Limit: 10},
+			want: links{
+				Prev: "/transactions?limit=10&start=5",
+			},
+		},
+		{
+			name:   "cursor page",
+			target: "/blocks?cursor=abc&limit=10",
+			result: &Result{Next: next, Prev: prev},
+			query:  &Query{Limit: 10, Cursor: &Cursor{}},
+			want: links{
+				Next: "/blocks?cursor=" + next.Encode() + "&limit=10",
+				Prev: "/blocks?cursor=" + prev.Encode() + "&limit=10",
+			},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			got := GetLinks(newContext(c.target), c.result, c.query)
+			if got != c.want {
+				t.Errorf("links = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
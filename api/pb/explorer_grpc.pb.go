@@ -0,0 +1,988 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: explorer.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ExplorerClient is the client API for Explorer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ExplorerClient interface {
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	StorageContract(ctx context.Context, in *StorageContractRequest, opts ...grpc.CallOption) (*StorageContractResponse, error)
+	StorageContracts(ctx context.Context, in *StorageContractsRequest, opts ...grpc.CallOption) (*StorageContractsResponse, error)
+	Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	Transactions(ctx context.Context, in *TransactionsRequest, opts ...grpc.CallOption) (*TransactionsResponse, error)
+	QueryType(ctx context.Context, in *QueryTypeRequest, opts ...grpc.CallOption) (*QueryTypeResponse, error)
+	Blocks(ctx context.Context, in *BlocksRequest, opts ...grpc.CallOption) (*BlocksResponse, error)
+	Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error)
+	Account(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*AccountResponse, error)
+	AccountLedger(ctx context.Context, in *AccountLedgerRequest, opts ...grpc.CallOption) (*AccountLedgerResponse, error)
+	BalanceAt(ctx context.Context, in *BalanceAtRequest, opts ...grpc.CallOption) (*BalanceAtResponse, error)
+	Validator(ctx context.Context, in *ValidatorRequest, opts ...grpc.CallOption) (*ValidatorResponse, error)
+	Validators(ctx context.Context, in *ValidatorsRequest, opts ...grpc.CallOption) (*ValidatorsResponse, error)
+	Auditors(ctx context.Context, in *AuditorsRequest, opts ...grpc.CallOption) (*AuditorsResponse, error)
+	Reorgs(ctx context.Context, in *ReorgsRequest, opts ...grpc.CallOption) (*ReorgsResponse, error)
+	Stream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (Explorer_StreamClient, error)
+	StreamBlocks(ctx context.Context, in *StreamBlocksRequest, opts ...grpc.CallOption) (Explorer_StreamBlocksClient, error)
+	GraphQL(ctx context.Context, in *GraphQLRequest, opts ...grpc.CallOption) (*GraphQLResponse, error)
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	Webhooks(ctx context.Context, in *WebhooksRequest, opts ...grpc.CallOption) (*WebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	WebhookDeliveries(ctx context.Context, in *WebhookDeliveriesRequest, opts ...grpc.CallOption) (*WebhookDeliveriesResponse, error)
+	RetryDelivery(ctx context.Context, in *RetryDeliveryRequest, opts ...grpc.CallOption) (*WebhookDelivery, error)
+}
+
+type explorerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExplorerClient(cc grpc.ClientConnInterface) ExplorerClient {
+	return &explorerClient{cc}
+}
+
+func (c *explorerClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) StorageContract(ctx context.Context, in *StorageContractRequest, opts ...grpc.CallOption) (*StorageContractResponse, error) {
+	out := new(StorageContractResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/StorageContract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) StorageContracts(ctx context.Context, in *StorageContractsRequest, opts ...grpc.CallOption) (*StorageContractsResponse, error) {
+	out := new(StorageContractsResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/StorageContracts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Transaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Transactions(ctx context.Context, in *TransactionsRequest, opts ...grpc.CallOption) (*TransactionsResponse, error) {
+	out := new(TransactionsResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Transactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) QueryType(ctx context.Context, in *QueryTypeRequest, opts ...grpc.CallOption) (*QueryTypeResponse, error) {
+	out := new(QueryTypeResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/QueryType", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Blocks(ctx context.Context, in *BlocksRequest, opts ...grpc.CallOption) (*BlocksResponse, error) {
+	out := new(BlocksResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Blocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error) {
+	out := new(BlockResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Block", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Account(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*AccountResponse, error) {
+	out := new(AccountResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Account", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) AccountLedger(ctx context.Context, in *AccountLedgerRequest, opts ...grpc.CallOption) (*AccountLedgerResponse, error) {
+	out := new(AccountLedgerResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/AccountLedger", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) BalanceAt(ctx context.Context, in *BalanceAtRequest, opts ...grpc.CallOption) (*BalanceAtResponse, error) {
+	out := new(BalanceAtResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/BalanceAt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Validator(ctx context.Context, in *ValidatorRequest, opts ...grpc.CallOption) (*ValidatorResponse, error) {
+	out := new(ValidatorResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Validator", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Validators(ctx context.Context, in *ValidatorsRequest, opts ...grpc.CallOption) (*ValidatorsResponse, error) {
+	out := new(ValidatorsResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Validators", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Auditors(ctx context.Context, in *AuditorsRequest, opts ...grpc.CallOption) (*AuditorsResponse, error) {
+	out := new(AuditorsResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Auditors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Reorgs(ctx context.Context, in *ReorgsRequest, opts ...grpc.CallOption) (*ReorgsResponse, error) {
+	out := new(ReorgsResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Reorgs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Stream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (Explorer_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Explorer_ServiceDesc.Streams[0], "/photon.explorer.v1.Explorer/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &explorerStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Explorer_StreamClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type explorerStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *explorerStreamClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *explorerClient) StreamBlocks(ctx context.Context, in *StreamBlocksRequest, opts ...grpc.CallOption) (Explorer_StreamBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Explorer_ServiceDesc.Streams[1], "/photon.explorer.v1.Explorer/StreamBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &explorerStreamBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Explorer_StreamBlocksClient interface {
+	Recv() (*BlockStreamEvent, error)
+	grpc.ClientStream
+}
+
+type explorerStreamBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *explorerStreamBlocksClient) Recv() (*BlockStreamEvent, error) {
+	m := new(BlockStreamEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *explorerClient) GraphQL(ctx context.Context, in *GraphQLRequest, opts ...grpc.CallOption) (*GraphQLResponse, error) {
+	out := new(GraphQLResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/GraphQL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/CreateWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) Webhooks(ctx context.Context, in *WebhooksRequest, opts ...grpc.CallOption) (*WebhooksResponse, error) {
+	out := new(WebhooksResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/Webhooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/DeleteWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) WebhookDeliveries(ctx context.Context, in *WebhookDeliveriesRequest, opts ...grpc.CallOption) (*WebhookDeliveriesResponse, error) {
+	out := new(WebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/WebhookDeliveries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *explorerClient) RetryDelivery(ctx context.Context, in *RetryDeliveryRequest, opts ...grpc.CallOption) (*WebhookDelivery, error) {
+	out := new(WebhookDelivery)
+	err := c.cc.Invoke(ctx, "/photon.explorer.v1.Explorer/RetryDelivery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExplorerServer is the server API for Explorer service.
+// All implementations must embed UnimplementedExplorerServer
+// for forward compatibility
+type ExplorerServer interface {
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	StorageContract(context.Context, *StorageContractRequest) (*StorageContractResponse, error)
+	StorageContracts(context.Context, *StorageContractsRequest) (*StorageContractsResponse, error)
+	Transaction(context.Context, *TransactionRequest) (*TransactionResponse, error)
+	Transactions(context.Context, *TransactionsRequest) (*TransactionsResponse, error)
+	QueryType(context.Context, *QueryTypeRequest) (*QueryTypeResponse, error)
+	Blocks(context.Context, *BlocksRequest) (*BlocksResponse, error)
+	Block(context.Context, *BlockRequest) (*BlockResponse, error)
+	Account(context.Context, *AccountRequest) (*AccountResponse, error)
+	AccountLedger(context.Context, *AccountLedgerRequest) (*AccountLedgerResponse, error)
+	BalanceAt(context.Context, *BalanceAtRequest) (*BalanceAtResponse, error)
+	Validator(context.Context, *ValidatorRequest) (*ValidatorResponse, error)
+	Validators(context.Context, *ValidatorsRequest) (*ValidatorsResponse, error)
+	Auditors(context.Context, *AuditorsRequest) (*AuditorsResponse, error)
+	Reorgs(context.Context, *ReorgsRequest) (*ReorgsResponse, error)
+	Stream(*StreamRequest, Explorer_StreamServer) error
+	StreamBlocks(*StreamBlocksRequest, Explorer_StreamBlocksServer) error
+	GraphQL(context.Context, *GraphQLRequest) (*GraphQLResponse, error)
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error)
+	Webhooks(context.Context, *WebhooksRequest) (*WebhooksResponse, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*Webhook, error)
+	WebhookDeliveries(context.Context, *WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, error)
+	RetryDelivery(context.Context, *RetryDeliveryRequest) (*WebhookDelivery, error)
+	mustEmbedUnimplementedExplorerServer()
+}
+
+// UnimplementedExplorerServer must be embedded to have forward compatible implementations.
+type UnimplementedExplorerServer struct {
+}
+
+func (UnimplementedExplorerServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedExplorerServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedExplorerServer) StorageContract(context.Context, *StorageContractRequest) (*StorageContractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageContract not implemented")
+}
+func (UnimplementedExplorerServer) StorageContracts(context.Context, *StorageContractsRequest) (*StorageContractsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageContracts not implemented")
+}
+func (UnimplementedExplorerServer) Transaction(context.Context, *TransactionRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transaction not implemented")
+}
+func (UnimplementedExplorerServer) Transactions(context.Context, *TransactionsRequest) (*TransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transactions not implemented")
+}
+func (UnimplementedExplorerServer) QueryType(context.Context, *QueryTypeRequest) (*QueryTypeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryType not implemented")
+}
+func (UnimplementedExplorerServer) Blocks(context.Context, *BlocksRequest) (*BlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Blocks not implemented")
+}
+func (UnimplementedExplorerServer) Block(context.Context, *BlockRequest) (*BlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Block not implemented")
+}
+func (UnimplementedExplorerServer) Account(context.Context, *AccountRequest) (*AccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Account not implemented")
+}
+func (UnimplementedExplorerServer) AccountLedger(context.Context, *AccountLedgerRequest) (*AccountLedgerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AccountLedger not implemented")
+}
+func (UnimplementedExplorerServer) BalanceAt(context.Context, *BalanceAtRequest) (*BalanceAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BalanceAt not implemented")
+}
+func (UnimplementedExplorerServer) Validator(context.Context, *ValidatorRequest) (*ValidatorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validator not implemented")
+}
+func (UnimplementedExplorerServer) Validators(context.Context, *ValidatorsRequest) (*ValidatorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validators not implemented")
+}
+func (UnimplementedExplorerServer) Auditors(context.Context, *AuditorsRequest) (*AuditorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Auditors not implemented")
+}
+func (UnimplementedExplorerServer) Reorgs(context.Context, *ReorgsRequest) (*ReorgsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reorgs not implemented")
+}
+func (UnimplementedExplorerServer) Stream(*StreamRequest, Explorer_StreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedExplorerServer) StreamBlocks(*StreamBlocksRequest, Explorer_StreamBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBlocks not implemented")
+}
+func (UnimplementedExplorerServer) GraphQL(context.Context, *GraphQLRequest) (*GraphQLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GraphQL not implemented")
+}
+func (UnimplementedExplorerServer) CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedExplorerServer) Webhooks(context.Context, *WebhooksRequest) (*WebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Webhooks not implemented")
+}
+func (UnimplementedExplorerServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedExplorerServer) WebhookDeliveries(context.Context, *WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebhookDeliveries not implemented")
+}
+func (UnimplementedExplorerServer) RetryDelivery(context.Context, *RetryDeliveryRequest) (*WebhookDelivery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryDelivery not implemented")
+}
+func (UnimplementedExplorerServer) mustEmbedUnimplementedExplorerServer() {}
+
+// UnsafeExplorerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExplorerServer will
+// result in compilation errors.
+type UnsafeExplorerServer interface {
+	mustEmbedUnimplementedExplorerServer()
+}
+
+func RegisterExplorerServer(s grpc.ServiceRegistrar, srv ExplorerServer) {
+	s.RegisterService(&Explorer_ServiceDesc, srv)
+}
+
+func _Explorer_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_StorageContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).StorageContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/StorageContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).StorageContract(ctx, req.(*StorageContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_StorageContracts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageContractsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).StorageContracts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/StorageContracts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).StorageContracts(ctx, req.(*StorageContractsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Transaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Transaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Transaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Transaction(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Transactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Transactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Transactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Transactions(ctx, req.(*TransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_QueryType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).QueryType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/QueryType",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).QueryType(ctx, req.(*QueryTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Blocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Blocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Blocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Blocks(ctx, req.(*BlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Block_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Block(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Block",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Block(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Account_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Account(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Account",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Account(ctx, req.(*AccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_AccountLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountLedgerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).AccountLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/AccountLedger",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).AccountLedger(ctx, req.(*AccountLedgerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_BalanceAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).BalanceAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/BalanceAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).BalanceAt(ctx, req.(*BalanceAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Validator_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Validator(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Validator",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Validator(ctx, req.(*ValidatorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Validators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Validators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Validators",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Validators(ctx, req.(*ValidatorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Auditors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Auditors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Auditors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Auditors(ctx, req.(*AuditorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Reorgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorgsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Reorgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Reorgs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Reorgs(ctx, req.(*ReorgsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExplorerServer).Stream(m, &explorerStreamServer{stream})
+}
+
+type Explorer_StreamServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type explorerStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *explorerStreamServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Explorer_StreamBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExplorerServer).StreamBlocks(m, &explorerStreamBlocksServer{stream})
+}
+
+type Explorer_StreamBlocksServer interface {
+	Send(*BlockStreamEvent) error
+	grpc.ServerStream
+}
+
+type explorerStreamBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *explorerStreamBlocksServer) Send(m *BlockStreamEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Explorer_GraphQL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GraphQLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).GraphQL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/GraphQL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).GraphQL(ctx, req.(*GraphQLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/CreateWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_Webhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).Webhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/Webhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).Webhooks(ctx, req.(*WebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/DeleteWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_WebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).WebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/WebhookDeliveries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).WebhookDeliveries(ctx, req.(*WebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Explorer_RetryDelivery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryDeliveryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExplorerServer).RetryDelivery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/photon.explorer.v1.Explorer/RetryDelivery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExplorerServer).RetryDelivery(ctx, req.(*RetryDeliveryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Explorer_ServiceDesc is the grpc.ServiceDesc for Explorer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Explorer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "photon.explorer.v1.Explorer",
+	HandlerType: (*ExplorerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ping",
+			Handler:    _Explorer_Ping_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Explorer_Stats_Handler,
+		},
+		{
+			MethodName: "StorageContract",
+			Handler:    _Explorer_StorageContract_Handler,
+		},
+		{
+			MethodName: "StorageContracts",
+			Handler:    _Explorer_StorageContracts_Handler,
+		},
+		{
+			MethodName: "Transaction",
+			Handler:    _Explorer_Transaction_Handler,
+		},
+		{
+			MethodName: "Transactions",
+			Handler:    _Explorer_Transactions_Handler,
+		},
+		{
+			MethodName: "QueryType",
+			Handler:    _Explorer_QueryType_Handler,
+		},
+		{
+			MethodName: "Blocks",
+			Handler:    _Explorer_Blocks_Handler,
+		},
+		{
+			MethodName: "Block",
+			Handler:    _Explorer_Block_Handler,
+		},
+		{
+			MethodName: "Account",
+			Handler:    _Explorer_Account_Handler,
+		},
+		{
+			MethodName: "AccountLedger",
+			Handler:    _Explorer_AccountLedger_Handler,
+		},
+		{
+			MethodName: "BalanceAt",
+			Handler:    _Explorer_BalanceAt_Handler,
+		},
+		{
+			MethodName: "Validator",
+			Handler:    _Explorer_Validator_Handler,
+		},
+		{
+			MethodName: "Validators",
+			Handler:    _Explorer_Validators_Handler,
+		},
+		{
+			MethodName: "Auditors",
+			Handler:    _Explorer_Auditors_Handler,
+		},
+		{
+			MethodName: "Reorgs",
+			Handler:    _Explorer_Reorgs_Handler,
+		},
+		{
+			MethodName: "GraphQL",
+			Handler:    _Explorer_GraphQL_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _Explorer_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "Webhooks",
+			Handler:    _Explorer_Webhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _Explorer_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "WebhookDeliveries",
+			Handler:    _Explorer_WebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "RetryDelivery",
+			Handler:    _Explorer_RetryDelivery_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _Explorer_Stream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamBlocks",
+			Handler:       _Explorer_StreamBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "explorer.proto",
+}
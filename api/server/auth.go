@@ -0,0 +1,42 @@
+package server
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/photon-storage/photon-explorer/api/service"
+)
+
+// authorize admits the requests carrying one of the tokens as a bearer
+// token. Without tokens every request is rejected.
+func authorize(tokens []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !validToken(tokens, c.GetHeader("Authorization")) {
+			c.Error(service.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// validToken reports whether the authorization header holds one of the
+// tokens as a bearer token.
+func validToken(tokens []string, header string) bool {
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+	for _, t := range tokens {
+		if t != "" &&
+			subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
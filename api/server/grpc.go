@@ -0,0 +1,510 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+
+	"github.com/photon-storage/photon-explorer/api/graphql"
+	"github.com/photon-storage/photon-explorer/api/pagination"
+	"github.com/photon-storage/photon-explorer/api/pb"
+	"github.com/photon-storage/photon-explorer/api/service"
+	"github.com/photon-storage/photon-explorer/api/stream"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// GRPCServer serves the explorer API over gRPC. The calls go through the
+// same service handlers as the REST routes: a request message is turned
+// into the query parameters (or JSON body) of the matching route and the
+// handler result into the response message, field names being shared
+// by both transports.
+type GRPCServer struct {
+	pb.UnimplementedExplorerServer
+
+	port        int
+	adminTokens []string
+	service     *service.Service
+	server      *grpc.Server
+}
+
+// NewGRPC returns a new instance of the gRPC server. The admin tokens
+// grant access to the webhook management calls.
+func NewGRPC(
+	port int,
+	adminTokens []string,
+	service *service.Service,
+) *GRPCServer {
+	s := &GRPCServer{
+		port:        port,
+		adminTokens: adminTokens,
+		service:     service,
+		server:      grpc.NewServer(),
+	}
+
+	pb.RegisterExplorerServer(s.server, s)
+	return s
+}
+
+// Run the gRPC server
+func (s *GRPCServer) Run() {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		log.Error("listen for the grpc server failed", "error", err)
+		os.Exit(1)
+	}
+
+	if err := s.server.Serve(lis); err != nil {
+		log.Error("run the grpc server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func (s *GRPCServer) Ping(
+	ctx context.Context,
+	req *pb.PingRequest,
+) (*pb.PingResponse, error) {
+	return unary(ctx, s.service.Ping, req, &pb.PingResponse{})
+}
+
+func (s *GRPCServer) Stats(
+	ctx context.Context,
+	req *pb.StatsRequest,
+) (*pb.StatsResponse, error) {
+	return unary(ctx, s.service.Stats, req, &pb.StatsResponse{})
+}
+
+func (s *GRPCServer) StorageContract(
+	ctx context.Context,
+	req *pb.StorageContractRequest,
+) (*pb.StorageContractResponse, error) {
+	return unary(ctx, s.service.StorageContract, req,
+		&pb.StorageContractResponse{})
+}
+
+func (s *GRPCServer) StorageContracts(
+	ctx context.Context,
+	req *pb.StorageContractsRequest,
+) (*pb.StorageContractsResponse, error) {
+	return unary(ctx, s.service.StorageContracts, req,
+		&pb.StorageContractsResponse{})
+}
+
+func (s *GRPCServer) Transaction(
+	ctx context.Context,
+	req *pb.TransactionRequest,
+) (*pb.TransactionResponse, error) {
+	return unary(ctx, s.service.Transaction, req, &pb.TransactionResponse{})
+}
+
+func (s *GRPCServer) Transactions(
+	ctx context.Context,
+	req *pb.TransactionsRequest,
+) (*pb.TransactionsResponse, error) {
+	return unary(ctx, s.service.Transactions, req, &pb.TransactionsResponse{})
+}
+
+func (s *GRPCServer) QueryType(
+	ctx context.Context,
+	req *pb.QueryTypeRequest,
+) (*pb.QueryTypeResponse, error) {
+	return unary(ctx, s.service.QueryType, req, &pb.QueryTypeResponse{})
+}
+
+func (s *GRPCServer) Blocks(
+	ctx context.Context,
+	req *pb.BlocksRequest,
+) (*pb.BlocksResponse, error) {
+	return unary(ctx, s.service.Blocks, req, &pb.BlocksResponse{})
+}
+
+func (s *GRPCServer) Block(
+	ctx context.Context,
+	req *pb.BlockRequest,
+) (*pb.BlockResponse, error) {
+	return unary(ctx, s.service.Block, req, &pb.BlockResponse{})
+}
+
+func (s *GRPCServer) Account(
+	ctx context.Context,
+	req *pb.AccountRequest,
+) (*pb.AccountResponse, error) {
+	return unary(ctx, s.service.Account, req, &pb.AccountResponse{})
+}
+
+func (s *GRPCServer) AccountLedger(
+	ctx context.Context,
+	req *pb.AccountLedgerRequest,
+) (*pb.AccountLedgerResponse, error) {
+	return unary(ctx, s.service.AccountLedger, req,
+		&pb.AccountLedgerResponse{})
+}
+
+func (s *GRPCServer) BalanceAt(
+	ctx context.Context,
+	req *pb.BalanceAtRequest,
+) (*pb.BalanceAtResponse, error) {
+	return unary(ctx, s.service.BalanceAt, req, &pb.BalanceAtResponse{})
+}
+
+func (s *GRPCServer) Validator(
+	ctx context.Context,
+	req *pb.ValidatorRequest,
+) (*pb.ValidatorResponse, error) {
+	return unary(ctx, s.service.Validator, req, &pb.ValidatorResponse{})
+}
+
+func (s *GRPCServer) Validators(
+	ctx context.Context,
+	req *pb.ValidatorsRequest,
+) (*pb.ValidatorsResponse, error) {
+	return unary(ctx, s.service.Validators, req, &pb.ValidatorsResponse{})
+}
+
+func (s *GRPCServer) Auditors(
+	ctx context.Context,
+	req *pb.AuditorsRequest,
+) (*pb.AuditorsResponse, error) {
+	return unary(ctx, s.service.Auditors, req, &pb.AuditorsResponse{})
+}
+
+func (s *GRPCServer) Reorgs(
+	ctx context.Context,
+	req *pb.ReorgsRequest,
+) (*pb.ReorgsResponse, error) {
+	return unary(ctx, s.service.Reorgs, req, &pb.ReorgsResponse{})
+}
+
+func (s *GRPCServer) Stream(
+	req *pb.StreamRequest,
+	srv pb.Explorer_StreamServer,
+) error {
+	return s.follow(srv.Context(), req, func(e *stream.Event) error {
+		return srv.Send(&pb.Event{
+			Id:      e.ID,
+			Kind:    e.Kind,
+			Payload: string(e.Payload),
+		})
+	})
+}
+
+func (s *GRPCServer) StreamBlocks(
+	req *pb.StreamBlocksRequest,
+	srv pb.Explorer_StreamBlocksServer,
+) error {
+	streamReq := &pb.StreamRequest{
+		Topics:      []string{orm.EventBlock},
+		LastEventId: req.LastEventId,
+	}
+
+	return s.follow(srv.Context(), streamReq, func(e *stream.Event) error {
+		resp := &pb.BlockStreamEvent{Id: e.ID}
+		switch e.Kind {
+		case orm.EventBlock:
+			block := &pb.BlockEvent{}
+			if err := unmarshalJSON(e.Payload, block); err != nil {
+				return err
+			}
+			resp.Event = &pb.BlockStreamEvent_Block{Block: block}
+
+		case orm.EventReorg:
+			reorg := &pb.ReorgEvent{}
+			if err := unmarshalJSON(e.Payload, reorg); err != nil {
+				return err
+			}
+			resp.Event = &pb.BlockStreamEvent_Reorg{Reorg: reorg}
+
+		default:
+			return nil
+		}
+
+		return srv.Send(resp)
+	})
+}
+
+func (s *GRPCServer) GraphQL(
+	ctx context.Context,
+	req *pb.GraphQLRequest,
+) (*pb.GraphQLResponse, error) {
+	gqlReq := &graphql.Request{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+	}
+	if req.Variables != "" {
+		if err := json.Unmarshal(
+			[]byte(req.Variables),
+			&gqlReq.Variables,
+		); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	result, err := s.service.ExecuteGraphQL(ctx, gqlReq)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.GraphQLResponse{Result: string(b)}, nil
+}
+
+func (s *GRPCServer) CreateWebhook(
+	ctx context.Context,
+	req *pb.CreateWebhookRequest,
+) (*pb.Webhook, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	return unary(ctx, s.service.CreateWebhook, req, &pb.Webhook{})
+}
+
+func (s *GRPCServer) Webhooks(
+	ctx context.Context,
+	req *pb.WebhooksRequest,
+) (*pb.WebhooksResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	return unary(ctx, s.service.Webhooks, req, &pb.WebhooksResponse{})
+}
+
+func (s *GRPCServer) DeleteWebhook(
+	ctx context.Context,
+	req *pb.DeleteWebhookRequest,
+) (*pb.Webhook, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	return unary(ctx, s.service.DeleteWebhook, req, &pb.Webhook{})
+}
+
+func (s *GRPCServer) WebhookDeliveries(
+	ctx context.Context,
+	req *pb.WebhookDeliveriesRequest,
+) (*pb.WebhookDeliveriesResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	return unary(ctx, s.service.WebhookDeliveries, req,
+		&pb.WebhookDeliveriesResponse{})
+}
+
+func (s *GRPCServer) RetryDelivery(
+	ctx context.Context,
+	req *pb.RetryDeliveryRequest,
+) (*pb.WebhookDelivery, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	return unary(ctx, s.service.RetryDelivery, req, &pb.WebhookDelivery{})
+}
+
+// authorize checks the bearer token of the authorization metadata, see
+// the authorize middleware.
+func (s *GRPCServer) authorize(ctx context.Context) error {
+	header := ""
+	if vs := metadata.ValueFromIncomingContext(ctx, "authorization"); len(vs) > 0 {
+		header = vs[0]
+	}
+
+	if !validToken(s.adminTokens, header) {
+		return toStatus(service.ErrUnauthorized)
+	}
+
+	return nil
+}
+
+// follow runs a stream request until the client goes away.
+func (s *GRPCServer) follow(
+	ctx context.Context,
+	req *pb.StreamRequest,
+	send func(*stream.Event) error,
+) error {
+	c, err := newRequestContext(ctx, req, false)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	es, err := s.service.OpenStream(c)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	// gRPC keeps the connection alive on its own.
+	if err := es.Follow(ctx, send, nil); err != nil {
+		return toStatus(err)
+	}
+
+	return nil
+}
+
+// pageInfo is the JSON of the pb.PageInfo of a paginated response.
+type pageInfo struct {
+	Total       *int64 `json:"total,omitempty"`
+	Approximate bool   `json:"approximate"`
+	NextCursor  string `json:"next_cursor,omitempty"`
+	PrevCursor  string `json:"prev_cursor,omitempty"`
+}
+
+// unary calls the service handler fn with the request message as its
+// parameters and returns its result as the response message.
+func unary[R proto.Message](
+	ctx context.Context,
+	fn handleFunc,
+	req proto.Message,
+	resp R,
+) (R, error) {
+	var zero R
+	ft := reflect.TypeOf(fn)
+	hasBody := ft.NumIn() > 1 && ft.In(1) != paginationType
+	c, err := newRequestContext(ctx, req, hasBody)
+	if err != nil {
+		return zero, toStatus(err)
+	}
+
+	args, err := buildInputParams(ft, c)
+	if err != nil {
+		if getErrCode(err, service.ErrorCode) == -1 {
+			return zero, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return zero, toStatus(err)
+	}
+
+	result := callHandleFunc(fn, args...)
+	if err := result[len(result)-1]; err != nil {
+		return zero, toStatus(err.(error))
+	}
+
+	data := result[0]
+	if r, ok := data.(*pagination.Result); ok {
+		info := &pageInfo{Total: r.Total, Approximate: r.Approximate}
+		if r.Next != nil {
+			info.NextCursor = r.Next.Encode()
+		}
+		if r.Prev != nil {
+			info.PrevCursor = r.Prev.Encode()
+		}
+
+		data = map[string]any{"data": r.Data, "page_info": info}
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return zero, toStatus(err)
+	}
+
+	if err := unmarshalJSON(b, resp); err != nil {
+		return zero, toStatus(err)
+	}
+
+	return resp, nil
+}
+
+// newRequestContext returns the context of the REST request matching
+// the request message. The fields are set as the query parameters, or
+// as the JSON body for the handlers binding one.
+func newRequestContext(
+	ctx context.Context,
+	req proto.Message,
+	hasBody bool,
+) (*gin.Context, error) {
+	method, target, body := http.MethodGet, "/", []byte(nil)
+	if hasBody {
+		b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+		method, body = http.MethodPost, b
+	} else {
+		values := url.Values{}
+		queryValues(req.ProtoReflect(), values)
+		target += "?" + values.Encode()
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	return &gin.Context{Request: r}, nil
+}
+
+// queryValues sets the populated fields of the message as query
+// parameters. Nested messages, such as the page request, are flattened
+// and lists are comma separated.
+func queryValues(m protoreflect.Message, values url.Values) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		switch {
+		case fd.IsList():
+			items := make([]string, v.List().Len())
+			for i := range items {
+				items[i] = fmt.Sprint(v.List().Get(i).Interface())
+			}
+			values.Set(name, strings.Join(items, ","))
+
+		case fd.Message() != nil:
+			queryValues(v.Message(), values)
+
+		default:
+			values.Set(name, fmt.Sprint(v.Interface()))
+		}
+
+		return true
+	})
+}
+
+func unmarshalJSON(b []byte, m proto.Message) error {
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m)
+}
+
+// toStatus maps a service error to a gRPC status. The errors with a
+// code are reported as the REST ones are, the others are logged and
+// hidden.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case err == service.ErrUnauthorized:
+		return status.Error(codes.Unauthenticated, err.Error())
+
+	case err == gorm.ErrRecordNotFound:
+		return status.Error(codes.NotFound, err.Error())
+
+	case getErrCode(err, service.ErrorCode) != -1:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Error("grpc request failed", "error", err)
+	return status.Error(codes.Internal, "request failed")
+}
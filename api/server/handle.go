@@ -56,7 +56,7 @@ func (s *Server) handle(fn handleFunc) gin.HandlerFunc {
 			ctx.AbortWithStatusJSON(http.StatusOK, &pagination.Response{
 				Code:   http.StatusOK,
 				Result: r,
-				Links:  pagination.GetLinks(ctx, r.Total, query),
+				Links:  pagination.GetLinks(ctx, r, query),
 			},
 			)
 
@@ -77,7 +77,7 @@ func buildInputParams(ft reflect.Type, ctx *gin.Context) ([]any, error) {
 	}
 
 	if ft.In(1) != paginationType {
-		reqArg := reflect.New(ft.In(1)).Interface()
+		reqArg := reflect.New(ft.In(1).Elem()).Interface()
 		if err := ctx.ShouldBindJSON(reqArg); err != nil {
 			return nil, err
 		}
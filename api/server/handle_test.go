@@ -1,11 +1,23 @@
 package server
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/photon-storage/photon-explorer/api/openapi"
 	"github.com/photon-storage/photon-explorer/api/pagination"
+	"github.com/photon-storage/photon-explorer/api/pb"
+	"github.com/photon-storage/photon-explorer/api/service"
 )
 
 func TestValidateFunc(t *testing.T) {
@@ -89,3 +101,216 @@ func TestValidateFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildInputParams(t *testing.T) {
+	type body struct {
+		Name string `json:"name" validate:"required"`
+	}
+	fn := func(c *gin.Context, b *body) (*body, error) { return b, nil }
+
+	for _, c := range []struct {
+		json    string
+		wantErr bool
+	}{
+		{`{"name":"a"}`, false},
+		{`{}`, true},
+	} {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(c.json))
+		args, err := buildInputParams(reflect.TypeOf(fn), &gin.Context{Request: r})
+		if (err != nil) != c.wantErr {
+			t.Fatalf("build %s: error = %v, want error %v", c.json, err, c.wantErr)
+		}
+
+		if err == nil && args[1].(*body).Name != "a" {
+			t.Fatalf("build %s: body = %+v", c.json, args[1])
+		}
+	}
+}
+
+// unlistedRoutes serve the documentation, the metrics and the probes,
+// outside of the API itself.
+var unlistedRoutes = map[string]bool{
+	"/photon/v1/openapi.json": true,
+	"/photon/v1/docs":         true,
+	"/metrics":                true,
+	"/healthz":                true,
+	"/readyz":                 true,
+}
+
+func TestGRPCCoversRoutes(t *testing.T) {
+	rpcs := map[string]string{
+		"/photon/v1/ping":                   "Ping",
+		"/photon/v1/stats":                  "Stats",
+		"/photon/v1/storage-contract":       "StorageContract",
+		"/photon/v1/storage-contracts":      "StorageContracts",
+		"/photon/v1/transaction":            "Transaction",
+		"/photon/v1/transactions":           "Transactions",
+		"/photon/v1/query":                  "QueryType",
+		"/photon/v1/blocks":                 "Blocks",
+		"/photon/v1/block":                  "Block",
+		"/photon/v1/account":                "Account",
+		"/photon/v1/account/ledger":         "AccountLedger",
+		"/photon/v1/account/balance-at":     "BalanceAt",
+		"/photon/v1/validator":              "Validator",
+		"/photon/v1/validators":             "Validators",
+		"/photon/v1/auditors":               "Auditors",
+		"/photon/v1/reorgs":                 "Reorgs",
+		"/photon/v1/stream":                 "Stream",
+		"/photon/v1/graphql":                "GraphQL",
+		"/photon/v1/webhooks":               "Webhooks",
+		"/photon/v1/webhook":                "DeleteWebhook",
+		"/photon/v1/webhook/deliveries":     "WebhookDeliveries",
+		"/photon/v1/webhook/delivery/retry": "RetryDelivery",
+	}
+
+	methods := make(map[string]bool)
+	for _, m := range pb.Explorer_ServiceDesc.Methods {
+		methods[m.MethodName] = true
+	}
+	for _, s := range pb.Explorer_ServiceDesc.Streams {
+		methods[s.StreamName] = true
+	}
+
+	gin.SetMode(gin.TestMode)
+	s := New(0, nil, &service.Service{}, nil)
+	for _, r := range s.engine.Routes() {
+		if unlistedRoutes[r.Path] {
+			continue
+		}
+
+		if name, ok := rpcs[r.Path]; !ok || !methods[name] {
+			t.Errorf("route %s %s has no rpc", r.Method, r.Path)
+		}
+	}
+}
+
+func TestQueryValues(t *testing.T) {
+	req := &pb.TransactionsRequest{
+		Page:     &pb.PageRequest{Limit: 20, Cursor: proto.String("")},
+		Role:     []string{"sender", "recipient"},
+		FromSlot: proto.Uint64(0),
+		Sort:     "amount",
+	}
+
+	values := url.Values{}
+	queryValues(req.ProtoReflect(), values)
+
+	want := url.Values{
+		"limit":     {"20"},
+		"cursor":    {""},
+		"role":      {"sender,recipient"},
+		"from_slot": {"0"},
+		"sort":      {"amount"},
+	}
+	if values.Encode() != want.Encode() {
+		t.Errorf("query values = %s, want %s", values.Encode(), want.Encode())
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := New(0, nil, &service.Service{}, nil)
+
+	doc := &openapi.Document{}
+	if err := json.Unmarshal(s.openapi, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range s.engine.Routes() {
+		if unlistedRoutes[r.Path] {
+			continue
+		}
+
+		path := strings.TrimPrefix(r.Path, doc.Servers[0].URL)
+		if doc.Paths[path][strings.ToLower(r.Method)] == nil {
+			t.Errorf("route %s %s is not documented", r.Method, r.Path)
+		}
+	}
+
+	params := make(map[string]bool)
+	for _, p := range doc.Paths["/transactions"]["get"].Parameters {
+		params[p.Name] = true
+	}
+	for _, name := range []string{"public_key", "min_amount", "cursor"} {
+		if !params[name] {
+			t.Errorf("transactions parameter %s is not documented", name)
+		}
+	}
+
+	if doc.Paths["/webhooks"]["post"].RequestBody == nil ||
+		doc.Paths["/webhooks"]["post"].Security == nil {
+		t.Errorf("webhook creation lacks its body or security")
+	}
+
+	data := doc.Paths["/blocks"]["get"].Responses["200"].
+		Content[gin.MIMEJSON].Schema.Properties["data"]
+	if data.Items == nil || doc.Components.Schemas["Block"] == nil {
+		t.Errorf("blocks data = %+v, want a list of Block", data)
+	}
+
+	if len(doc.Components.Schemas["Code"].Enum) != len(service.ErrorCode)+2 {
+		t.Errorf("codes = %v, want success, failure and the error codes",
+			doc.Components.Schemas["Code"].Enum)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(metrics(), handleError())
+	engine.GET("ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, Response{Code: http.StatusOK})
+	})
+	engine.GET("unauthorized", func(c *gin.Context) {
+		c.Error(service.ErrUnauthorized)
+	})
+
+	for _, path := range []string{"/ok", "/unauthorized", "/missing"} {
+		engine.ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodGet, path, nil),
+		)
+	}
+
+	testCases := []struct {
+		route string
+		code  string
+	}{
+		{route: "/ok", code: "200"},
+		{
+			route: "/unauthorized",
+			code:  strconv.Itoa(service.ErrorCode[service.ErrUnauthorized]),
+		},
+		{route: "unmatched", code: "404"},
+	}
+	for _, tc := range testCases {
+		count := testutil.ToFloat64(
+			requestCount.WithLabelValues(tc.route, http.MethodGet, tc.code),
+		)
+		if count != 1 {
+			t.Errorf("requests of %s with code %s = %v, want 1",
+				tc.route, tc.code, count)
+		}
+	}
+}
+
+func TestValidToken(t *testing.T) {
+	tokens := []string{"", "secret"}
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{"Bearer secret", true},
+		{"secret", false},
+		{"Bearer other", false},
+		{"Basic secret", false},
+		{"Bearer ", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		if got := validToken(tokens, c.header); got != c.want {
+			t.Errorf("validToken(%q) = %v, want %v", c.header, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/photon-storage/photon-explorer/api/service"
+)
+
+var (
+	requestCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "photon_explorer",
+			Subsystem: "api",
+			Name:      "requests_total",
+			Help: "API requests by route and response code, 200 or one " +
+				"of the error codes.",
+		},
+		[]string{"route", "method", "code"},
+	)
+	requestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "photon_explorer",
+			Subsystem: "api",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the API requests by route.",
+		},
+		[]string{"route", "method"},
+	)
+)
+
+// metrics reports the requests by route. The code is the one of the
+// response envelope, from service.ErrorCode when the handler failed.
+func metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		code := c.Writer.Status()
+		if err := c.Errors.Last(); err != nil {
+			code = getErrCode(err.Err, service.ErrorCode)
+		}
+
+		requestCount.WithLabelValues(
+			route,
+			c.Request.Method,
+			strconv.Itoa(code),
+		).Inc()
+		requestDuration.WithLabelValues(route, c.Request.Method).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/photon-storage/go-common/log"
+
+	"github.com/photon-storage/photon-explorer/api/openapi"
+	"github.com/photon-storage/photon-explorer/api/service"
+)
+
+const bearerScheme = "bearer"
+
+// pageParams are the parameters pagination.Parse reads.
+var pageParams = []service.Param{
+	{
+		Name:        "start",
+		Type:        service.ParamInteger,
+		Description: "Offset of the page, ignored with a cursor.",
+	},
+	{
+		Name:        "limit",
+		Type:        service.ParamInteger,
+		Description: "Size of the page, 10 by default and 100 at most.",
+	},
+	{
+		Name: "cursor",
+		Type: service.ParamString,
+		Description: "Cursor of the page from the _links of the previous " +
+			"one, empty for the first page paged by key.",
+	},
+	{
+		Name: "count",
+		Type: service.ParamString,
+		Description: "How the total is counted: exact (default), approx, " +
+			"or none (default with a cursor).",
+	},
+}
+
+// operation is a registered route.
+type operation struct {
+	method string
+	path   string
+	fn     any
+	admin  bool
+}
+
+// routes registers the handlers of a group and records them for the
+// OpenAPI document. The handlers are either handle functions or gin
+// handlers writing their own response.
+type routes struct {
+	server *Server
+	group  *gin.RouterGroup
+	admin  bool
+}
+
+func (r *routes) GET(path string, fn any) {
+	r.handle(http.MethodGet, path, fn)
+}
+
+func (r *routes) POST(path string, fn any) {
+	r.handle(http.MethodPost, path, fn)
+}
+
+func (r *routes) DELETE(path string, fn any) {
+	r.handle(http.MethodDelete, path, fn)
+}
+
+func (r *routes) handle(method, path string, fn any) {
+	if _, ok := service.Docs[handlerName(fn)]; !ok {
+		log.Fatal("missing the documentation of a service handle func",
+			"handler", handlerName(fn),
+		)
+	}
+
+	h, ok := fn.(func(*gin.Context))
+	if !ok {
+		h = r.server.handle(fn)
+	}
+
+	r.group.Handle(method, path, h)
+	r.server.operations = append(r.server.operations, &operation{
+		method: method,
+		path:   "/" + path,
+		fn:     fn,
+		admin:  r.admin,
+	})
+}
+
+// handlerName returns the method name of a service handler.
+func handlerName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// openAPI returns the OpenAPI document of the recorded routes. The
+// responses are described from the handler signatures and the service
+// documentation of the handlers.
+func (s *Server) openAPI(basePath string) *openapi.Document {
+	schemas := openapi.NewSchemas()
+	code := schemas.Add("Code", codeSchema())
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title: "Photon Explorer API",
+			Description: "The responses are wrapped in an envelope whose " +
+				"code is 200 on success and an error code otherwise.",
+			Version: "v1",
+		},
+		Servers: []openapi.Server{{URL: basePath}},
+		Paths:   make(map[string]openapi.PathItem),
+		Components: openapi.Components{
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+
+	ids := make(map[string]bool)
+	for _, op := range s.operations {
+		name := handlerName(op.fn)
+		d := service.Docs[name]
+
+		id := name
+		if ids[id] {
+			id += op.method[:1] + strings.ToLower(op.method[1:])
+		}
+		ids[id] = true
+
+		o := &openapi.Operation{
+			OperationID: id,
+			Summary:     d.Summary,
+		}
+		if op.admin {
+			o.Security = []map[string][]string{{bearerScheme: {}}}
+		}
+
+		if _, raw := op.fn.(func(*gin.Context)); raw {
+			rawOperation(o, op.method, d, schemas)
+		} else {
+			handleOperation(o, reflect.TypeOf(op.fn), d, schemas, code)
+		}
+
+		item := doc.Paths[op.path]
+		if item == nil {
+			item = make(openapi.PathItem)
+			doc.Paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = o
+	}
+
+	doc.Components.Schemas = schemas.Components()
+	return doc
+}
+
+// handleOperation describes an operation of a handle function.
+func handleOperation(
+	o *openapi.Operation,
+	ft reflect.Type,
+	d *service.Doc,
+	schemas *openapi.Schemas,
+	code *openapi.Schema,
+) {
+	o.Parameters = parameters(d.Params)
+	if ft.NumIn() > 1 && ft.In(1) != paginationType {
+		o.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.Content(gin.MIMEJSON, schemas.Of(ft.In(1))),
+		}
+	}
+
+	resp := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"code": code,
+			"msg":  {Type: "string"},
+		},
+		Required: []string{"code"},
+	}
+
+	if ft.In(ft.NumIn()-1) == paginationType {
+		o.Parameters = append(o.Parameters, parameters(pageParams)...)
+
+		link := &openapi.Schema{Type: "string", Description: "URL of the page."}
+		resp.Properties["data"] = &openapi.Schema{
+			Type:  "array",
+			Items: schemas.Of(reflect.TypeOf(d.Items)),
+		}
+		resp.Properties["total"] = &openapi.Schema{
+			Type:        "integer",
+			Format:      "int64",
+			Description: "Left out when not counted.",
+		}
+		resp.Properties["approximate"] = &openapi.Schema{Type: "boolean"}
+		resp.Properties["_links"] = &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"next": link,
+				"prev": link,
+			},
+		}
+	} else {
+		resp.Properties["data"] = schemas.Of(ft.Out(0))
+	}
+
+	o.Responses = map[string]*openapi.Response{
+		"200": {
+			Description: "The data, or the error code and message.",
+			Content:     openapi.Content(gin.MIMEJSON, resp),
+		},
+	}
+}
+
+// rawOperation describes an operation of a handler writing its own
+// response.
+func rawOperation(
+	o *openapi.Operation,
+	method string,
+	d *service.Doc,
+	schemas *openapi.Schemas,
+) {
+	if d.Body != nil && method != http.MethodGet {
+		o.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content: openapi.Content(
+				gin.MIMEJSON,
+				schemas.Of(reflect.TypeOf(d.Body)),
+			),
+		}
+	} else {
+		o.Parameters = parameters(d.Params)
+	}
+
+	schema := &openapi.Schema{Type: "string"}
+	if d.Response != nil {
+		schema = schemas.Of(reflect.TypeOf(d.Response))
+	}
+
+	o.Responses = map[string]*openapi.Response{
+		"200": {
+			Description: d.Summary,
+			Content:     openapi.Content(d.ContentType, schema),
+		},
+	}
+}
+
+func parameters(params []service.Param) []*openapi.Parameter {
+	var ps []*openapi.Parameter
+	for _, p := range params {
+		param := &openapi.Parameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+		}
+
+		switch p.Type {
+		case service.ParamInteger:
+			param.Schema = &openapi.Schema{Type: "integer", Format: "int64"}
+
+		case service.ParamList:
+			explode := false
+			param.Style = "form"
+			param.Explode = &explode
+			param.Schema = &openapi.Schema{
+				Type:  "array",
+				Items: &openapi.Schema{Type: "string"},
+			}
+
+		default:
+			param.Schema = &openapi.Schema{Type: "string"}
+		}
+
+		ps = append(ps, param)
+	}
+
+	return ps
+}
+
+// codeSchema lists the response codes, the error ones from
+// service.ErrorCode.
+func codeSchema() *openapi.Schema {
+	type errorCode struct {
+		code int
+		msg  string
+	}
+	codes := []errorCode{{-1, "request failed"}}
+	for err, code := range service.ErrorCode {
+		codes = append(codes, errorCode{code, err.Error()})
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].code < codes[j].code
+	})
+
+	schema := &openapi.Schema{
+		Type:        "integer",
+		Description: "200 on success, otherwise one of the error codes:",
+		Enum:        []any{http.StatusOK},
+	}
+	for _, c := range codes {
+		schema.Enum = append(schema.Enum, c.code)
+		schema.Description += fmt.Sprintf("\n- %d: %s", c.code, c.msg)
+	}
+
+	return schema
+}
@@ -1,29 +1,46 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/photon-storage/go-common/log"
 
+	"github.com/photon-storage/photon-explorer/api/openapi"
 	"github.com/photon-storage/photon-explorer/api/service"
+	"github.com/photon-storage/photon-explorer/health"
 )
 
 // Server defines an instance of a server that handles the requests of
 // the third-party application.
 type Server struct {
-	port   int
-	engine *gin.Engine
+	port        int
+	adminTokens []string
+	engine      *gin.Engine
+	checker     *health.Checker
+	operations  []*operation
+	openapi     []byte
 }
 
-// New returns a new instance of the server.
-func New(port int, service *service.Service) *Server {
+// New returns a new instance of the server. The admin tokens grant
+// access to the webhook management requests, and the checker answers
+// the readiness probe.
+func New(
+	port int,
+	adminTokens []string,
+	service *service.Service,
+	checker *health.Checker,
+) *Server {
 	server := &Server{
-		port:   port,
-		engine: gin.Default(),
+		port:        port,
+		adminTokens: adminTokens,
+		engine:      gin.Default(),
+		checker:     checker,
 	}
 
 	server.registerRouter(service)
@@ -34,7 +51,7 @@ func cors() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization, Token")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "POST, GET, DELETE, OPTIONS")
 		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
@@ -47,21 +64,56 @@ func cors() gin.HandlerFunc {
 }
 
 func (s *Server) registerRouter(service *service.Service) {
-	s.engine.Use(handleError(), cors())
+	s.engine.Use(metrics(), handleError(), cors())
+	s.engine.GET("metrics", gin.WrapH(promhttp.Handler()))
+	s.engine.GET("healthz", gin.WrapF(health.Handler(health.Alive)))
+	s.engine.GET("readyz", gin.WrapF(health.Handler(s.checker.Ready)))
 	g := s.engine.Group("photon/v1")
 
-	g.GET("ping", s.handle(service.Ping))
-	g.GET("stats", s.handle(service.Stats))
-	g.GET("storage-contract", s.handle(service.StorageContract))
-	g.GET("storage-contracts", s.handle(service.StorageContracts))
-	g.GET("transaction", s.handle(service.Transaction))
-	g.GET("transactions", s.handle(service.Transactions))
-	g.GET("query", s.handle(service.QueryType))
-	g.GET("blocks", s.handle(service.Blocks))
-	g.GET("block", s.handle(service.Block))
-	g.GET("account", s.handle(service.Account))
-	g.GET("validators", s.handle(service.Validators))
-	g.GET("auditors", s.handle(service.Auditors))
+	api := &routes{server: s, group: g}
+	api.GET("ping", service.Ping)
+	api.GET("stats", service.Stats)
+	api.GET("storage-contract", service.StorageContract)
+	api.GET("storage-contracts", service.StorageContracts)
+	api.GET("transaction", service.Transaction)
+	api.GET("transactions", service.Transactions)
+	api.GET("query", service.QueryType)
+	api.GET("blocks", service.Blocks)
+	api.GET("block", service.Block)
+	api.GET("account", service.Account)
+	api.GET("account/ledger", service.AccountLedger)
+	api.GET("account/balance-at", service.BalanceAt)
+	api.GET("validator", service.Validator)
+	api.GET("validators", service.Validators)
+	api.GET("auditors", service.Auditors)
+	api.GET("reorgs", service.Reorgs)
+	api.GET("stream", service.Stream)
+	api.GET("graphql", service.GraphQL)
+	api.POST("graphql", service.GraphQL)
+
+	admin := &routes{
+		server: s,
+		group:  g.Group("", authorize(s.adminTokens)),
+		admin:  true,
+	}
+	admin.POST("webhooks", service.CreateWebhook)
+	admin.GET("webhooks", service.Webhooks)
+	admin.DELETE("webhook", service.DeleteWebhook)
+	admin.GET("webhook/deliveries", service.WebhookDeliveries)
+	admin.POST("webhook/delivery/retry", service.RetryDelivery)
+
+	doc, err := json.Marshal(s.openAPI(g.BasePath()))
+	if err != nil {
+		log.Fatal("marshal the openapi document failed", "error", err)
+	}
+	s.openapi = doc
+
+	g.GET("openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, gin.MIMEJSON, s.openapi)
+	})
+	g.GET("docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.UI)
+	})
 }
 
 // Run the server
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
-	"github.com/photon-storage/go-photon/config/config"
 	"github.com/photon-storage/go-photon/crypto/bls"
 	pbc "github.com/photon-storage/photon-proto/consensus"
 
@@ -28,25 +31,13 @@ type status struct {
 
 // Account handles the /account request.
 func (s *Service) Account(c *gin.Context) (*accountResp, error) {
-	pk := c.Query("public_key")
-	if pk == "" {
-		return nil, errMissingPublicKey
-	}
-
-	if _, err := bls.PublicKeyFromHex(pk); err != nil {
-		return nil, err
-	}
-
-	account := &orm.Account{}
-	if err := s.db.Model(&orm.Account{}).
-		Where("public_key = ?", pk).
-		First(account).
-		Error; err != nil {
+	account, err := s.accountByPublicKey(c.Query("public_key"))
+	if err != nil {
 		return nil, err
 	}
 
 	resp := &accountResp{
-		PublicKey: pk,
+		PublicKey: account.PublicKey,
 		Balance:   phoAmount(account.Balance),
 		Nonce:     account.Nonce,
 	}
@@ -82,6 +73,114 @@ func (s *Service) Account(c *gin.Context) (*accountResp, error) {
 	return resp, nil
 }
 
+type ledgerEntry struct {
+	Kind      string `json:"kind"`
+	Slot      uint64 `json:"slot"`
+	TxHash    string `json:"tx_hash"`
+	Delta     string `json:"delta"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// AccountLedger handles the /account/ledger request.
+func (s *Service) AccountLedger(
+	c *gin.Context,
+	page *pagination.Query,
+) (*pagination.Result, error) {
+	account, err := s.accountByPublicKey(c.Query("public_key"))
+	if err != nil {
+		return nil, err
+	}
+
+	ls, r, err := pagination.Find(
+		s.db.Model(&orm.AccountLedger{}).
+			Where("account_id = ?", account.ID),
+		page,
+		pagination.Key[*orm.AccountLedger]{
+			Columns: []string{"id"},
+			Values: func(l *orm.AccountLedger) []uint64 {
+				return []uint64{l.ID}
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*ledgerEntry, len(ls))
+	for i, l := range ls {
+		entries[i] = &ledgerEntry{
+			Kind:      l.Kind,
+			Slot:      l.Slot,
+			TxHash:    l.TxHash,
+			Delta:     phoDelta(l.Delta),
+			Timestamp: l.CreatedAt.Unix(),
+		}
+	}
+
+	r.Data = entries
+	return r, nil
+}
+
+type balanceAtResp struct {
+	PublicKey string `json:"public_key"`
+	Slot      uint64 `json:"slot"`
+	Balance   string `json:"balance"`
+}
+
+// BalanceAt handles the /account/balance-at request. The balance is the
+// sum of the account ledger up to and including the slot.
+func (s *Service) BalanceAt(c *gin.Context) (*balanceAtResp, error) {
+	slot, err := strconv.ParseUint(c.Query("slot"), 10, 64)
+	if err != nil {
+		return nil, errInvalidSlot
+	}
+
+	account, err := s.accountByPublicKey(c.Query("public_key"))
+	if err != nil {
+		return nil, err
+	}
+
+	balance := int64(0)
+	if err := s.db.Model(&orm.AccountLedger{}).
+		Select("coalesce(sum(delta), 0)").
+		Where("account_id = ? and slot <= ?", account.ID, slot).
+		Scan(&balance).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return &balanceAtResp{
+		PublicKey: account.PublicKey,
+		Slot:      slot,
+		Balance:   phoDelta(balance),
+	}, nil
+}
+
+func (s *Service) accountByPublicKey(pk string) (*orm.Account, error) {
+	if pk == "" {
+		return nil, errMissingPublicKey
+	}
+
+	if _, err := bls.PublicKeyFromHex(pk); err != nil {
+		return nil, err
+	}
+
+	account := &orm.Account{}
+	if err := s.db.Model(&orm.Account{}).
+		Where("public_key = ?", pk).
+		First(account).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// phoDelta formats a signed amount like phoAmount.
+func phoDelta(amount int64) string {
+	return fmt.Sprintf("%.2f", float64(amount)/math.Pow10(9))
+}
+
 type baseAccount struct {
 	PublicKey       string `json:"public_key"`
 	DepotAmount     string `json:"depot_amount"`
@@ -100,14 +199,17 @@ func (s *Service) Validators(
 	_ *gin.Context,
 	page *pagination.Query,
 ) (*pagination.Result, error) {
-	vs := make([]*orm.Validator, 0)
-	if err := s.db.Model(&orm.Validator{}).
-		Preload("Account").
-		Preload("AttestBlock").
-		Offset(page.Start).
-		Limit(page.Limit).
-		Find(&vs).
-		Error; err != nil {
+	vs, r, err := pagination.Find(
+		s.db.Model(&orm.Validator{}),
+		page,
+		pagination.Key[*orm.Validator]{
+			Columns: []string{"id"},
+			Asc:     true,
+			Values:  func(v *orm.Validator) []uint64 { return []uint64{v.ID} },
+		},
+		preload("Account", "AttestBlock"),
+	)
+	if err != nil {
 		return nil, err
 	}
 
@@ -130,15 +232,8 @@ func (s *Service) Validators(
 		}
 	}
 
-	count := int64(0)
-	if err := s.db.Model(&orm.Validator{}).Count(&count).Error; err != nil {
-		return nil, err
-	}
-
-	return &pagination.Result{
-		Data:  validators,
-		Total: count,
-	}, nil
+	r.Data = validators
+	return r, nil
 }
 
 type auditor struct {
@@ -151,13 +246,17 @@ func (s *Service) Auditors(
 	_ *gin.Context,
 	page *pagination.Query,
 ) (*pagination.Result, error) {
-	as := make([]*orm.Auditor, 0)
-	if err := s.db.Model(&orm.Auditor{}).
-		Preload("Account").
-		Offset(page.Start).
-		Limit(page.Limit).
-		Find(&as).
-		Error; err != nil {
+	as, r, err := pagination.Find(
+		s.db.Model(&orm.Auditor{}),
+		page,
+		pagination.Key[*orm.Auditor]{
+			Columns: []string{"id"},
+			Asc:     true,
+			Values:  func(a *orm.Auditor) []uint64 { return []uint64{a.ID} },
+		},
+		preload("Account"),
+	)
+	if err != nil {
 		return nil, err
 	}
 
@@ -186,19 +285,13 @@ func (s *Service) Auditors(
 		}
 	}
 
-	count := int64(0)
-	if err := s.db.Model(&orm.Auditor{}).Count(&count).Error; err != nil {
-		return nil, err
-	}
-
-	return &pagination.Result{
-		Data:  auditors,
-		Total: count,
-	}, nil
+	r.Data = auditors
+	return r, nil
 }
 
 func convertExitEpoch(epoch uint64) uint64 {
-	if epoch == uint64(config.Consensus().FarFutureEpoch) {
+	// FarFutureEpoch is stored capped to the signed 64-bit range.
+	if epoch >= math.MaxInt64 {
 		return 0
 	}
 
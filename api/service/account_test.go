@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	fieldparams "github.com/photon-storage/go-photon/config/fieldparams"
+
+	"github.com/photon-storage/photon-explorer/api/pagination"
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+)
+
+func TestAccountLedger(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(transferTx("t1", alicePK, bobPK, 2000000000)))
+	gw.AddBlock(chaintest.WithTxs(transferTx("t2", bobPK, alicePK, 500000000)))
+
+	s := newService(t, gw)
+	gas := phoDelta(-int64(fieldparams.BalanceTransferGas))
+
+	target := "/account/ledger?public_key=" + alicePK
+	c := newContext(target)
+	page, err := pagination.Parse(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := s.AccountLedger(c, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, e := range r.Data.([]*ledgerEntry) {
+		got = append(got, fmt.Sprint(e.Kind, " ", e.Slot, " ", e.TxHash, " ", e.Delta))
+	}
+	want := []string{
+		"transfer_in 2 t2 0.50",
+		"gas_fee 1 t1 " + gas,
+		"transfer_out 1 t1 -2.00",
+		"genesis 0  10.00",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s entries %q, want %q", target, got, want)
+	}
+
+	cases := []struct {
+		target string
+		want   string
+		err    error
+	}{
+		{"/account/balance-at?slot=0&public_key=" + alicePK, "10.00", nil},
+		{"/account/balance-at?slot=1&public_key=" + alicePK, "8.00", nil},
+		{"/account/balance-at?slot=2&public_key=" + alicePK, "8.50", nil},
+		{"/account/balance-at?slot=1&public_key=" + bobPK, "12.00", nil},
+		{"/account/balance-at?slot=9&public_key=" + bobPK, "11.50", nil},
+		{"/account/balance-at?public_key=" + bobPK, "", errInvalidSlot},
+		{"/account/balance-at?slot=1", "", errMissingPublicKey},
+	}
+	for _, c := range cases {
+		t.Run(c.target, func(t *testing.T) {
+			b, err := s.BalanceAt(newContext(c.target))
+			if err != c.err {
+				t.Fatalf("error %v, want %v", err, c.err)
+			}
+			if err == nil && b.Balance != c.want {
+				t.Errorf("balance %s, want %s", b.Balance, c.want)
+			}
+		})
+	}
+}
@@ -25,13 +25,15 @@ func (s *Service) Blocks(
 	_ *gin.Context,
 	page *pagination.Query,
 ) (*pagination.Result, error) {
-	blks := make([]*orm.Block, 0)
-	if err := s.db.Model(&orm.Block{}).
-		Offset(page.Start).
-		Limit(page.Limit).
-		Order("id desc").
-		Find(&blks).
-		Error; err != nil {
+	blks, r, err := pagination.Find(
+		s.db.Model(&orm.Block{}),
+		page,
+		pagination.Key[*orm.Block]{
+			Columns: []string{"slot"},
+			Values:  func(b *orm.Block) []uint64 { return []uint64{b.Slot} },
+		},
+	)
+	if err != nil {
 		return nil, err
 	}
 
@@ -54,27 +56,20 @@ func (s *Service) Blocks(
 		}
 	}
 
-	count := int64(0)
-	if err := s.db.Model(&orm.Block{}).Count(&count).Error; err != nil {
-		return nil, err
-	}
-
-	return &pagination.Result{
-		Data:  blocks,
-		Total: count,
-	}, nil
+	r.Data = blocks
+	return r, nil
 }
 
 type blockResp struct {
-	Slot          uint64 `json:"slot"`
-	Epoch         uint64 `json:"epoch"`
-	TxCount       uint64 `json:"tx_count"`
-	Timestamp     uint64 `json:"timestamp"`
-	BlockHash     string `json:"block_hash"`
-	ParentHash    string `json:"parent_hash"`
-	StateHash     string `json:"state_hash"`
-	ProposerIndex uint64 `json:"proposer_index"`
-	Finalized     bool   `json:"finalized"`
+	Slot          uint64  `json:"slot"`
+	Epoch         uint64  `json:"epoch"`
+	TxCount       uint64  `json:"tx_count"`
+	Timestamp     uint64  `json:"timestamp"`
+	BlockHash     string  `json:"block_hash"`
+	ParentHash    string  `json:"parent_hash"`
+	StateHash     string  `json:"state_hash"`
+	ProposerIndex *uint64 `json:"proposer_index"`
+	Finalized     bool    `json:"finalized"`
 }
 
 // Block handles the /block request.
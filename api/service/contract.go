@@ -29,6 +29,22 @@ type storageContractResp struct {
 	StartEpoch   uint64             `json:"start_epoch"`
 	EndEpoch     uint64             `json:"end_epoch"`
 	Transactions []*baseTransaction `json:"transactions"`
+	Proofs       []*storageProof    `json:"proofs"`
+	Timeline     []*contractStatus  `json:"timeline"`
+}
+
+type storageProof struct {
+	TxHash    string `json:"tx_hash"`
+	Depot     string `json:"depot"`
+	Slot      uint64 `json:"slot"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+type contractStatus struct {
+	PrevStatus string `json:"prev_status"`
+	Status     string `json:"status"`
+	Slot       uint64 `json:"slot"`
+	TxHash     string `json:"tx_hash"`
 }
 
 // StorageContract handles the /storage-contract request.
@@ -57,7 +73,7 @@ func (s *Service) StorageContract(c *gin.Context) (*storageContractResp, error)
 		Preload("FromAccount").
 		Joins("join transaction_contracts as tc on tc.transaction_id = transactions.id").
 		Where("tc.contract_id = ?", sc.ID).
-		Order("id desc").
+		Order("transactions.id desc").
 		Find(&txs).
 		Error; err != nil {
 		return nil, err
@@ -68,6 +84,52 @@ func (s *Service) StorageContract(c *gin.Context) (*storageContractResp, error)
 		txsResp[i] = newBaseTransaction(tx)
 	}
 
+	proofs := make([]*orm.StorageProof, 0)
+	if err := s.db.Model(&orm.StorageProof{}).
+		Preload("Depot").
+		Preload("Transaction.Block").
+		Where("contract_id = ?", sc.ID).
+		Order("id desc").
+		Find(&proofs).
+		Error; err != nil {
+		return nil, err
+	}
+
+	proofsResp := make([]*storageProof, len(proofs))
+	for i, p := range proofs {
+		proofsResp[i] = &storageProof{
+			TxHash:    p.Transaction.Hash,
+			Depot:     p.Depot.PublicKey,
+			Slot:      p.Slot,
+			Timestamp: p.Transaction.Block.Timestamp,
+		}
+	}
+
+	statuses := make([]*orm.StorageContractStatus, 0)
+	if err := s.db.Model(&orm.StorageContractStatus{}).
+		Preload("Transaction").
+		Where("contract_id = ?", sc.ID).
+		Order("id asc").
+		Find(&statuses).
+		Error; err != nil {
+		return nil, err
+	}
+
+	timeline := make([]*contractStatus, len(statuses))
+	for i, st := range statuses {
+		txHash := ""
+		if st.Transaction != nil {
+			txHash = st.Transaction.Hash
+		}
+
+		timeline[i] = &contractStatus{
+			PrevStatus: pbc.StorageStatus_name[st.PrevStatus],
+			Status:     pbc.StorageStatus_name[st.Status],
+			Slot:       st.Slot,
+			TxHash:     txHash,
+		}
+	}
+
 	auditor := ""
 	if sc.Auditor != nil {
 		auditor = sc.Auditor.PublicKey
@@ -85,6 +147,8 @@ func (s *Service) StorageContract(c *gin.Context) (*storageContractResp, error)
 		StartEpoch:   uint64(slots.ToEpoch(pbc.Slot(sc.StartSlot))),
 		EndEpoch:     uint64(slots.ToEpoch(pbc.Slot(sc.EndSlot))),
 		Transactions: txsResp,
+		Proofs:       proofsResp,
+		Timeline:     timeline,
 	}, nil
 }
 
@@ -103,23 +167,25 @@ func (s *Service) StorageContracts(
 	c *gin.Context,
 	page *pagination.Query,
 ) (*pagination.Result, error) {
-	query := s.db.Model(&orm.StorageContract{}).
-		Preload("Owner").
-		Preload("Depot").
-		Preload("CommitTransaction")
-
+	query := s.db.Model(&orm.StorageContract{})
 	if pk := c.Query("public_key"); pk != "" {
 		query = query.Joins("join accounts on accounts.id = "+
 			"storage_contracts.owner_id").
-			Where("public_key = ?", pk)
+			Where("accounts.public_key = ?", pk)
 	}
 
-	scs := make([]*orm.StorageContract, 0)
-	if err := query.Offset(page.Start).
-		Limit(page.Limit).
-		Order("id desc").
-		Find(&scs).
-		Error; err != nil {
+	scs, r, err := pagination.Find(
+		query,
+		page,
+		pagination.Key[*orm.StorageContract]{
+			Columns: []string{"storage_contracts.id"},
+			Values: func(sc *orm.StorageContract) []uint64 {
+				return []uint64{sc.ID}
+			},
+		},
+		preload("Owner", "Depot", "CommitTransaction"),
+	)
+	if err != nil {
 		return nil, err
 	}
 
@@ -154,13 +220,6 @@ func (s *Service) StorageContracts(
 		}
 	}
 
-	count := int64(0)
-	if err := s.db.Model(&orm.StorageContract{}).Count(&count).Error; err != nil {
-		return nil, err
-	}
-
-	return &pagination.Result{
-		Data:  storageContracts,
-		Total: count,
-	}, nil
+	r.Data = storageContracts
+	return r, nil
 }
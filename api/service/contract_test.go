@@ -0,0 +1,58 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+)
+
+func TestStorageContractProofs(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.SetStorageContract("c1", &gateway.StorageResp{
+		Owner:      alicePK,
+		Depot:      bobPK,
+		ObjectHash: "object/c1",
+		Status:     pbc.StorageStatus_OPEN.String(),
+	})
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(commitTx("c1", alicePK, bobPK)))
+	gw.AddBlock(chaintest.WithTxs(porTx("p1", bobPK, "c1")))
+	gw.AddBlock(chaintest.WithTxs(porTx("p2", bobPK, "c1")))
+
+	s := newService(t, gw)
+	if _, err := s.StorageContract(newContext("/storage-contract")); err != errMissingContractHash {
+		t.Fatalf("error %v without a hash, want %v", err, errMissingContractHash)
+	}
+
+	sc, err := s.StorageContract(newContext("/storage-contract?hash=c1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var txs []string
+	for _, tx := range sc.Transactions {
+		txs = append(txs, tx.Hash)
+	}
+	if want := []string{"p2", "p1", "c1"}; !reflect.DeepEqual(txs, want) {
+		t.Errorf("transactions %v, want %v", txs, want)
+	}
+
+	var proofs []storageProof
+	for _, p := range sc.Proofs {
+		p.Timestamp = 0
+		proofs = append(proofs, *p)
+	}
+	want := []storageProof{
+		{TxHash: "p2", Depot: bobPK, Slot: 3},
+		{TxHash: "p1", Depot: bobPK, Slot: 2},
+	}
+	if !reflect.DeepEqual(proofs, want) {
+		t.Errorf("proofs %+v, want %+v", proofs, want)
+	}
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"github.com/photon-storage/photon-explorer/api/graphql"
+)
+
+// Param types of the API documentation.
+const (
+	ParamString  = "string"
+	ParamInteger = "integer"
+	// ParamList is a comma separated list of strings.
+	ParamList = "list"
+)
+
+// Param is a query parameter a handler reads.
+type Param struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// Doc documents a handler for the OpenAPI document, the parameters
+// being read with c.Query out of reach of the reflection over the
+// handler signature.
+type Doc struct {
+	Summary string
+	Params  []Param
+	// Items is an item of the paginated lists.
+	Items any
+	// ContentType, Body and Response describe the handlers writing their
+	// own response. Body is read instead of the parameters by the
+	// requests other than GET.
+	ContentType string
+	Body        any
+	Response    any
+}
+
+var publicKeyParam = Param{
+	Name:        "public_key",
+	Type:        ParamString,
+	Required:    true,
+	Description: "Public key of the account, hex encoded.",
+}
+
+// Docs documents the handlers by method name.
+var Docs = map[string]*Doc{
+	"Ping": {
+		Summary: "Check the server is up.",
+	},
+	"Stats": {
+		Summary: "Chain and network statistics.",
+	},
+	"StorageContract": {
+		Summary: "A storage contract with its transactions, proofs and " +
+			"status timeline.",
+		Params: []Param{{
+			Name:        "hash",
+			Type:        ParamString,
+			Required:    true,
+			Description: "Hash of the commit transaction of the contract.",
+		}},
+	},
+	"StorageContracts": {
+		Summary: "The storage contracts, newest first.",
+		Params: []Param{{
+			Name:        "public_key",
+			Type:        ParamString,
+			Description: "Owner of the contracts.",
+		}},
+		Items: &storageContract{},
+	},
+	"Transaction": {
+		Summary: "A transaction with its type specific details.",
+		Params: []Param{{
+			Name:        "hash",
+			Type:        ParamString,
+			Required:    true,
+			Description: "Hash of the transaction.",
+		}},
+	},
+	"Transactions": {
+		Summary: "The transactions matching the filters, the ranges " +
+			"being inclusive.",
+		Params: []Param{
+			{
+				Name:        "public_key",
+				Type:        ParamString,
+				Description: "Account taking part in the transactions.",
+			},
+			{
+				Name: "role",
+				Type: ParamList,
+				Description: "Roles of the public_key account: sender, " +
+					"recipient, owner, depot, auditor.",
+			},
+			{
+				Name:        "counterparty",
+				Type:        ParamString,
+				Description: "Recipient of the transactions.",
+			},
+			{
+				Name:        "block_hash",
+				Type:        ParamString,
+				Description: "Hash of the block including the transactions.",
+			},
+			{
+				Name:        "type",
+				Type:        ParamList,
+				Description: "Transaction types.",
+			},
+			{Name: "from_slot", Type: ParamInteger},
+			{Name: "to_slot", Type: ParamInteger},
+			{Name: "from_epoch", Type: ParamInteger},
+			{Name: "to_epoch", Type: ParamInteger},
+			{
+				Name:        "from_time",
+				Type:        ParamInteger,
+				Description: "Block timestamp, in seconds.",
+			},
+			{
+				Name:        "to_time",
+				Type:        ParamInteger,
+				Description: "Block timestamp, in seconds.",
+			},
+			{Name: "min_gas_price", Type: ParamInteger},
+			{Name: "max_gas_price", Type: ParamInteger},
+			{Name: "min_amount", Type: ParamInteger},
+			{Name: "max_amount", Type: ParamInteger},
+			{
+				Name:        "sort",
+				Type:        ParamString,
+				Description: "slot (default), gas_price or amount.",
+			},
+			{
+				Name:        "order",
+				Type:        ParamString,
+				Description: "desc (default) or asc.",
+			},
+		},
+		Items: &baseTransaction{},
+	},
+	"QueryType": {
+		Summary: "What a search value is: block, transaction, contract, " +
+			"account or unknown.",
+		Params: []Param{{
+			Name:        "value",
+			Type:        ParamString,
+			Required:    true,
+			Description: "Slot, hash or public key.",
+		}},
+	},
+	"Blocks": {
+		Summary: "The blocks, newest first.",
+		Items:   &Block{},
+	},
+	"Block": {
+		Summary: "A block by slot or by hash.",
+		Params: []Param{
+			{
+				Name:        "slot",
+				Type:        ParamInteger,
+				Description: "Slot of the block.",
+			},
+			{
+				Name:        "hash",
+				Type:        ParamString,
+				Description: "Hash of the block, used without a slot.",
+			},
+		},
+	},
+	"Account": {
+		Summary: "An account with its validator and auditor status.",
+		Params:  []Param{publicKeyParam},
+	},
+	"AccountLedger": {
+		Summary: "The balance changes of an account, newest first.",
+		Params:  []Param{publicKeyParam},
+		Items:   &ledgerEntry{},
+	},
+	"BalanceAt": {
+		Summary: "The balance of an account at a slot.",
+		Params: []Param{
+			publicKeyParam,
+			{
+				Name:     "slot",
+				Type:     ParamInteger,
+				Required: true,
+				Description: "Slot the balance is taken at, " +
+					"inclusive.",
+			},
+		},
+	},
+	"Validator": {
+		Summary: "A validator by public key or by index, with its " +
+			"participation and deposits.",
+		Params: []Param{
+			{
+				Name:        "public_key",
+				Type:        ParamString,
+				Description: "Public key of the validator, hex encoded.",
+			},
+			{
+				Name:        "index",
+				Type:        ParamInteger,
+				Description: "Index of the validator, used without a public key.",
+			},
+		},
+	},
+	"Validators": {
+		Summary: "The validators.",
+		Items:   &validator{},
+	},
+	"Auditors": {
+		Summary: "The auditors.",
+		Items:   &auditor{},
+	},
+	"Reorgs": {
+		Summary: "The chain reorganizations, newest first.",
+		Items:   &reorg{},
+	},
+	"Stream": {
+		Summary: "A server-sent events stream of the chain events.",
+		Params: []Param{
+			{
+				Name: "topics",
+				Type: ParamList,
+				Description: "block, transaction, finality, reorg, " +
+					"contract_status, missed_attestation, all by default.",
+			},
+			{
+				Name:        "account",
+				Type:        ParamList,
+				Description: "Accounts taking part in the transactions.",
+			},
+			{
+				Name:        "type",
+				Type:        ParamList,
+				Description: "Transaction types.",
+			},
+			{
+				Name: "last_event_id",
+				Type: ParamInteger,
+				Description: "Id of the last event received, as the " +
+					"Last-Event-ID header.",
+			},
+		},
+		ContentType: "text/event-stream",
+	},
+	"GraphQL": {
+		Summary: "A GraphQL query. The response is a GraphQL response.",
+		Params: []Param{
+			{
+				Name:     "query",
+				Type:     ParamString,
+				Required: true,
+			},
+			{
+				Name: "operationName",
+				Type: ParamString,
+			},
+			{
+				Name:        "variables",
+				Type:        ParamString,
+				Description: "Variables as a JSON object.",
+			},
+		},
+		ContentType: "application/json",
+		Body:        &graphql.Request{},
+		Response:    map[string]any{},
+	},
+	"CreateWebhook": {
+		Summary: "Register a webhook. The secret signing the deliveries " +
+			"is only returned here.",
+	},
+	"Webhooks": {
+		Summary: "The webhooks.",
+		Items:   &webhookResp{},
+	},
+	"DeleteWebhook": {
+		Summary: "Delete a webhook, its pending deliveries end up dead.",
+		Params: []Param{{
+			Name:        "id",
+			Type:        ParamInteger,
+			Required:    true,
+			Description: "Id of the webhook.",
+		}},
+	},
+	"WebhookDeliveries": {
+		Summary: "The webhook deliveries, the dead ones being the " +
+			"dead-letter log.",
+		Params: []Param{
+			{
+				Name:        "id",
+				Type:        ParamInteger,
+				Description: "Id of the webhook.",
+			},
+			{
+				Name:        "status",
+				Type:        ParamString,
+				Description: "pending, delivered or dead.",
+			},
+		},
+		Items: &webhookDelivery{},
+	},
+	"RetryDelivery": {
+		Summary: "Queue a dead delivery again with its attempts reset.",
+		Params: []Param{{
+			Name:        "id",
+			Type:        ParamInteger,
+			Required:    true,
+			Description: "Id of the delivery.",
+		}},
+	},
+}
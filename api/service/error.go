@@ -1,6 +1,10 @@
 package service
 
-import "github.com/pkg/errors"
+import (
+	"github.com/pkg/errors"
+
+	"github.com/photon-storage/photon-explorer/api/pagination"
+)
 
 var (
 	errSystem              = errors.New("system error")
@@ -9,13 +13,34 @@ var (
 	errMissingPublicKey    = errors.New("missing public key")
 	errMissingContractHash = errors.New("missing contract hash")
 	errApiNotReady         = errors.New("api isn't ready")
+	errInvalidSlot         = errors.New("invalid slot")
+	errInvalidFilter       = errors.New("invalid filter")
+	errInvalidSort         = errors.New("invalid sort")
+	errInvalidTopic        = errors.New("invalid stream topic")
+	errInvalidWebhook      = errors.New("invalid webhook")
+	errWebhookNotFound     = errors.New("webhook not found")
+	errInvalidGraphQL      = errors.New("invalid graphql request")
+
+	// ErrUnauthorized is returned for the admin requests without a
+	// valid token.
+	ErrUnauthorized = errors.New("unauthorized")
 )
 
 var ErrorCode = map[error]int{
-	errSystem:              1000,
-	errMissingTxHash:       1001,
-	errMissingQueryValue:   1002,
-	errMissingPublicKey:    1003,
-	errMissingContractHash: 1004,
-	errApiNotReady:         1005,
+	errSystem:                   1000,
+	errMissingTxHash:            1001,
+	errMissingQueryValue:        1002,
+	errMissingPublicKey:         1003,
+	errMissingContractHash:      1004,
+	errApiNotReady:              1005,
+	errInvalidSlot:              1006,
+	pagination.ErrInvalidCursor: 1007,
+	pagination.ErrInvalidCount:  1008,
+	errInvalidFilter:            1009,
+	errInvalidSort:              1010,
+	errInvalidTopic:             1011,
+	errInvalidWebhook:           1012,
+	errWebhookNotFound:          1013,
+	ErrUnauthorized:             1014,
+	errInvalidGraphQL:           1015,
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+
+	"github.com/photon-storage/photon-explorer/api/graphql"
+)
+
+// GraphQL handles the /graphql request, a GraphQL query either posted
+// as JSON or given in the query, operationName and variables
+// parameters. The response is a GraphQL response rather than the
+// envelope of the other requests.
+func (s *Service) GraphQL(c *gin.Context) {
+	req := &graphql.Request{}
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if v := c.Query("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				c.Error(errInvalidGraphQL)
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(req); err != nil {
+		c.Error(errInvalidGraphQL)
+		return
+	}
+
+	result, err := s.ExecuteGraphQL(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, result)
+}
+
+// ExecuteGraphQL runs a GraphQL request. The errors of the query itself
+// are reported in the result.
+func (s *Service) ExecuteGraphQL(
+	ctx context.Context,
+	req *graphql.Request,
+) (*gql.Result, error) {
+	if req.Query == "" {
+		return nil, errInvalidGraphQL
+	}
+
+	return s.graphql.Execute(ctx, req), nil
+}
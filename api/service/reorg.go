@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/photon-storage/photon-explorer/api/pagination"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+type reorg struct {
+	Depth        uint64 `json:"depth"`
+	OldHeadSlot  uint64 `json:"old_head_slot"`
+	OldHeadHash  string `json:"old_head_hash"`
+	NewHeadSlot  uint64 `json:"new_head_slot"`
+	NewHeadHash  string `json:"new_head_hash"`
+	AncestorSlot uint64 `json:"ancestor_slot"`
+	AncestorHash string `json:"ancestor_hash"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// Reorgs handles the /reorgs request.
+func (s *Service) Reorgs(
+	_ *gin.Context,
+	page *pagination.Query,
+) (*pagination.Result, error) {
+	rs, r, err := pagination.Find(
+		s.db.Model(&orm.Reorg{}),
+		page,
+		pagination.Key[*orm.Reorg]{
+			Columns: []string{"id"},
+			Values:  func(rg *orm.Reorg) []uint64 { return []uint64{rg.ID} },
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	reorgs := make([]*reorg, len(rs))
+	for i, rg := range rs {
+		reorgs[i] = &reorg{
+			Depth:        rg.Depth,
+			OldHeadSlot:  rg.OldHeadSlot,
+			OldHeadHash:  rg.OldHeadHash,
+			NewHeadSlot:  rg.NewHeadSlot,
+			NewHeadHash:  rg.NewHeadHash,
+			AncestorSlot: rg.AncestorSlot,
+			AncestorHash: rg.AncestorHash,
+			Timestamp:    rg.CreatedAt.Unix(),
+		}
+	}
+
+	r.Data = reorgs
+	return r, nil
+}
@@ -4,20 +4,31 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/photon-storage/photon-explorer/api/graphql"
+	"github.com/photon-storage/photon-explorer/api/stream"
 	"github.com/photon-storage/photon-explorer/chain"
 )
 
 // Service defines an instance of service that handles third-party requests.
 type Service struct {
-	db   *gorm.DB
-	node *chain.NodeClient
+	db      *gorm.DB
+	node    chain.NodeClient
+	broker  *stream.Broker
+	graphql *graphql.Executor
 }
 
 // New creates a new service instance.
-func New(db *gorm.DB, nodeEndpoint string) *Service {
+func New(
+	db *gorm.DB,
+	node chain.NodeClient,
+	broker *stream.Broker,
+	graphql *graphql.Executor,
+) *Service {
 	return &Service{
-		db:   db,
-		node: chain.NewNodeClient(nodeEndpoint),
+		db:      db,
+		node:    node,
+		broker:  broker,
+		graphql: graphql,
 	}
 }
 
@@ -28,3 +39,13 @@ type pingResp struct {
 func (s *Service) Ping(_ *gin.Context) (*pingResp, error) {
 	return &pingResp{Pong: "pong"}, nil
 }
+
+// preload returns a scope preloading the given associations.
+func preload(associations ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, a := range associations {
+			db = db.Preload(a)
+		}
+		return db
+	}
+}
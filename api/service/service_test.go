@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	"github.com/photon-storage/go-photon/crypto/sha256"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/database/sqlite"
+	"github.com/photon-storage/photon-explorer/indexer"
+)
+
+// Public keys of the test accounts queried from the node, which checks
+// they are valid BLS keys: the G1 generator and its negation.
+const (
+	alicePK = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	bobPK   = "b7f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+)
+
+// genesisBalance is the balance alice and bob start with, 10 PHO.
+const genesisBalance = 10000000000
+
+// newService indexes the chain of the fake gateway into a new database
+// with the event processor and returns a service reading it.
+func newService(t *testing.T, gw *chaintest.Gateway) *Service {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain status and accounts stand in for the genesis, which is
+	// skipped once the chain status exists.
+	if err := db.Create(&orm.ChainStatus{
+		CurrentHash: sha256.Zero.Hex(),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pk := range []string{alicePK, bobPK} {
+		a := &orm.Account{PublicKey: pk, Balance: genesisBalance}
+		if err := db.Create(a).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if err := db.Create(&orm.AccountLedger{
+			AccountID: a.ID,
+			Kind:      orm.LedgerGenesis,
+			Delta:     genesisBalance,
+		}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	node := gw.Client()
+	cs, err := node.ChainStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ep := indexer.NewEventProcessor(
+		context.Background(),
+		indexer.Config{RefreshInterval: 1},
+		node,
+		db,
+	)
+	go ep.Run()
+	defer ep.Stop()
+
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		status := &orm.ChainStatus{}
+		if err := db.First(status).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if status.NextSlot > cs.Best.Slot {
+			return New(db, node, nil, nil)
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("indexed up to slot %d, want %d", status.NextSlot, cs.Best.Slot)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newContext returns a gin context for a GET request of the target.
+func newContext(target string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+// alloc sets the pointer to a new value and returns it, which fills the
+// typed payloads of a gateway transaction.
+func alloc[T any](p **T) *T {
+	*p = new(T)
+	return *p
+}
+
+func transferTx(hash string, from string, to string, amount uint64) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_BALANCE_TRANSFER.String(),
+		From:     from,
+		GasPrice: 1,
+	}
+	bt := alloc(&tx.BalanceTransfer)
+	bt.To = to
+	bt.Amount = amount
+
+	return tx
+}
+
+func commitTx(hash string, owner string, depot string) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_OBJECT_COMMIT.String(),
+		From:     owner,
+		GasPrice: 1,
+	}
+	oc := alloc(&tx.ObjectCommit)
+	oc.Owner = owner
+	oc.Depot = depot
+	oc.Hash = "object/" + hash
+
+	return tx
+}
+
+func porTx(hash string, depot string, commitTxHash string) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_OBJECT_POR.String(),
+		From:     depot,
+		GasPrice: 1,
+	}
+	alloc(&tx.ObjectPoR).CommitTxHash = commitTxHash
+
+	return tx
+}
+
+func auditTx(hash string, auditor string, depot string, commitTxHash string) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_OBJECT_AUDIT.String(),
+		From:     auditor,
+		GasPrice: 1,
+	}
+	oa := alloc(&tx.ObjectAudit)
+	oa.CommitTxHash = commitTxHash
+	oa.Hash = "object/" + commitTxHash
+	oa.Auditor = auditor
+	oa.Depot = depot
+
+	return tx
+}
+
+func validatorDepositTx(hash string, from string, amount uint64) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_VALIDATOR_DEPOSIT.String(),
+		From:     from,
+		GasPrice: 1,
+	}
+	alloc(&tx.ValidatorDeposit).Amount = amount
+
+	return tx
+}
@@ -49,7 +49,7 @@ func (s *Service) Stats(_ *gin.Context) (*statsResp, error) {
 
 	result := new(struct{ Size uint64 })
 	if err := s.db.Model(&orm.StorageContract{}).
-		Select("sum(size) as size").
+		Select("coalesce(sum(size), 0) as size").
 		Scan(result).
 		Error; err != nil {
 		return nil, err
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/api/stream"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+const (
+	streamKeepAlive  = 15 * time.Second
+	streamReplayStep = 500
+)
+
+var streamTopics = map[string]bool{
+	orm.EventBlock:       true,
+	orm.EventTransaction: true,
+	orm.EventFinality:    true,
+	orm.EventReorg:       true,
+
+	orm.EventContractStatus:    true,
+	orm.EventMissedAttestation: true,
+}
+
+// Stream handles the /stream request, a server-sent events stream of
+// the topics (block, transaction, finality, reorg, contract_status,
+// missed_attestation, comma separated, all by default). Transactions
+// can be narrowed to accounts (account) and types (type). Subscribers
+// of blocks or transactions always receive reorgs. A client resuming
+// with the Last-Event-ID header or the last_event_id parameter first
+// gets the events it missed.
+func (s *Service) Stream(c *gin.Context) {
+	es, err := s.OpenStream(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	es.Follow(
+		c.Request.Context(),
+		func(e *stream.Event) error {
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n",
+				e.ID,
+				e.Kind,
+				e.Payload,
+			)
+			c.Writer.Flush()
+			return nil
+		},
+		func() error {
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+			return nil
+		},
+	)
+}
+
+// EventStream is a checked stream request, ready to be followed by any
+// transport.
+type EventStream struct {
+	s      *Service
+	filter *stream.Filter
+	lastID uint64
+}
+
+// OpenStream checks the parameters of a stream request, see Stream.
+func (s *Service) OpenStream(c *gin.Context) (*EventStream, error) {
+	filter, err := streamFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	lastID := uint64(0)
+	if v := c.GetHeader("Last-Event-ID"); v != "" {
+		lastID, err = strconv.ParseUint(v, 10, 64)
+	} else if v := c.Query("last_event_id"); v != "" {
+		lastID, err = strconv.ParseUint(v, 10, 64)
+	}
+	if err != nil {
+		return nil, errInvalidFilter
+	}
+
+	return &EventStream{s: s, filter: filter, lastID: lastID}, nil
+}
+
+// Follow sends the missed events and then the new ones until the
+// context is done, the broker stops or send fails. keepAlive, if set,
+// is called when the stream has been idle for a while.
+func (es *EventStream) Follow(
+	ctx context.Context,
+	send func(*stream.Event) error,
+	keepAlive func() error,
+) error {
+	broker := es.s.broker
+	sub, from := broker.Subscribe(es.filter)
+	defer broker.Unsubscribe(sub)
+
+	for lastID := es.lastID; lastID > 0 && lastID < from; {
+		events, err := broker.Replay(es.filter, lastID, from, streamReplayStep)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < streamReplayStep {
+			break
+		}
+		lastID = events[len(events)-1].ID
+	}
+
+	var tick <-chan time.Time
+	if keepAlive != nil {
+		ticker := time.NewTicker(streamKeepAlive)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case e, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+
+			if err := send(e); err != nil {
+				return err
+			}
+
+		case <-tick:
+			if err := keepAlive(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func streamFilter(c *gin.Context) (*stream.Filter, error) {
+	f := &stream.Filter{
+		Kinds:    make(map[string]bool),
+		Accounts: make(map[string]bool),
+		TxTypes:  make(map[string]bool),
+	}
+
+	for _, t := range queryList(c, "topics") {
+		if !streamTopics[t] {
+			return nil, errInvalidTopic
+		}
+		f.Kinds[t] = true
+	}
+
+	for _, a := range queryList(c, "account") {
+		f.Accounts[a] = true
+	}
+
+	for _, t := range queryList(c, "type") {
+		if _, ok := pbc.TxType_value[t]; !ok {
+			return nil, errInvalidFilter
+		}
+		f.TxTypes[t] = true
+	}
+
+	return f, nil
+}
+
+// queryList returns the non-empty items of a comma separated parameter.
+func queryList(c *gin.Context, key string) []string {
+	var items []string
+	for _, item := range strings.Split(c.Query(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -4,20 +4,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 
 	"github.com/docker/go-units"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/photon-storage/go-photon/chain/gateway"
+	"github.com/photon-storage/go-photon/config/config"
 	pbc "github.com/photon-storage/photon-proto/consensus"
 
 	"github.com/photon-storage/photon-explorer/api/pagination"
 	"github.com/photon-storage/photon-explorer/database/orm"
 )
 
+var participantRoles = map[string]bool{
+	orm.RoleSender:    true,
+	orm.RoleRecipient: true,
+	orm.RoleOwner:     true,
+	orm.RoleDepot:     true,
+	orm.RoleAuditor:   true,
+}
+
 type baseTransaction struct {
 	Hash      string `json:"hash"`
 	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Amount    string `json:"amount"`
 	Nonce     uint64 `json:"nonce"`
 	Slot      uint64 `json:"slot"`
 	Type      string `json:"type"`
@@ -25,35 +39,100 @@ type baseTransaction struct {
 	GasPrice  uint64 `json:"gas_price"`
 }
 
-// Transactions handles the /transactions request.
+// Transactions handles the /transactions request. The list can be
+// filtered by an account taking part in the transaction (public_key),
+// optionally in the given roles (role, comma separated), counterparty,
+// block_hash, type (comma separated), the inclusive ranges
+// from_/to_slot, from_/to_epoch, from_/to_time, min_/max_gas_price and
+// min_/max_amount, and sorted by slot, gas_price or amount in desc or
+// asc order.
 func (s *Service) Transactions(
 	c *gin.Context,
 	page *pagination.Query,
 ) (*pagination.Result, error) {
-	query := s.db.Model(&orm.Transaction{}).
-		Preload("Block")
+	query := s.db.Model(&orm.Transaction{})
 	if pk := c.Query("public_key"); pk != "" {
-		query.Joins("join accounts on accounts.id = transactions.from_account_id").
+		participants := s.db.Model(&orm.TransactionParticipant{}).
+			Select("transaction_participants.transaction_id").
+			Joins("join accounts on accounts.id = transaction_participants.account_id").
 			Where("accounts.public_key = ?", pk)
+		if roles := c.Query("role"); roles != "" {
+			var values []string
+			for _, r := range strings.Split(roles, ",") {
+				r = strings.TrimSpace(r)
+				if !participantRoles[r] {
+					return nil, errInvalidFilter
+				}
+				values = append(values, r)
+			}
+			participants = participants.
+				Where("transaction_participants.role in ?", values)
+		}
+
+		query = query.Where("transactions.id in (?)", participants)
+	}
+
+	if pk := c.Query("counterparty"); pk != "" {
+		query = query.Joins("join accounts as cp on cp.id = transactions.to_account_id").
+			Where("cp.public_key = ?", pk)
+	}
+
+	if types := c.Query("type"); types != "" {
+		var values []int32
+		for _, t := range strings.Split(types, ",") {
+			v, ok := pbc.TxType_value[strings.TrimSpace(t)]
+			if !ok {
+				return nil, errInvalidFilter
+			}
+			values = append(values, v)
+		}
+		query = query.Where("transactions.type in ?", values)
+	}
+
+	slotFrom, slotTo, err := slotRange(c)
+	if err != nil {
+		return nil, err
+	}
+
+	timeFrom, timeTo, err := queryRange(c, "from_time", "to_time")
+	if err != nil {
+		return nil, err
 	}
 
+	// The block is joined for the slot ordering as well as the filters.
+	query = query.Joins("join blocks on blocks.id = transactions.block_id")
 	if bh := c.Query("block_hash"); bh != "" {
-		query = query.Joins("join blocks on blocks.id = transactions.block_id").
-			Where("blocks.hash = ?", bh)
+		query = query.Where("blocks.hash = ?", bh)
+	}
+	query = whereRange(query, "blocks.slot", slotFrom, slotTo)
+	query = whereRange(query, "blocks.timestamp", timeFrom, timeTo)
+
+	for _, f := range []struct {
+		column   string
+		from, to string
+	}{
+		{"transactions.gas_price", "min_gas_price", "max_gas_price"},
+		{"transactions.amount", "min_amount", "max_amount"},
+	} {
+		from, to, err := queryRange(c, f.from, f.to)
+		if err != nil {
+			return nil, err
+		}
+		query = whereRange(query, f.column, from, to)
 	}
 
-	count := int64(0)
-	if err := query.Count(&count).Error; err != nil {
+	key, err := transactionKey(c)
+	if err != nil {
 		return nil, err
 	}
 
-	txs := make([]*orm.Transaction, 0)
-	if err := query.Preload("FromAccount").
-		Offset(page.Start).
-		Limit(page.Limit).
-		Order("id desc").
-		Find(&txs).
-		Error; err != nil {
+	txs, r, err := pagination.Find(
+		query,
+		page,
+		key,
+		preload("Block", "FromAccount", "ToAccount"),
+	)
+	if err != nil {
 		return nil, err
 	}
 
@@ -62,22 +141,129 @@ func (s *Service) Transactions(
 		baseTransactions[i] = newBaseTransaction(tx)
 	}
 
-	return &pagination.Result{
-		Data:  baseTransactions,
-		Total: count,
-	}, nil
+	r.Data = baseTransactions
+	return r, nil
+}
+
+// transactionKey returns the ordering requested by the sort and order
+// parameters. Sorting by slot orders by the block slot and the position
+// in the block, ids don't follow the chain order once a reorg or a
+// reindex has inserted blocks again.
+func transactionKey(c *gin.Context) (pagination.Key[*orm.Transaction], error) {
+	key := pagination.Key[*orm.Transaction]{}
+	switch c.DefaultQuery("sort", "slot") {
+	case "slot":
+		key.Columns = []string{
+			"blocks.slot",
+			"transactions.position",
+			"transactions.id",
+		}
+		key.Values = func(tx *orm.Transaction) []uint64 {
+			return []uint64{tx.Block.Slot, tx.Position, tx.ID}
+		}
+	case "gas_price":
+		key.Columns = []string{"transactions.gas_price", "transactions.id"}
+		key.Values = func(tx *orm.Transaction) []uint64 {
+			return []uint64{tx.GasPrice, tx.ID}
+		}
+	case "amount":
+		key.Columns = []string{"transactions.amount", "transactions.id"}
+		key.Values = func(tx *orm.Transaction) []uint64 {
+			return []uint64{tx.Amount, tx.ID}
+		}
+	default:
+		return key, errInvalidSort
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+	case "asc":
+		key.Asc = true
+	default:
+		return key, errInvalidSort
+	}
+
+	return key, nil
+}
+
+// slotRange returns the slot range of the from_/to_slot and
+// from_/to_epoch parameters, the narrower bound wins if both are given.
+func slotRange(c *gin.Context) (*uint64, *uint64, error) {
+	from, to, err := queryRange(c, "from_slot", "to_slot")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fromEpoch, toEpoch, err := queryRange(c, "from_epoch", "to_epoch")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slotsPerEpoch := uint64(config.Consensus().SlotsPerEpoch)
+	if fromEpoch != nil {
+		slot := *fromEpoch * slotsPerEpoch
+		if from == nil || *from < slot {
+			from = &slot
+		}
+	}
+
+	if toEpoch != nil {
+		slot := (*toEpoch+1)*slotsPerEpoch - 1
+		if to == nil || *to > slot {
+			to = &slot
+		}
+	}
+
+	return from, to, nil
+}
+
+// queryRange parses the optional unsigned bounds of a range filter.
+func queryRange(c *gin.Context, fromKey, toKey string) (*uint64, *uint64, error) {
+	bounds := make([]*uint64, 2)
+	for i, key := range []string{fromKey, toKey} {
+		v := c.Query(key)
+		if v == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, nil, errInvalidFilter
+		}
+		bounds[i] = &n
+	}
+
+	return bounds[0], bounds[1], nil
+}
+
+func whereRange(query *gorm.DB, column string, from, to *uint64) *gorm.DB {
+	if from != nil {
+		query = query.Where(column+" >= ?", *from)
+	}
+
+	if to != nil {
+		query = query.Where(column+" <= ?", *to)
+	}
+
+	return query
 }
 
 func newBaseTransaction(tx *orm.Transaction) *baseTransaction {
-	return &baseTransaction{
+	btx := &baseTransaction{
 		Hash:      tx.Hash,
 		From:      tx.FromAccount.PublicKey,
+		Amount:    phoAmount(tx.Amount),
 		Nonce:     tx.FromAccount.Nonce,
 		Slot:      tx.Block.Slot,
 		Type:      pbc.TxType_name[tx.Type],
 		Timestamp: tx.Block.Timestamp,
 		GasPrice:  tx.GasPrice,
 	}
+	if tx.ToAccount != nil {
+		btx.To = tx.ToAccount.PublicKey
+	}
+
+	return btx
 }
 
 type transactionResp struct {
@@ -144,6 +330,7 @@ func (s *Service) Transaction(c *gin.Context) (*transactionResp, error) {
 	if err := s.db.Model(&orm.Transaction{}).
 		Preload("Block").
 		Preload("FromAccount").
+		Preload("ToAccount").
 		Where("hash = ?", hash).
 		First(tx).
 		Error; err != nil {
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/api/pagination"
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/indexer"
+)
+
+func TestTransactions(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.SetStorageContract("c1", &gateway.StorageResp{
+		Owner:      alicePK,
+		Depot:      bobPK,
+		ObjectHash: "object/c1",
+		Status:     pbc.StorageStatus_CREATED.String(),
+	})
+	t2 := transferTx("t2", bobPK, alicePK, 300)
+	t2.GasPrice = 3
+	t3 := transferTx("t3", alicePK, bobPK, 200)
+	t3.GasPrice = 2
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(transferTx("t1", alicePK, bobPK, 100), t2))
+	gw.AddBlock(chaintest.WithTxs(t3, commitTx("c1", alicePK, bobPK)))
+
+	s := newService(t, gw)
+	cases := []struct {
+		target string
+		want   []string
+		err    error
+	}{
+		{target: "/transactions", want: []string{"c1", "t3", "t2", "t1"}},
+		{target: "/transactions?order=asc", want: []string{"t1", "t2", "t3", "c1"}},
+		{target: "/transactions?sort=amount", want: []string{"t2", "t3", "t1", "c1"}},
+		{
+			target: "/transactions?sort=gas_price&order=asc",
+			want:   []string{"t1", "c1", "t3", "t2"},
+		},
+		{target: "/transactions?type=OBJECT_COMMIT", want: []string{"c1"}},
+		{
+			target: "/transactions?type=OBJECT_COMMIT,BALANCE_TRANSFER&to_slot=1",
+			want:   []string{"t2", "t1"},
+		},
+		{target: "/transactions?counterparty=" + alicePK, want: []string{"t2"}},
+		{target: "/transactions?from_slot=2", want: []string{"c1", "t3"}},
+		{target: "/transactions?min_gas_price=2", want: []string{"t3", "t2"}},
+		{
+			target: "/transactions?min_amount=150&max_amount=250",
+			want:   []string{"t3"},
+		},
+		{target: "/transactions?sort=nonce", err: errInvalidSort},
+		{target: "/transactions?order=up", err: errInvalidSort},
+		{target: "/transactions?type=NONE", err: errInvalidFilter},
+		{target: "/transactions?min_amount=-1", err: errInvalidFilter},
+	}
+	for _, c := range cases {
+		t.Run(c.target, func(t *testing.T) {
+			if got, err := transactionHashes(s, c.target); err != c.err {
+				t.Fatalf("error %v, want %v", err, c.err)
+			} else if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("transactions %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestAccountActivity(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.SetStorageContract("c1", &gateway.StorageResp{
+		Owner:      alicePK,
+		Depot:      bobPK,
+		Auditor:    alicePK,
+		ObjectHash: "object/c1",
+		Status:     pbc.StorageStatus_OPEN.String(),
+	})
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(
+		transferTx("t1", alicePK, bobPK, 10),
+		transferTx("t2", bobPK, alicePK, 5),
+	))
+	gw.AddBlock(chaintest.WithTxs(commitTx("c1", alicePK, bobPK)))
+	gw.AddBlock(chaintest.WithTxs(auditTx("a1", alicePK, bobPK, "c1")))
+
+	s := newService(t, gw)
+	cases := []struct {
+		target string
+		want   []string
+		err    error
+	}{
+		{
+			target: "/transactions?public_key=" + bobPK,
+			want:   []string{"a1", "c1", "t2", "t1"},
+		},
+		{
+			target: "/transactions?public_key=" + bobPK + "&role=recipient",
+			want:   []string{"t1"},
+		},
+		{
+			target: "/transactions?public_key=" + bobPK + "&role=sender,recipient",
+			want:   []string{"t2", "t1"},
+		},
+		{
+			target: "/transactions?public_key=" + bobPK + "&role=depot",
+			want:   []string{"a1", "c1"},
+		},
+		{
+			target: "/transactions?public_key=" + alicePK + "&role=sender",
+			want:   []string{"a1", "c1", "t1"},
+		},
+		{
+			target: "/transactions?public_key=" + alicePK + "&role=auditor",
+			want:   []string{"a1", "c1"},
+		},
+		{
+			target: "/transactions?public_key=" + bobPK + "&role=payer",
+			err:    errInvalidFilter,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.target, func(t *testing.T) {
+			if got, err := transactionHashes(s, c.target); err != c.err {
+				t.Fatalf("error %v, want %v", err, c.err)
+			} else if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("transactions %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestTransactionsSlotPages(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	// Slot 2 holds four transactions, pages of two split it.
+	slot2 := []*gateway.Tx{
+		transferTx("t2", alicePK, bobPK, 1),
+		transferTx("t3", bobPK, alicePK, 1),
+		transferTx("t4", alicePK, bobPK, 1),
+		transferTx("t5", bobPK, alicePK, 1),
+	}
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(transferTx("t1", alicePK, bobPK, 1)))
+	b := gw.AddBlock(chaintest.WithTxs(slot2...))
+	gw.AddBlock(chaintest.WithTxs(transferTx("t6", alicePK, bobPK, 1)))
+
+	s := newService(t, gw)
+
+	// The node serves the transactions of slot 2 in the reverse order, the
+	// reindex keeps their ids, which then run against the chain order.
+	for _, pk := range []string{alicePK, bobPK} {
+		a, err := s.accountByPublicKey(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gw.SetAccount(pk, &gateway.AccountResp{Nonce: a.Nonce, Balance: a.Balance})
+	}
+	b.Txs = []*gateway.Tx{slot2[3], slot2[2], slot2[1], slot2[0]}
+	if _, err := indexer.Reindex(
+		context.Background(),
+		gw.Client(),
+		s.db,
+		2,
+		2,
+		false,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		order string
+		want  [][]string
+	}{
+		{"desc", [][]string{{"t6", "t2"}, {"t3", "t4"}, {"t5", "t1"}}},
+		{"asc", [][]string{{"t1", "t5"}, {"t4", "t3"}, {"t2", "t6"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.order, func(t *testing.T) {
+			var pages [][]string
+			var prev []*pagination.Cursor
+			cursor := &pagination.Cursor{}
+			for cursor != nil && len(pages) <= len(c.want) {
+				target := "/transactions?sort=slot&limit=2&order=" + c.order +
+					"&cursor=" + cursor.Encode()
+				ctx := newContext(target)
+				page, err := pagination.Parse(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				r, err := s.Transactions(ctx, page)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var hashes []string
+				for _, tx := range r.Data.([]*baseTransaction) {
+					hashes = append(hashes, tx.Hash)
+				}
+				pages = append(pages, hashes)
+				prev = append(prev, r.Prev)
+				cursor = r.Next
+			}
+
+			if !reflect.DeepEqual(pages, c.want) {
+				t.Fatalf("pages %v, want %v", pages, c.want)
+			}
+
+			// The previous page of the last one is the middle page.
+			got, err := transactionHashes(s, "/transactions?sort=slot&limit=2&order="+
+				c.order+"&cursor="+prev[len(prev)-1].Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want[1]) {
+				t.Errorf("previous page %v, want %v", got, c.want[1])
+			}
+		})
+	}
+}
+
+// transactionHashes returns the hashes of the page of the /transactions
+// request of the target.
+func transactionHashes(s *Service, target string) ([]string, error) {
+	c := newContext(target)
+	page, err := pagination.Parse(c)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.Transactions(c, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []string
+	for _, tx := range r.Data.([]*baseTransaction) {
+		hashes = append(hashes, tx.Hash)
+	}
+
+	return hashes, nil
+}
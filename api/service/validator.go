@@ -0,0 +1,217 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/photon-storage/go-photon/config/config"
+	"github.com/photon-storage/go-photon/sak/time/slots"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// participationEpochs is the number of recent epochs returned in the
+// participation of a validator.
+const participationEpochs = 32
+
+type validatorResp struct {
+	baseAccount
+	Index           uint64                `json:"index"`
+	ProposedBlocks  int64                 `json:"proposed_blocks"`
+	MissedProposals int64                 `json:"missed_proposals"`
+	InclusionRate   float64               `json:"inclusion_rate"`
+	Participation   []*epochParticipation `json:"participation"`
+	Deposits        []*deposit            `json:"deposits"`
+}
+
+type epochParticipation struct {
+	Epoch           uint64 `json:"epoch"`
+	AttestationSlot uint64 `json:"attestation_slot"`
+	Attested        bool   `json:"attested"`
+	InclusionSlot   uint64 `json:"inclusion_slot"`
+	Proposed        uint64 `json:"proposed"`
+	MissedProposals uint64 `json:"missed_proposals"`
+}
+
+type deposit struct {
+	TxHash string `json:"tx_hash"`
+	Amount string `json:"amount"`
+	Slot   uint64 `json:"slot"`
+}
+
+// Validator handles the /validator request.
+func (s *Service) Validator(c *gin.Context) (*validatorResp, error) {
+	query := s.db.Model(&orm.Validator{}).Preload("Account")
+	if pk := c.Query("public_key"); pk != "" {
+		query = query.
+			Joins("join accounts on accounts.id = validators.account_id").
+			Where("accounts.public_key = ?", pk)
+	} else if idx := c.Query("index"); idx != "" {
+		index, err := strconv.ParseUint(idx, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		query = query.Where("idx = ?", index)
+	} else {
+		return nil, errMissingPublicKey
+	}
+
+	v := &orm.Validator{}
+	if err := query.First(v).Error; err != nil {
+		return nil, err
+	}
+
+	proposed := int64(0)
+	if err := s.db.Model(&orm.Block{}).
+		Where("proposal_index = ? and hash <> ''", v.Index).
+		Count(&proposed).
+		Error; err != nil {
+		return nil, err
+	}
+
+	// Empty slots whose proposer is unknown have a null proposal_index
+	// and are never counted.
+	missed := int64(0)
+	if err := s.db.Model(&orm.Block{}).
+		Where("proposal_index = ? and hash = ''", v.Index).
+		Count(&missed).
+		Error; err != nil {
+		return nil, err
+	}
+
+	duties, attested := int64(0), int64(0)
+	if err := s.db.Model(&orm.ValidatorParticipation{}).
+		Where("validator_idx = ?", v.Index).
+		Count(&duties).
+		Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&orm.ValidatorParticipation{}).
+		Where("validator_idx = ? and attested = ?", v.Index, true).
+		Count(&attested).
+		Error; err != nil {
+		return nil, err
+	}
+
+	inclusionRate := float64(0)
+	if duties > 0 {
+		inclusionRate = float64(attested) / float64(duties)
+	}
+
+	participation, err := s.validatorParticipation(v.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	deposits, err := s.validatorDeposits(v.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &validatorResp{
+		baseAccount: baseAccount{
+			PublicKey:       v.Account.PublicKey,
+			DepotAmount:     phoAmount(v.Deposit),
+			Status:          pbc.ValidatorStatus_name[v.Status],
+			ActivationEpoch: v.ActivationEpoch,
+			ExitEpoch:       convertExitEpoch(v.ExitEpoch),
+		},
+		Index:           v.Index,
+		ProposedBlocks:  proposed,
+		MissedProposals: missed,
+		InclusionRate:   inclusionRate,
+		Participation:   participation,
+		Deposits:        deposits,
+	}, nil
+}
+
+// validatorParticipation returns the attestation duties and proposals of
+// the validator in the most recent epochs, newest first.
+func (s *Service) validatorParticipation(
+	index uint64,
+) ([]*epochParticipation, error) {
+	ps := make([]*orm.ValidatorParticipation, 0)
+	if err := s.db.Model(&orm.ValidatorParticipation{}).
+		Where("validator_idx = ?", index).
+		Order("epoch desc").
+		Limit(participationEpochs).
+		Find(&ps).
+		Error; err != nil {
+		return nil, err
+	}
+
+	if len(ps) == 0 {
+		return []*epochParticipation{}, nil
+	}
+
+	slotsPerEpoch := uint64(config.Consensus().SlotsPerEpoch)
+	blks := make([]*orm.Block, 0)
+	if err := s.db.Model(&orm.Block{}).
+		Select("slot", "hash").
+		Where("proposal_index = ?", index).
+		Where("slot >= ?", ps[len(ps)-1].Epoch*slotsPerEpoch).
+		Find(&blks).
+		Error; err != nil {
+		return nil, err
+	}
+
+	participation := make([]*epochParticipation, len(ps))
+	byEpoch := make(map[uint64]*epochParticipation)
+	for i, p := range ps {
+		participation[i] = &epochParticipation{
+			Epoch:           p.Epoch,
+			AttestationSlot: p.AttestationSlot,
+			Attested:        p.Attested,
+			InclusionSlot:   p.InclusionSlot,
+		}
+		byEpoch[p.Epoch] = participation[i]
+	}
+
+	for _, b := range blks {
+		ep, ok := byEpoch[uint64(slots.ToEpoch(pbc.Slot(b.Slot)))]
+		if !ok {
+			continue
+		}
+
+		if b.Hash == "" {
+			ep.MissedProposals++
+		} else {
+			ep.Proposed++
+		}
+	}
+
+	return participation, nil
+}
+
+// validatorDeposits returns the validator deposits of the account on
+// the canonical chain, newest first.
+func (s *Service) validatorDeposits(accountID uint64) ([]*deposit, error) {
+	ls := make([]*orm.AccountLedger, 0)
+	if err := s.db.Model(&orm.AccountLedger{}).
+		Joins("join blocks as b on b.id = account_ledgers.block_id").
+		Joins("join transactions as t on t.hash = account_ledgers.tx_hash").
+		Where("account_ledgers.account_id = ?", accountID).
+		Where("account_ledgers.kind = ?", orm.LedgerDeposit).
+		Where("t.type = ?", int32(pbc.TxType_VALIDATOR_DEPOSIT)).
+		Where("b.deleted_at is null").
+		Order("account_ledgers.id desc").
+		Find(&ls).
+		Error; err != nil {
+		return nil, err
+	}
+
+	deposits := make([]*deposit, len(ls))
+	for i, l := range ls {
+		deposits[i] = &deposit{
+			TxHash: l.TxHash,
+			Amount: phoAmount(uint64(-l.Delta)),
+			Slot:   l.Slot,
+		}
+	}
+
+	return deposits, nil
+}
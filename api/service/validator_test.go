@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+)
+
+func TestValidator(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	// Alice and bob deposit as validators 0 and 1. Alice proposes the
+	// blocks, bob misses slot 2 and the proposer of slot 3 is unknown.
+	for i, pk := range []string{alicePK, bobPK} {
+		gw.SetValidator(pk, &gateway.ValidatorResp{
+			PublicKey: pk,
+			Index:     uint64(i),
+			Status:    "VALIDATOR_ACTIVE",
+		})
+	}
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(
+		validatorDepositTx("d1", alicePK, 100),
+		validatorDepositTx("d2", bobPK, 200),
+	))
+	gw.AddEmptySlot(chaintest.WithProposer(1))
+	gw.AddEmptySlot()
+
+	s := newService(t, gw)
+	cases := []struct {
+		target   string
+		proposed int64
+		missed   int64
+		deposit  string
+	}{
+		{"/validator?index=0", 2, 0, "d1"},
+		{"/validator?public_key=" + bobPK, 0, 1, "d2"},
+	}
+	for _, c := range cases {
+		t.Run(c.target, func(t *testing.T) {
+			v, err := s.Validator(newContext(c.target))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v.ProposedBlocks != c.proposed || v.MissedProposals != c.missed {
+				t.Errorf("proposed %d missed %d, want %d %d",
+					v.ProposedBlocks, v.MissedProposals, c.proposed, c.missed)
+			}
+
+			if len(v.Deposits) != 1 || v.Deposits[0].TxHash != c.deposit ||
+				v.Deposits[0].Slot != 1 {
+				t.Errorf("deposits %+v, want %s in slot 1", v.Deposits, c.deposit)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/api/pagination"
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/webhook"
+)
+
+const webhookSecretLength = 32
+
+type createWebhookReq struct {
+	URL        string   `json:"url" validate:"required,url"`
+	Secret     string   `json:"secret"`
+	EventKinds []string `json:"event_kinds" validate:"required,min=1"`
+	Accounts   []string `json:"accounts"`
+	Contracts  []string `json:"contracts"`
+	TxTypes    []string `json:"tx_types"`
+}
+
+type webhookResp struct {
+	ID         uint64   `json:"id"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventKinds []string `json:"event_kinds"`
+	Accounts   []string `json:"accounts"`
+	Contracts  []string `json:"contracts"`
+	TxTypes    []string `json:"tx_types"`
+	Timestamp  int64    `json:"timestamp"`
+}
+
+type webhookDelivery struct {
+	ID            uint64 `json:"id"`
+	WebhookID     uint64 `json:"webhook_id"`
+	EventID       uint64 `json:"event_id"`
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	ResponseCode  int    `json:"response_code"`
+	LastError     string `json:"last_error"`
+	NextAttemptAt int64  `json:"next_attempt_at"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+var deliveryStatuses = map[string]bool{
+	orm.DeliveryPending:   true,
+	orm.DeliveryDelivered: true,
+	orm.DeliveryDead:      true,
+}
+
+// CreateWebhook handles the POST /webhooks request. The secret the
+// deliveries are signed with is generated unless given and is only
+// returned here.
+func (s *Service) CreateWebhook(
+	_ *gin.Context,
+	req *createWebhookReq,
+) (*webhookResp, error) {
+	for _, k := range req.EventKinds {
+		if !webhook.Kinds[k] {
+			return nil, errInvalidWebhook
+		}
+	}
+
+	for _, t := range req.TxTypes {
+		if _, ok := pbc.TxType_value[t]; !ok {
+			return nil, errInvalidWebhook
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		b := make([]byte, webhookSecretLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	w := &orm.Webhook{
+		URL:        req.URL,
+		Secret:     secret,
+		EventKinds: strings.Join(req.EventKinds, ","),
+		Accounts:   strings.Join(req.Accounts, ","),
+		Contracts:  strings.Join(req.Contracts, ","),
+		TxTypes:    strings.Join(req.TxTypes, ","),
+	}
+	if err := s.db.Model(&orm.Webhook{}).Create(w).Error; err != nil {
+		return nil, err
+	}
+
+	resp := toWebhookResp(w)
+	resp.Secret = secret
+	return resp, nil
+}
+
+// Webhooks handles the GET /webhooks request.
+func (s *Service) Webhooks(
+	_ *gin.Context,
+	page *pagination.Query,
+) (*pagination.Result, error) {
+	ws, r, err := pagination.Find(
+		s.db.Model(&orm.Webhook{}),
+		page,
+		pagination.Key[*orm.Webhook]{
+			Columns: []string{"id"},
+			Values:  func(w *orm.Webhook) []uint64 { return []uint64{w.ID} },
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := make([]*webhookResp, len(ws))
+	for i, w := range ws {
+		hooks[i] = toWebhookResp(w)
+	}
+
+	r.Data = hooks
+	return r, nil
+}
+
+// DeleteWebhook handles the DELETE /webhook?id= request. The pending
+// deliveries of a deleted webhook end up dead.
+func (s *Service) DeleteWebhook(c *gin.Context) (*webhookResp, error) {
+	id, err := strconv.ParseUint(c.Query("id"), 10, 64)
+	if err != nil {
+		return nil, errInvalidWebhook
+	}
+
+	w := &orm.Webhook{}
+	if err := s.db.Model(&orm.Webhook{}).
+		Where("id = ?", id).
+		First(w).
+		Error; err == gorm.ErrRecordNotFound {
+		return nil, errWebhookNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Delete(w).Error; err != nil {
+		return nil, err
+	}
+
+	return toWebhookResp(w), nil
+}
+
+// WebhookDeliveries handles the GET /webhook/deliveries request,
+// optionally narrowed to a webhook (id) and a status (pending,
+// delivered, dead). The dead deliveries are the dead-letter log.
+func (s *Service) WebhookDeliveries(
+	c *gin.Context,
+	page *pagination.Query,
+) (*pagination.Result, error) {
+	query := s.db.Model(&orm.WebhookDelivery{})
+	if v := c.Query("id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, errInvalidFilter
+		}
+		query = query.Where("webhook_id = ?", id)
+	}
+
+	if v := c.Query("status"); v != "" {
+		if !deliveryStatuses[v] {
+			return nil, errInvalidFilter
+		}
+		query = query.Where("status = ?", v)
+	}
+
+	ds, r, err := pagination.Find(
+		query,
+		page,
+		pagination.Key[*orm.WebhookDelivery]{
+			Columns: []string{"id"},
+			Values: func(d *orm.WebhookDelivery) []uint64 {
+				return []uint64{d.ID}
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*webhookDelivery, len(ds))
+	for i, d := range ds {
+		deliveries[i] = toWebhookDelivery(d)
+	}
+
+	r.Data = deliveries
+	return r, nil
+}
+
+// RetryDelivery handles the POST /webhook/delivery/retry?id= request,
+// queueing a dead delivery again with its attempts reset.
+func (s *Service) RetryDelivery(c *gin.Context) (*webhookDelivery, error) {
+	id, err := strconv.ParseUint(c.Query("id"), 10, 64)
+	if err != nil {
+		return nil, errInvalidWebhook
+	}
+
+	d := &orm.WebhookDelivery{}
+	if err := s.db.Model(&orm.WebhookDelivery{}).
+		Where("id = ? and status = ?", id, orm.DeliveryDead).
+		First(d).
+		Error; err == gorm.ErrRecordNotFound {
+		return nil, errWebhookNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	d.Status = orm.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now()
+	if err := s.db.Model(&orm.WebhookDelivery{}).
+		Where("id = ?", d.ID).
+		Updates(map[string]interface{}{
+			"status":          d.Status,
+			"attempts":        d.Attempts,
+			"next_attempt_at": d.NextAttemptAt,
+		}).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return toWebhookDelivery(d), nil
+}
+
+func toWebhookResp(w *orm.Webhook) *webhookResp {
+	return &webhookResp{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventKinds: webhook.List(w.EventKinds),
+		Accounts:   webhook.List(w.Accounts),
+		Contracts:  webhook.List(w.Contracts),
+		TxTypes:    webhook.List(w.TxTypes),
+		Timestamp:  w.CreatedAt.Unix(),
+	}
+}
+
+func toWebhookDelivery(d *orm.WebhookDelivery) *webhookDelivery {
+	return &webhookDelivery{
+		ID:            d.ID,
+		WebhookID:     d.WebhookID,
+		EventID:       d.EventID,
+		Kind:          d.Kind,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		LastError:     d.LastError,
+		NextAttemptAt: d.NextAttemptAt.Unix(),
+		Timestamp:     d.CreatedAt.Unix(),
+	}
+}
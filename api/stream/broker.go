@@ -0,0 +1,272 @@
+// Package stream fans the events the indexer writes to the outbox out to
+// subscribed clients.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBufferSize   = 256
+	pollBatchSize       = 500
+)
+
+// Config defines the stream configuration.
+type Config struct {
+	// PollInterval is the interval the outbox is polled at.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// BufferSize is the number of events buffered per subscriber, a
+	// subscriber falling further behind is dropped.
+	BufferSize int `yaml:"buffer_size"`
+}
+
+// Event is an outbox event with the fields subscribers filter on.
+type Event struct {
+	ID      uint64
+	Kind    string
+	Payload json.RawMessage
+
+	txType   string
+	accounts []string
+}
+
+// Filter selects the events of a subscription. Empty sets match
+// everything.
+type Filter struct {
+	Kinds    map[string]bool
+	Accounts map[string]bool
+	TxTypes  map[string]bool
+}
+
+func (f *Filter) match(e *Event) bool {
+	if e.Kind == orm.EventReorg {
+		// Subscribers of blocks or transactions must learn about the
+		// rollback of what they received.
+		return len(f.Kinds) == 0 || f.Kinds[orm.EventReorg] ||
+			f.Kinds[orm.EventBlock] || f.Kinds[orm.EventTransaction]
+	}
+
+	if len(f.Kinds) > 0 && !f.Kinds[e.Kind] {
+		return false
+	}
+
+	if e.Kind != orm.EventTransaction {
+		return true
+	}
+
+	if len(f.TxTypes) > 0 && !f.TxTypes[e.txType] {
+		return false
+	}
+
+	if len(f.Accounts) == 0 {
+		return true
+	}
+
+	for _, a := range e.accounts {
+		if f.Accounts[a] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscription receives the events matching its filter on C. C is
+// closed when the subscriber falls behind or the broker stops.
+type Subscription struct {
+	C      <-chan *Event
+	ch     chan *Event
+	filter *Filter
+}
+
+// Broker polls the outbox and dispatches new events to subscriptions.
+type Broker struct {
+	ctx  context.Context
+	cfg  Config
+	db   *gorm.DB
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+	last uint64
+}
+
+// NewBroker returns a new broker, Run starts it.
+func NewBroker(ctx context.Context, cfg Config, db *gorm.DB) *Broker {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+
+	return &Broker{
+		ctx:  ctx,
+		cfg:  cfg,
+		db:   db,
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Run polls the outbox until the context is done. Events published
+// before Run are only available through Replay.
+func (b *Broker) Run() {
+	last := uint64(0)
+	if err := b.db.Model(&orm.Event{}).
+		Select("coalesce(max(id), 0)").
+		Scan(&last).
+		Error; err != nil {
+		log.Error("Error querying the last event", "error", err)
+	}
+
+	b.mu.Lock()
+	b.last = last
+	b.mu.Unlock()
+
+	ticker := time.NewTicker(b.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.ctx.Done():
+			b.closeAll()
+			return
+
+		case <-ticker.C:
+
+		}
+
+		for {
+			es, err := b.load(last, 0, pollBatchSize)
+			if err != nil {
+				log.Error("Error polling events", "error", err)
+				break
+			}
+
+			if len(es) == 0 {
+				break
+			}
+
+			b.dispatch(es)
+			last = es[len(es)-1].ID
+			if len(es) < pollBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// Subscribe registers a subscription. It returns the id of the last
+// event dispatched before the subscription, later events arrive on C.
+func (b *Broker) Subscribe(filter *Filter) (*Subscription, uint64) {
+	ch := make(chan *Event, b.cfg.BufferSize)
+	sub := &Subscription{C: ch, ch: ch, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[sub] = struct{}{}
+	return sub, b.last
+}
+
+// Unsubscribe removes a subscription.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Replay returns the events in (from, to] matching filter, at most
+// limit of them.
+func (b *Broker) Replay(
+	filter *Filter,
+	from uint64,
+	to uint64,
+	limit int,
+) ([]*Event, error) {
+	es, err := b.load(from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*Event, 0, len(es))
+	for _, e := range es {
+		if filter.match(e) {
+			matched = append(matched, e)
+		}
+	}
+
+	return matched, nil
+}
+
+// load reads the events after id from, up to id to unless to is 0.
+func (b *Broker) load(from, to uint64, limit int) ([]*Event, error) {
+	query := b.db.Model(&orm.Event{}).Where("id > ?", from)
+	if to > 0 {
+		query = query.Where("id <= ?", to)
+	}
+
+	rows := make([]*orm.Event, 0)
+	if err := query.Order("id asc").
+		Limit(limit).
+		Find(&rows).
+		Error; err != nil {
+		return nil, err
+	}
+
+	es := make([]*Event, len(rows))
+	for i, r := range rows {
+		es[i] = &Event{ID: r.ID, Kind: r.Kind, Payload: r.Payload}
+		if r.Kind == orm.EventTransaction {
+			tx := &orm.TransactionEvent{}
+			if err := json.Unmarshal(r.Payload, tx); err != nil {
+				return nil, err
+			}
+
+			es[i].txType = tx.Type
+			es[i].accounts = tx.Accounts
+		}
+	}
+
+	return es, nil
+}
+
+func (b *Broker) dispatch(es []*Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range es {
+		for sub := range b.subs {
+			if !sub.filter.match(e) {
+				continue
+			}
+
+			select {
+			case sub.ch <- e:
+			default:
+				log.Warn("Dropping slow stream subscriber", "event_id", e.ID)
+				delete(b.subs, sub)
+				close(sub.ch)
+			}
+		}
+		b.last = e.ID
+	}
+}
+
+func (b *Broker) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
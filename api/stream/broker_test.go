@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestFilterMatch(t *testing.T) {
+	transfer := &Event{
+		Kind:     orm.EventTransaction,
+		txType:   "BALANCE_TRANSFER",
+		accounts: []string{"alice", "bob"},
+	}
+	block := &Event{Kind: orm.EventBlock}
+	reorg := &Event{Kind: orm.EventReorg}
+	finality := &Event{Kind: orm.EventFinality}
+
+	testCases := []struct {
+		name   string
+		filter *Filter
+		event  *Event
+		want   bool
+	}{
+		{"empty filter", &Filter{}, transfer, true},
+		{
+			"other topic",
+			&Filter{Kinds: map[string]bool{orm.EventBlock: true}},
+			transfer,
+			false,
+		},
+		{
+			"matching account",
+			&Filter{Accounts: map[string]bool{"bob": true}},
+			transfer,
+			true,
+		},
+		{
+			"other account",
+			&Filter{Accounts: map[string]bool{"carol": true}},
+			transfer,
+			false,
+		},
+		{
+			"other type",
+			&Filter{TxTypes: map[string]bool{"OBJECT_COMMIT": true}},
+			transfer,
+			false,
+		},
+		{
+			"account filter ignores blocks",
+			&Filter{Accounts: map[string]bool{"carol": true}},
+			block,
+			true,
+		},
+		{
+			"reorg for transaction subscribers",
+			&Filter{Kinds: map[string]bool{orm.EventTransaction: true}},
+			reorg,
+			true,
+		},
+		{
+			"no reorg for finality subscribers",
+			&Filter{Kinds: map[string]bool{orm.EventFinality: true}},
+			reorg,
+			false,
+		},
+		{
+			"finality",
+			&Filter{Kinds: map[string]bool{orm.EventFinality: true}},
+			finality,
+			true,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.filter.match(c.event); got != c.want {
+				t.Errorf("match = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,475 @@
+// Package chaintest provides an in-process fake of the photon node
+// gateway for tests.
+package chaintest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	photonsha256 "github.com/photon-storage/go-photon/crypto/sha256"
+
+	"github.com/photon-storage/photon-explorer/chain"
+)
+
+// genesisTimestamp is the timestamp of slot 0 of the fake chain.
+const genesisTimestamp = uint64(1660000000)
+
+// BlockOption customizes a block produced by Gateway.AddBlock.
+type BlockOption func(b *gateway.BlockResp)
+
+// WithTxs sets the transactions of the block.
+func WithTxs(txs ...*gateway.Tx) BlockOption {
+	return func(b *gateway.BlockResp) {
+		b.Txs = txs
+	}
+}
+
+// WithAttestations sets the attestations of the block.
+func WithAttestations(as ...*gateway.Attestation) BlockOption {
+	return func(b *gateway.BlockResp) {
+		b.Attestations = as
+	}
+}
+
+// WithProposer sets the proposer index of the block.
+func WithProposer(index uint64) BlockOption {
+	return func(b *gateway.BlockResp) {
+		b.ProposerIndex = index
+	}
+}
+
+// Gateway is a scriptable fake photon node. It serves the same
+// {code,msg,data} envelope as the real gateway over an httptest server,
+// so the production chain.HTTPNodeClient can be pointed at it.
+type Gateway struct {
+	mu            sync.Mutex
+	server        *httptest.Server
+	canonical     []*gateway.BlockResp
+	blocks        map[string]*gateway.BlockResp
+	forks         uint64
+	finalizedSlot uint64
+	accounts      map[string]*gateway.AccountResp
+	validator     map[string]*gateway.ValidatorResp
+	validators    *gateway.ValidatorsResp
+	auditor       map[string]*gateway.ValidatorResp
+	auditors      *gateway.AuditorsResp
+	contracts     map[string]*gateway.StorageResp
+	committees    map[uint64][]*chain.Committee
+	failures      map[string]int
+}
+
+// NewGateway starts a fake gateway with an empty chain. The caller must
+// call Close when done.
+func NewGateway() *Gateway {
+	g := &Gateway{
+		blocks:     make(map[string]*gateway.BlockResp),
+		accounts:   make(map[string]*gateway.AccountResp),
+		validator:  make(map[string]*gateway.ValidatorResp),
+		validators: &gateway.ValidatorsResp{},
+		auditor:    make(map[string]*gateway.ValidatorResp),
+		contracts:  make(map[string]*gateway.StorageResp),
+		committees: make(map[uint64][]*chain.Committee),
+		failures:   make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chain-status", g.handleChainStatus)
+	mux.HandleFunc("/block", g.handleBlock)
+	mux.HandleFunc("/account", g.handleAccount)
+	mux.HandleFunc("/validator", g.handleValidator)
+	mux.HandleFunc("/validators", g.handleValidators)
+	mux.HandleFunc("/auditor", g.handleAuditor)
+	mux.HandleFunc("/auditors", g.handleAuditors)
+	mux.HandleFunc("/storage-contract", g.handleStorageContract)
+	mux.HandleFunc("/committees", g.handleCommittees)
+	g.server = httptest.NewServer(g.intercept(mux))
+
+	return g
+}
+
+// URL returns the endpoint of the fake gateway.
+func (g *Gateway) URL() string {
+	return g.server.URL
+}
+
+// Client returns a node client connected to the fake gateway. Retries are
+// disabled so injected failures surface immediately.
+func (g *Gateway) Client() *chain.HTTPNodeClient {
+	return chain.NewNodeClient(g.URL(), chain.Config{MaxRetries: -1})
+}
+
+// Close shuts down the fake gateway.
+func (g *Gateway) Close() {
+	g.server.Close()
+}
+
+// AddBlock appends a block to the canonical chain at the next slot and
+// returns it.
+func (g *Gateway) AddBlock(opts ...BlockOption) *gateway.BlockResp {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	slot := uint64(len(g.canonical))
+	b := &gateway.BlockResp{
+		Slot:       slot,
+		BlockHash:  fakeHash("block", slot, g.forks),
+		ParentHash: g.headHash(),
+		StateHash:  fakeHash("state", slot, g.forks),
+		Timestamp:  genesisTimestamp + slot*12,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	g.canonical = append(g.canonical, b)
+	g.blocks[b.BlockHash] = b
+	return b
+}
+
+// AddEmptySlot appends a slot without a block to the canonical chain.
+// WithProposer sets the proposer that missed the slot.
+func (g *Gateway) AddEmptySlot(opts ...BlockOption) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b := &gateway.BlockResp{
+		Slot:      uint64(len(g.canonical)),
+		BlockHash: photonsha256.Zero.Hex(),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	g.canonical = append(g.canonical, b)
+}
+
+// Fork drops every canonical slot from the given slot onwards. Blocks
+// added afterwards build a new branch with different hashes. Orphaned
+// blocks remain queryable by hash until PruneOrphans is called.
+func (g *Gateway) Fork(slot uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if slot < uint64(len(g.canonical)) {
+		g.canonical = g.canonical[:slot]
+	}
+	g.forks++
+}
+
+// PruneOrphans forgets every block that is no longer canonical, the way
+// a node does once it discards a losing branch.
+func (g *Gateway) PruneOrphans() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	canonical := make(map[string]bool, len(g.canonical))
+	for _, b := range g.canonical {
+		canonical[b.BlockHash] = true
+	}
+
+	for h := range g.blocks {
+		if !canonical[h] {
+			delete(g.blocks, h)
+		}
+	}
+}
+
+// Finalize marks the given slot as finalized in the chain status.
+func (g *Gateway) Finalize(slot uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.finalizedSlot = slot
+}
+
+// Block returns the canonical block at the given slot, nil if absent.
+func (g *Gateway) Block(slot uint64) *gateway.BlockResp {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if slot >= uint64(len(g.canonical)) {
+		return nil
+	}
+
+	return g.canonical[slot]
+}
+
+// SetAccount sets the account returned for the public key.
+func (g *Gateway) SetAccount(pk string, a *gateway.AccountResp) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.accounts[pk] = a
+}
+
+// SetValidator sets the validator returned for the public key.
+func (g *Gateway) SetValidator(pk string, v *gateway.ValidatorResp) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.validator[pk] = v
+}
+
+// SetValidators sets the response of every validators page.
+func (g *Gateway) SetValidators(vs *gateway.ValidatorsResp) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.validators = vs
+}
+
+// SetAuditor sets the auditor returned for the public key.
+func (g *Gateway) SetAuditor(pk string, a *gateway.ValidatorResp) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.auditor[pk] = a
+}
+
+// SetAuditors sets the response of every auditors page. A nil value
+// makes the gateway answer with gateway.ErrNullAuditors.
+func (g *Gateway) SetAuditors(as *gateway.AuditorsResp) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.auditors = as
+}
+
+// SetStorageContract sets the storage contract committed by the tx hash.
+func (g *Gateway) SetStorageContract(txHash string, sc *gateway.StorageResp) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.contracts[txHash] = sc
+}
+
+// SetCommittees sets the committees of the given slot.
+func (g *Gateway) SetCommittees(slot uint64, cs []*chain.Committee) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.committees[slot] = cs
+}
+
+// FailNext makes the next n requests to the path fail with a 503, the
+// way an unreachable or restarting node does. An empty path matches
+// every request.
+func (g *Gateway) FailNext(path string, n int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.failures[path] = n
+}
+
+func (g *Gateway) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.mu.Lock()
+		fail := false
+		for _, path := range []string{"", r.URL.Path} {
+			if g.failures[path] > 0 {
+				g.failures[path]--
+				fail = true
+				break
+			}
+		}
+		g.mu.Unlock()
+
+		if fail {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(&envelope{
+				Code: http.StatusServiceUnavailable,
+				Msg:  "injected failure",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (g *Gateway) handleChainStatus(w http.ResponseWriter, _ *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cs := &gateway.ChainStatusResp{}
+	if n := len(g.canonical); n > 0 {
+		cs.Best.Slot = uint64(n - 1)
+		cs.Best.Hash = g.headHash()
+	}
+	if g.finalizedSlot < uint64(len(g.canonical)) {
+		cs.Finalized.Slot = g.finalizedSlot
+		cs.Finalized.Hash = g.canonical[g.finalizedSlot].BlockHash
+	}
+
+	writeData(w, cs)
+}
+
+func (g *Gateway) handleBlock(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if hash := r.URL.Query().Get("hash"); hash != "" {
+		b, ok := g.blocks[hash]
+		if !ok {
+			writeError(w, http.StatusNotFound, "block not found")
+			return
+		}
+
+		writeData(w, b)
+		return
+	}
+
+	slot, err := strconv.ParseUint(r.URL.Query().Get("slot"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if slot >= uint64(len(g.canonical)) {
+		writeError(w, http.StatusNotFound, "slot is in the future")
+		return
+	}
+
+	writeData(w, g.canonical[slot])
+}
+
+func (g *Gateway) handleAccount(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	a, ok := g.accounts[r.URL.Query().Get("public_key")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "account not found")
+		return
+	}
+
+	writeData(w, a)
+}
+
+func (g *Gateway) handleValidator(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	v, ok := g.validator[r.URL.Query().Get("public_key")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "validator not found")
+		return
+	}
+
+	writeData(w, v)
+}
+
+func (g *Gateway) handleValidators(w http.ResponseWriter, _ *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	writeData(w, g.validators)
+}
+
+func (g *Gateway) handleAuditor(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	a, ok := g.auditor[r.URL.Query().Get("public_key")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "auditor not found")
+		return
+	}
+
+	writeData(w, a)
+}
+
+func (g *Gateway) handleAuditors(w http.ResponseWriter, _ *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.auditors == nil {
+		writeError(
+			w,
+			http.StatusInternalServerError,
+			gateway.ErrNullAuditors.Error(),
+		)
+		return
+	}
+
+	writeData(w, g.auditors)
+}
+
+func (g *Gateway) handleStorageContract(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sc, ok := g.contracts[r.URL.Query().Get("storage_hash")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "storage contract not found")
+		return
+	}
+
+	writeData(w, sc)
+}
+
+func (g *Gateway) handleCommittees(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	slot, err := strconv.ParseUint(r.URL.Query().Get("slot"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cs, ok := g.committees[slot]
+	if !ok {
+		cs = []*chain.Committee{}
+	}
+
+	writeData(w, cs)
+}
+
+// headHash returns the hash of the latest non-empty canonical block,
+// which is the parent of the next block. Must hold g.mu.
+func (g *Gateway) headHash() string {
+	for i := len(g.canonical) - 1; i >= 0; i-- {
+		if h := g.canonical[i].BlockHash; h != photonsha256.Zero.Hex() {
+			return h
+		}
+	}
+
+	return photonsha256.Zero.Hex()
+}
+
+func fakeHash(kind string, slot uint64, fork uint64) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%d", kind, slot, fork)))
+	return hex.EncodeToString(h[:])
+}
+
+type envelope struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data any    `json:"data,omitempty"`
+}
+
+func writeData(w http.ResponseWriter, data any) {
+	writeEnvelope(w, &envelope{Code: http.StatusOK, Data: data})
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeEnvelope(w, &envelope{Code: code, Msg: msg})
+}
+
+func writeEnvelope(w http.ResponseWriter, e *envelope) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,101 @@
+package chain
+
+import "time"
+
+// Config defines the photon node client configuration.
+type Config struct {
+	// Timeout bounds a single HTTP attempt.
+	Timeout time.Duration `yaml:"timeout"`
+	// Timeouts overrides Timeout per gateway path, e.g. "block".
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+	// MaxRetries is the number of retries after the first attempt. A
+	// negative value disables retries.
+	MaxRetries int `yaml:"max_retries"`
+	// InitialBackoff is the delay before the first retry. It doubles on
+	// every retry up to MaxBackoff, with random jitter applied.
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// BreakerThreshold is the number of consecutive failed calls that
+	// opens the circuit breaker.
+	BreakerThreshold int `yaml:"breaker_threshold"`
+	// BreakerCooldown is how long the breaker stays open before a single
+	// probe call is let through.
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown"`
+	// MaxIdleConns is the size of the shared keep-alive connection pool.
+	MaxIdleConns int `yaml:"max_idle_conns"`
+	// HealthCheckInterval is how often every node of a multi-endpoint
+	// client is probed with ChainStatus.
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"`
+	// Quorum is the number of nodes that must return the same block for
+	// BlockBySlot to succeed. Values below 2 disable quorum reads.
+	Quorum int `yaml:"quorum"`
+}
+
+// DefaultConfig returns the node client configuration used when a field
+// is not set.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          5 * time.Second,
+		MaxRetries:       3,
+		InitialBackoff:   200 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+		MaxIdleConns:     16,
+
+		HealthCheckInterval: 10 * time.Second,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.Timeout == 0 {
+		c.Timeout = d.Timeout
+	}
+
+	if c.MaxRetries == 0 {
+		c.MaxRetries = d.MaxRetries
+	}
+
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = d.InitialBackoff
+	}
+
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = d.MaxBackoff
+	}
+
+	if c.BreakerThreshold == 0 {
+		c.BreakerThreshold = d.BreakerThreshold
+	}
+
+	if c.BreakerCooldown == 0 {
+		c.BreakerCooldown = d.BreakerCooldown
+	}
+
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = d.MaxIdleConns
+	}
+
+	if c.HealthCheckInterval == 0 {
+		c.HealthCheckInterval = d.HealthCheckInterval
+	}
+
+	return c
+}
+
+func (c Config) retries() int {
+	if c.MaxRetries < 0 {
+		return 0
+	}
+
+	return c.MaxRetries
+}
+
+func (c Config) timeout(path string) time.Duration {
+	if t, ok := c.Timeouts[path]; ok && t > 0 {
+		return t
+	}
+
+	return c.Timeout
+}
@@ -0,0 +1,133 @@
+package chain
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+)
+
+var (
+	nodeRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "photon_explorer",
+			Subsystem: "node",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the photon node calls, retries included.",
+		},
+		[]string{"method"},
+	)
+	nodeRequestErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "photon_explorer",
+			Subsystem: "node",
+			Name:      "request_errors_total",
+			Help:      "Photon node calls that failed.",
+		},
+		[]string{"method"},
+	)
+)
+
+// instrumentedClient reports the latency and the errors of the calls of
+// a NodeClient by method.
+type instrumentedClient struct {
+	node NodeClient
+}
+
+// Instrument returns node reporting its calls to the metrics.
+func Instrument(node NodeClient) NodeClient {
+	return &instrumentedClient{node: node}
+}
+
+func observe(method string, start time.Time, err *error) {
+	nodeRequestDuration.WithLabelValues(method).
+		Observe(time.Since(start).Seconds())
+	if *err != nil {
+		nodeRequestErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (c *instrumentedClient) ChainStatus(
+	ctx context.Context,
+) (_ *gateway.ChainStatusResp, err error) {
+	defer observe("ChainStatus", time.Now(), &err)
+	return c.node.ChainStatus(ctx)
+}
+
+func (c *instrumentedClient) BlockBySlot(
+	ctx context.Context,
+	slot uint64,
+) (_ *gateway.BlockResp, err error) {
+	defer observe("BlockBySlot", time.Now(), &err)
+	return c.node.BlockBySlot(ctx, slot)
+}
+
+func (c *instrumentedClient) BlockByHash(
+	ctx context.Context,
+	hash string,
+) (_ *gateway.BlockResp, err error) {
+	defer observe("BlockByHash", time.Now(), &err)
+	return c.node.BlockByHash(ctx, hash)
+}
+
+func (c *instrumentedClient) Account(
+	ctx context.Context,
+	pk string,
+) (_ *gateway.AccountResp, err error) {
+	defer observe("Account", time.Now(), &err)
+	return c.node.Account(ctx, pk)
+}
+
+func (c *instrumentedClient) Validator(
+	ctx context.Context,
+	pk string,
+) (_ *gateway.ValidatorResp, err error) {
+	defer observe("Validator", time.Now(), &err)
+	return c.node.Validator(ctx, pk)
+}
+
+func (c *instrumentedClient) Validators(
+	ctx context.Context,
+	pageToken string,
+	pageSize uint64,
+) (_ *gateway.ValidatorsResp, err error) {
+	defer observe("Validators", time.Now(), &err)
+	return c.node.Validators(ctx, pageToken, pageSize)
+}
+
+func (c *instrumentedClient) Auditor(
+	ctx context.Context,
+	pk string,
+) (_ *gateway.ValidatorResp, err error) {
+	defer observe("Auditor", time.Now(), &err)
+	return c.node.Auditor(ctx, pk)
+}
+
+func (c *instrumentedClient) Auditors(
+	ctx context.Context,
+	pageToken string,
+	pageSize uint64,
+) (_ *gateway.AuditorsResp, err error) {
+	defer observe("Auditors", time.Now(), &err)
+	return c.node.Auditors(ctx, pageToken, pageSize)
+}
+
+func (c *instrumentedClient) StorageContract(
+	ctx context.Context,
+	txHash string,
+	blockHash string,
+) (_ *gateway.StorageResp, err error) {
+	defer observe("StorageContract", time.Now(), &err)
+	return c.node.StorageContract(ctx, txHash, blockHash)
+}
+
+func (c *instrumentedClient) Committees(
+	ctx context.Context,
+	slot uint64,
+) (_ []*Committee, err error) {
+	defer observe("Committees", time.Now(), &err)
+	return c.node.Committees(ctx, slot)
+}
@@ -0,0 +1,381 @@
+package chain
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/photon-storage/go-common/log"
+	"github.com/photon-storage/go-photon/chain/gateway"
+)
+
+// ErrNoQuorum is returned by a quorum read when not enough nodes agree
+// on the result.
+var ErrNoQuorum = errors.New("photon nodes did not reach quorum")
+
+// Endpoints is a list of photon node gateway URLs. It decodes from either
+// a single YAML string or a YAML sequence.
+type Endpoints []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *Endpoints) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*e = Endpoints{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	*e = list
+	return nil
+}
+
+type nodeState struct {
+	client  *HTTPNodeClient
+	index   int
+	healthy bool
+	head    uint64
+}
+
+// MultiNodeClient spreads calls over several photon nodes. Each node is
+// health checked with ChainStatus, calls go to the healthy node with the
+// highest head, and fail over to the next node when it stops answering.
+// When Config.Quorum is set, BlockBySlot results must agree across that
+// many nodes before they are returned.
+type MultiNodeClient struct {
+	cfg   Config
+	mu    sync.RWMutex
+	nodes []*nodeState
+}
+
+var _ NodeClient = (*MultiNodeClient)(nil)
+
+// NewMultiNodeClient returns a client over the endpoints and starts the
+// health checks, which run until ctx is done.
+func NewMultiNodeClient(
+	ctx context.Context,
+	endpoints []string,
+	cfg Config,
+) (*MultiNodeClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no photon node endpoint configured")
+	}
+
+	cfg = cfg.withDefaults()
+	if cfg.Quorum > len(endpoints) {
+		return nil, errors.Errorf(
+			"quorum %d exceeds the number of endpoints %d",
+			cfg.Quorum,
+			len(endpoints),
+		)
+	}
+
+	m := &MultiNodeClient{cfg: cfg}
+	for i, endpoint := range endpoints {
+		m.nodes = append(m.nodes, &nodeState{
+			client:  NewNodeClient(endpoint, cfg),
+			index:   i,
+			healthy: true,
+		})
+	}
+
+	m.checkHealth(ctx)
+	if len(m.nodes) > 1 {
+		go m.runHealthChecks(ctx)
+	}
+
+	return m, nil
+}
+
+func (m *MultiNodeClient) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			m.checkHealth(ctx)
+		}
+	}
+}
+
+func (m *MultiNodeClient) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, n := range m.nodes {
+		wg.Add(1)
+		go func(n *nodeState) {
+			defer wg.Done()
+
+			cs, err := n.client.ChainStatus(ctx)
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			if err != nil {
+				if n.healthy {
+					log.Warn("Photon node unhealthy",
+						"endpoint", n.client.endpoint,
+						"error", err,
+					)
+				}
+				n.healthy = false
+				return
+			}
+
+			if !n.healthy {
+				log.Info("Photon node healthy again",
+					"endpoint", n.client.endpoint,
+				)
+			}
+			n.healthy = true
+			n.head = cs.Best.Slot
+		}(n)
+	}
+	wg.Wait()
+}
+
+// ranked returns the nodes in routing order: healthy nodes with the
+// highest head first, unhealthy nodes last as a final resort.
+func (m *MultiNodeClient) ranked() []*nodeState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ns := make([]*nodeState, len(m.nodes))
+	copy(ns, m.nodes)
+	sort.SliceStable(ns, func(i, j int) bool {
+		if ns[i].healthy != ns[j].healthy {
+			return ns[i].healthy
+		}
+
+		return ns[i].head > ns[j].head
+	})
+
+	return ns
+}
+
+func (m *MultiNodeClient) markDown(n *nodeState, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n.healthy {
+		log.Warn("Photon node stopped answering, failing over",
+			"endpoint", n.client.endpoint,
+			"error", err,
+		)
+	}
+	n.healthy = false
+}
+
+// call runs fn against the nodes in routing order until one answers.
+func (m *MultiNodeClient) call(fn func(n *HTTPNodeClient) error) error {
+	var err error
+	for _, n := range m.ranked() {
+		if err = fn(n.client); err == nil || !IsUnavailable(err) {
+			return err
+		}
+
+		m.markDown(n, err)
+	}
+
+	return err
+}
+
+// ChainStatus requests chain status of the best photon node.
+func (m *MultiNodeClient) ChainStatus(
+	ctx context.Context,
+) (*gateway.ChainStatusResp, error) {
+	var cs *gateway.ChainStatusResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		cs, err = n.ChainStatus(ctx)
+		return err
+	})
+	return cs, err
+}
+
+// BlockBySlot requests chain block by the given slot. In quorum mode the
+// block is requested from every healthy node and returned only when at
+// least Config.Quorum nodes report the same block hash.
+func (m *MultiNodeClient) BlockBySlot(
+	ctx context.Context,
+	slot uint64,
+) (*gateway.BlockResp, error) {
+	if m.cfg.Quorum > 1 {
+		return m.quorumBlockBySlot(ctx, slot)
+	}
+
+	var b *gateway.BlockResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		b, err = n.BlockBySlot(ctx, slot)
+		return err
+	})
+	return b, err
+}
+
+func (m *MultiNodeClient) quorumBlockBySlot(
+	ctx context.Context,
+	slot uint64,
+) (*gateway.BlockResp, error) {
+	type answer struct {
+		node  *nodeState
+		block *gateway.BlockResp
+		err   error
+	}
+
+	ns := m.ranked()
+	answers := make([]answer, len(ns))
+	var wg sync.WaitGroup
+	for i, n := range ns {
+		wg.Add(1)
+		go func(i int, n *nodeState) {
+			defer wg.Done()
+
+			b, err := n.client.BlockBySlot(ctx, slot)
+			answers[i] = answer{node: n, block: b, err: err}
+		}(i, n)
+	}
+	wg.Wait()
+
+	votes := make(map[string]int)
+	for _, a := range answers {
+		if a.err != nil {
+			if IsUnavailable(a.err) {
+				m.markDown(a.node, a.err)
+			}
+			continue
+		}
+
+		votes[a.block.BlockHash]++
+	}
+
+	// Answers are in routing order, so ties resolve to the best node.
+	for _, a := range answers {
+		if a.err == nil && votes[a.block.BlockHash] >= m.cfg.Quorum {
+			if len(votes) > 1 {
+				log.Warn("Photon nodes disagree on block",
+					"slot", slot,
+					"votes", votes,
+				)
+			}
+
+			return a.block, nil
+		}
+	}
+
+	return nil, errors.Wrapf(ErrNoQuorum, "slot %d, votes %v", slot, votes)
+}
+
+// BlockByHash requests chain block by the given hash.
+func (m *MultiNodeClient) BlockByHash(
+	ctx context.Context,
+	hash string,
+) (*gateway.BlockResp, error) {
+	var b *gateway.BlockResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		b, err = n.BlockByHash(ctx, hash)
+		return err
+	})
+	return b, err
+}
+
+// Account gets account detail by account public key.
+func (m *MultiNodeClient) Account(
+	ctx context.Context,
+	pk string,
+) (*gateway.AccountResp, error) {
+	var a *gateway.AccountResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		a, err = n.Account(ctx, pk)
+		return err
+	})
+	return a, err
+}
+
+// Validator gets validator by account public key.
+func (m *MultiNodeClient) Validator(
+	ctx context.Context,
+	pk string,
+) (*gateway.ValidatorResp, error) {
+	var v *gateway.ValidatorResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		v, err = n.Validator(ctx, pk)
+		return err
+	})
+	return v, err
+}
+
+// Validators gets validators by pagination params.
+func (m *MultiNodeClient) Validators(
+	ctx context.Context,
+	pageToken string,
+	pageSize uint64,
+) (*gateway.ValidatorsResp, error) {
+	var vs *gateway.ValidatorsResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		vs, err = n.Validators(ctx, pageToken, pageSize)
+		return err
+	})
+	return vs, err
+}
+
+// Auditor gets auditor by account public key.
+func (m *MultiNodeClient) Auditor(
+	ctx context.Context,
+	pk string,
+) (*gateway.ValidatorResp, error) {
+	var a *gateway.ValidatorResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		a, err = n.Auditor(ctx, pk)
+		return err
+	})
+	return a, err
+}
+
+// Auditors gets auditors by pagination params.
+func (m *MultiNodeClient) Auditors(
+	ctx context.Context,
+	pageToken string,
+	pageSize uint64,
+) (*gateway.AuditorsResp, error) {
+	var as *gateway.AuditorsResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		as, err = n.Auditors(ctx, pageToken, pageSize)
+		return err
+	})
+	return as, err
+}
+
+// StorageContract gets storage contract detail by tx hash.
+func (m *MultiNodeClient) StorageContract(
+	ctx context.Context,
+	txHash string,
+	blockHash string,
+) (*gateway.StorageResp, error) {
+	var sc *gateway.StorageResp
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		sc, err = n.StorageContract(ctx, txHash, blockHash)
+		return err
+	})
+	return sc, err
+}
+
+// Committees returns committees info by the given slot.
+func (m *MultiNodeClient) Committees(
+	ctx context.Context,
+	slot uint64,
+) ([]*Committee, error) {
+	var cs []*Committee
+	err := m.call(func(n *HTTPNodeClient) (err error) {
+		cs, err = n.Committees(ctx, slot)
+		return err
+	})
+	return cs, err
+}
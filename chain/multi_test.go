@@ -0,0 +1,130 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+)
+
+func TestEndpointsUnmarshal(t *testing.T) {
+	testCases := []struct {
+		name string
+		doc  string
+		want chain.Endpoints
+	}{
+		{
+			name: "single endpoint",
+			doc:  `p: "http://a:6100"`,
+			want: chain.Endpoints{"http://a:6100"},
+		},
+		{
+			name: "endpoint list",
+			doc:  "p:\n  - \"http://a:6100\"\n  - \"http://b:6100\"",
+			want: chain.Endpoints{"http://a:6100", "http://b:6100"},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			v := struct {
+				P chain.Endpoints `yaml:"p"`
+			}{}
+			if err := yaml.Unmarshal([]byte(c.doc), &v); err != nil {
+				t.Fatalf("unmarshal error = %v", err)
+			}
+
+			if len(v.P) != len(c.want) {
+				t.Fatalf("endpoints = %v, want %v", v.P, c.want)
+			}
+			for i := range c.want {
+				if v.P[i] != c.want[i] {
+					t.Errorf("endpoints = %v, want %v", v.P, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiNodeClientFailover(t *testing.T) {
+	behind, ahead := chaintest.NewGateway(), chaintest.NewGateway()
+	defer behind.Close()
+	defer ahead.Close()
+
+	behind.AddBlock()
+	ahead.AddBlock()
+	ahead.AddBlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	node, err := chain.NewMultiNodeClient(
+		ctx,
+		[]string{behind.URL(), ahead.URL()},
+		chain.Config{MaxRetries: -1},
+	)
+	if err != nil {
+		t.Fatalf("new multi node client error = %v", err)
+	}
+
+	cs, err := node.ChainStatus(ctx)
+	if err != nil {
+		t.Fatalf("chain status error = %v", err)
+	}
+	if cs.Best.Slot != 1 {
+		t.Errorf("head slot = %d, want the highest head 1", cs.Best.Slot)
+	}
+
+	ahead.FailNext("", 1)
+	if _, err := node.BlockBySlot(ctx, 0); err != nil {
+		t.Fatalf("block by slot must fail over, error = %v", err)
+	}
+}
+
+func TestMultiNodeClientQuorum(t *testing.T) {
+	gws := []*chaintest.Gateway{
+		chaintest.NewGateway(),
+		chaintest.NewGateway(),
+		chaintest.NewGateway(),
+	}
+	urls := make([]string, len(gws))
+	for i, gw := range gws {
+		defer gw.Close()
+		gw.AddBlock()
+		urls[i] = gw.URL()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	node, err := chain.NewMultiNodeClient(
+		ctx,
+		urls,
+		chain.Config{MaxRetries: -1, Quorum: 2},
+	)
+	if err != nil {
+		t.Fatalf("new multi node client error = %v", err)
+	}
+
+	// One node serving a different block is outvoted.
+	gws[0].Fork(0)
+	gws[0].AddBlock()
+	b, err := node.BlockBySlot(ctx, 0)
+	if err != nil {
+		t.Fatalf("block by slot error = %v", err)
+	}
+	if b.BlockHash != gws[1].Block(0).BlockHash {
+		t.Errorf("block hash = %s, want the majority hash", b.BlockHash)
+	}
+
+	// Without a majority the read fails.
+	gws[1].Fork(0)
+	gws[1].Fork(0)
+	gws[1].AddBlock()
+	if _, err := node.BlockBySlot(ctx, 0); !errors.Is(err, chain.ErrNoQuorum) {
+		t.Errorf("error = %v, want %v", err, chain.ErrNoQuorum)
+	}
+}
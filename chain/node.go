@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/photon-storage/go-photon/crypto/bls"
+	"github.com/pkg/errors"
 
 	"github.com/photon-storage/go-photon/chain/gateway"
+	"github.com/photon-storage/go-photon/crypto/bls"
 )
 
 const (
@@ -26,22 +28,67 @@ const (
 	committeesPath      = "committees"
 )
 
-// NodeClient gets the required data according to the HTTP request
+// NodeClient defines the photon node queries required by the indexer
+// and the api service.
+type NodeClient interface {
+	ChainStatus(ctx context.Context) (*gateway.ChainStatusResp, error)
+	BlockBySlot(ctx context.Context, slot uint64) (*gateway.BlockResp, error)
+	BlockByHash(ctx context.Context, hash string) (*gateway.BlockResp, error)
+	Account(ctx context.Context, pk string) (*gateway.AccountResp, error)
+	Validator(ctx context.Context, pk string) (*gateway.ValidatorResp, error)
+	Validators(
+		ctx context.Context,
+		pageToken string,
+		pageSize uint64,
+	) (*gateway.ValidatorsResp, error)
+	Auditor(ctx context.Context, pk string) (*gateway.ValidatorResp, error)
+	Auditors(
+		ctx context.Context,
+		pageToken string,
+		pageSize uint64,
+	) (*gateway.AuditorsResp, error)
+	StorageContract(
+		ctx context.Context,
+		txHash string,
+		blockHash string,
+	) (*gateway.StorageResp, error)
+	Committees(ctx context.Context, slot uint64) ([]*Committee, error)
+}
+
+// HTTPNodeClient gets the required data according to the HTTP request
 // from the photon node.
-type NodeClient struct {
+// Failed requests are retried with exponential backoff, and a circuit
+// breaker fails calls fast while the node keeps failing.
+type HTTPNodeClient struct {
 	endpoint string
+	cfg      Config
+	client   *http.Client
+	breaker  *breaker
 }
 
-// NewNodeClient returns a new node instance.
-func NewNodeClient(endpoint string) *NodeClient {
-	return &NodeClient{endpoint: endpoint}
+var _ NodeClient = (*HTTPNodeClient)(nil)
+
+// NewNodeClient returns a new node instance. Zero fields of cfg take the
+// values of DefaultConfig.
+func NewNodeClient(endpoint string, cfg Config) *HTTPNodeClient {
+	cfg = cfg.withDefaults()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConns
+
+	return &HTTPNodeClient{
+		endpoint: endpoint,
+		cfg:      cfg,
+		client:   &http.Client{Transport: transport},
+		breaker:  newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
 }
 
 // ChainStatus requests chain status of photon node.
-func (n *NodeClient) ChainStatus(ctx context.Context) (*gateway.ChainStatusResp, error) {
+func (n *HTTPNodeClient) ChainStatus(ctx context.Context) (*gateway.ChainStatusResp, error) {
 	url := fmt.Sprintf("%s/%s", n.endpoint, chainStatusPath)
 	cs := &gateway.ChainStatusResp{}
-	if err := httpGet(ctx, url, cs); err != nil {
+	if err := n.httpGet(ctx, chainStatusPath, url, cs); err != nil {
 		return nil, err
 	}
 
@@ -49,32 +96,32 @@ func (n *NodeClient) ChainStatus(ctx context.Context) (*gateway.ChainStatusResp,
 }
 
 // BlockBySlot requests chain block by the given slot.
-func (n *NodeClient) BlockBySlot(ctx context.Context, slot uint64) (*gateway.BlockResp, error) {
+func (n *HTTPNodeClient) BlockBySlot(ctx context.Context, slot uint64) (*gateway.BlockResp, error) {
 	url := fmt.Sprintf("%s/%s?slot=%d", n.endpoint, blockPath, slot)
 	b := &gateway.BlockResp{}
-	return b, httpGet(ctx, url, b)
+	return b, n.httpGet(ctx, blockPath, url, b)
 }
 
 // BlockByHash requests chain block by the given hash.
-func (n *NodeClient) BlockByHash(ctx context.Context, hash string) (*gateway.BlockResp, error) {
+func (n *HTTPNodeClient) BlockByHash(ctx context.Context, hash string) (*gateway.BlockResp, error) {
 	url := fmt.Sprintf("%s/%s?hash=%s", n.endpoint, blockPath, hash)
 	b := &gateway.BlockResp{}
-	return b, httpGet(ctx, url, b)
+	return b, n.httpGet(ctx, blockPath, url, b)
 }
 
 // Account gets account detail by account public key.
-func (n *NodeClient) Account(ctx context.Context, pk string) (*gateway.AccountResp, error) {
+func (n *HTTPNodeClient) Account(ctx context.Context, pk string) (*gateway.AccountResp, error) {
 	if _, err := bls.PublicKeyFromHex(strings.ToLower(pk)); err != nil {
 		return nil, err
 	}
 
 	url := fmt.Sprintf("%s/%s?public_key=%s", n.endpoint, accountPath, pk)
 	a := &gateway.AccountResp{}
-	return a, httpGet(ctx, url, a)
+	return a, n.httpGet(ctx, accountPath, url, a)
 }
 
 // Validator gets validator by account public key.
-func (n *NodeClient) Validator(
+func (n *HTTPNodeClient) Validator(
 	ctx context.Context,
 	pk string,
 ) (*gateway.ValidatorResp, error) {
@@ -85,11 +132,11 @@ func (n *NodeClient) Validator(
 		pk,
 	)
 	v := &gateway.ValidatorResp{}
-	return v, httpGet(ctx, url, v)
+	return v, n.httpGet(ctx, validatorPath, url, v)
 }
 
 // Validators gets validators by pagination params.
-func (n *NodeClient) Validators(
+func (n *HTTPNodeClient) Validators(
 	ctx context.Context,
 	pageToken string,
 	pageSize uint64,
@@ -102,11 +149,11 @@ func (n *NodeClient) Validators(
 		pageSize,
 	)
 	v := &gateway.ValidatorsResp{}
-	return v, httpGet(ctx, url, v)
+	return v, n.httpGet(ctx, validatorsPath, url, v)
 }
 
 // Auditor gets auditor by account public key.
-func (n *NodeClient) Auditor(
+func (n *HTTPNodeClient) Auditor(
 	ctx context.Context,
 	pk string,
 ) (*gateway.ValidatorResp, error) {
@@ -117,11 +164,11 @@ func (n *NodeClient) Auditor(
 		pk,
 	)
 	v := &gateway.ValidatorResp{}
-	return v, httpGet(ctx, url, v)
+	return v, n.httpGet(ctx, auditorPath, url, v)
 }
 
 // Auditors gets auditors by pagination params.
-func (n *NodeClient) Auditors(
+func (n *HTTPNodeClient) Auditors(
 	ctx context.Context,
 	pageToken string,
 	pageSize uint64,
@@ -134,11 +181,11 @@ func (n *NodeClient) Auditors(
 		pageSize,
 	)
 	a := &gateway.AuditorsResp{}
-	return a, httpGet(ctx, url, a)
+	return a, n.httpGet(ctx, auditorsPath, url, a)
 }
 
 // StorageContract gets storage contract detail by tx hash.
-func (n *NodeClient) StorageContract(
+func (n *HTTPNodeClient) StorageContract(
 	ctx context.Context,
 	txHash string,
 	blockHash string,
@@ -151,7 +198,7 @@ func (n *NodeClient) StorageContract(
 		blockHash,
 	)
 	s := &gateway.StorageResp{}
-	return s, httpGet(ctx, url, s)
+	return s, n.httpGet(ctx, storageContractPath, url, s)
 }
 
 // TODO(doris): set committees response exported in go-photon repo.
@@ -161,7 +208,7 @@ type Committee struct {
 }
 
 // Committees returns committees info by the given slot.
-func (n *NodeClient) Committees(
+func (n *HTTPNodeClient) Committees(
 	ctx context.Context,
 	slot uint64,
 ) ([]*Committee, error) {
@@ -172,7 +219,7 @@ func (n *NodeClient) Committees(
 		slot,
 	)
 	c := []*Committee{}
-	return c, httpGet(ctx, url, &c)
+	return c, n.httpGet(ctx, committeesPath, url, &c)
 }
 
 type photonResponse struct {
@@ -181,8 +228,87 @@ type photonResponse struct {
 	Data json.RawMessage `json:"data,omitempty"`
 }
 
-func httpGet(ctx context.Context, url string, result interface{}) error {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+// errRetryable marks failures worth retrying: the node could not be
+// reached or did not answer properly. An error envelope from a healthy
+// node is final.
+type errRetryable struct {
+	error
+}
+
+func (e errRetryable) Unwrap() error {
+	return e.error
+}
+
+// IsUnavailable reports whether err means the node could not be reached,
+// as opposed to the node answering with an error.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || errors.As(err, &errRetryable{})
+}
+
+func (n *HTTPNodeClient) httpGet(
+	ctx context.Context,
+	path string,
+	url string,
+	result interface{},
+) error {
+	if err := n.breaker.allow(); err != nil {
+		return err
+	}
+
+	// A half-open probe the caller gives up on must be released, or the
+	// circuit stays open for good.
+	settled := false
+	defer func() {
+		if !settled {
+			n.breaker.cancel()
+		}
+	}()
+
+	var err error
+	for attempt := 0; attempt <= n.cfg.retries(); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(n.backoff(attempt)):
+			}
+		}
+
+		err = n.get(ctx, path, url, result)
+		if err == nil || !errors.As(err, &errRetryable{}) {
+			settled = true
+			n.breaker.success()
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+
+	settled = true
+	n.breaker.failure()
+	return err
+}
+
+// backoff returns the delay before the given retry attempt, doubling from
+// InitialBackoff up to MaxBackoff with jitter in [d/2, d).
+func (n *HTTPNodeClient) backoff(attempt int) time.Duration {
+	d := n.cfg.InitialBackoff << (attempt - 1)
+	if d <= 0 || d > n.cfg.MaxBackoff {
+		d = n.cfg.MaxBackoff
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (n *HTTPNodeClient) get(
+	ctx context.Context,
+	path string,
+	url string,
+	result interface{},
+) error {
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.timeout(path))
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -190,21 +316,27 @@ func httpGet(ctx context.Context, url string, result interface{}) error {
 		return err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := n.client.Do(req)
 	if err != nil {
-		return err
+		return errRetryable{err}
 	}
 
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return errRetryable{err}
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errRetryable{fmt.Errorf(
+			"request photon node failed, status: %s",
+			resp.Status,
+		)}
 	}
 
 	pr := &photonResponse{}
 	if err := json.Unmarshal(body, pr); err != nil {
-		return err
+		return errRetryable{err}
 	}
 
 	if pr.Code != http.StatusOK {
@@ -0,0 +1,173 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/photon-storage/go-photon/crypto/sha256"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+)
+
+func TestNodeClientAgainstFakeGateway(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	ctx := context.Background()
+	node := gw.Client()
+
+	b0 := gw.AddBlock()
+	gw.AddEmptySlot()
+	b2 := gw.AddBlock()
+
+	cs, err := node.ChainStatus(ctx)
+	if err != nil {
+		t.Fatalf("chain status error = %v", err)
+	}
+	if cs.Best.Slot != 2 || cs.Best.Hash != b2.BlockHash {
+		t.Errorf("chain head = (%d, %s), want (2, %s)",
+			cs.Best.Slot, cs.Best.Hash, b2.BlockHash)
+	}
+
+	empty, err := node.BlockBySlot(ctx, 1)
+	if err != nil {
+		t.Fatalf("block by slot error = %v", err)
+	}
+	if empty.BlockHash != sha256.Zero.Hex() {
+		t.Errorf("empty slot hash = %s, want zero hash", empty.BlockHash)
+	}
+
+	if b2.ParentHash != b0.BlockHash {
+		t.Errorf("parent hash = %s, want %s", b2.ParentHash, b0.BlockHash)
+	}
+
+	gw.Fork(2)
+	fb2 := gw.AddBlock()
+	if fb2.BlockHash == b2.BlockHash {
+		t.Fatal("forked block must not reuse the orphaned hash")
+	}
+
+	orphan, err := node.BlockByHash(ctx, b2.BlockHash)
+	if err != nil {
+		t.Fatalf("block by orphan hash error = %v", err)
+	}
+	if orphan.Slot != 2 {
+		t.Errorf("orphan slot = %d, want 2", orphan.Slot)
+	}
+
+	gw.PruneOrphans()
+	if _, err := node.BlockByHash(ctx, b2.BlockHash); err == nil {
+		t.Error("pruned orphan must not be found")
+	}
+
+	gw.FailNext("/block", 1)
+	if _, err := node.BlockBySlot(ctx, 2); err == nil {
+		t.Error("injected failure must surface as an error")
+	}
+
+	blk, err := node.BlockBySlot(ctx, 2)
+	if err != nil {
+		t.Fatalf("block by slot error = %v", err)
+	}
+	if blk.BlockHash != fb2.BlockHash {
+		t.Errorf("block hash = %s, want %s", blk.BlockHash, fb2.BlockHash)
+	}
+}
+
+func TestNodeClientRetry(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.AddBlock()
+	node := chain.NewNodeClient(gw.URL(), chain.Config{
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	})
+
+	gw.FailNext("/block", 2)
+	if _, err := node.BlockBySlot(context.Background(), 0); err != nil {
+		t.Fatalf("block by slot must succeed after retries, error = %v", err)
+	}
+
+	gw.FailNext("/block", 3)
+	_, err := node.BlockBySlot(context.Background(), 0)
+	if !chain.IsUnavailable(err) {
+		t.Errorf("error = %v, want unavailable", err)
+	}
+}
+
+func TestNodeClientCircuitBreaker(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.AddBlock()
+	node := chain.NewNodeClient(gw.URL(), chain.Config{
+		MaxRetries:       -1,
+		BreakerThreshold: 2,
+		BreakerCooldown:  50 * time.Millisecond,
+	})
+
+	ctx := context.Background()
+	gw.FailNext("", 2)
+	for i := 0; i < 2; i++ {
+		if _, err := node.ChainStatus(ctx); err == nil {
+			t.Fatal("injected failure must surface as an error")
+		}
+	}
+
+	if _, err := node.ChainStatus(ctx); !errors.Is(err, chain.ErrCircuitOpen) {
+		t.Errorf("error = %v, want %v", err, chain.ErrCircuitOpen)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := node.ChainStatus(ctx); err != nil {
+		t.Fatalf("probe after cooldown error = %v", err)
+	}
+
+	if _, err := node.ChainStatus(ctx); err != nil {
+		t.Errorf("breaker must close after a successful probe, error = %v", err)
+	}
+}
+
+func TestNodeClientCircuitBreakerCanceledProbe(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.AddBlock()
+	node := chain.NewNodeClient(gw.URL(), chain.Config{
+		MaxRetries:       1,
+		InitialBackoff:   200 * time.Millisecond,
+		MaxBackoff:       200 * time.Millisecond,
+		BreakerThreshold: 1,
+		BreakerCooldown:  10 * time.Millisecond,
+	})
+
+	gw.FailNext("", 2)
+	if _, err := node.ChainStatus(context.Background()); err == nil {
+		t.Fatal("injected failure must surface as an error")
+	}
+	if _, err := node.ChainStatus(context.Background()); !errors.Is(err, chain.ErrCircuitOpen) {
+		t.Fatalf("error = %v, want %v", err, chain.ErrCircuitOpen)
+	}
+
+	// The probe fails once and its caller gives up during the backoff.
+	time.Sleep(20 * time.Millisecond)
+	gw.FailNext("", 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := node.ChainStatus(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if _, err := node.ChainStatus(context.Background()); err != nil {
+		t.Fatalf("probe after a canceled probe error = %v", err)
+	}
+	if _, err := node.ChainStatus(context.Background()); err != nil {
+		t.Errorf("breaker must close after a successful probe, error = %v", err)
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"os"
 
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"github.com/photon-storage/go-common/log"
 	pc "github.com/photon-storage/go-photon/config/config"
 
+	"github.com/photon-storage/photon-explorer/api/graphql"
 	"github.com/photon-storage/photon-explorer/api/server"
 	"github.com/photon-storage/photon-explorer/api/service"
+	"github.com/photon-storage/photon-explorer/api/stream"
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/cmd/runtime/migrate"
 	"github.com/photon-storage/photon-explorer/cmd/runtime/version"
 	"github.com/photon-storage/photon-explorer/config"
-	"github.com/photon-storage/photon-explorer/database/mysql"
+	"github.com/photon-storage/photon-explorer/database"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/health"
 )
 
 var (
@@ -71,6 +78,9 @@ func main() {
 			logFilenameFlag,
 			logColorFlag,
 		},
+		Commands: []*cli.Command{
+			migrate.Command(openDB),
+		},
 	}
 
 	app.Before = func(ctx *cli.Context) error {
@@ -118,23 +128,69 @@ func exec(ctx *cli.Context) error {
 		log.Fatal("reading api config failed", "error", err)
 	}
 
-	db, err := mysql.NewMySQLDB(cfg.MySQL)
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		log.Fatal("initialize database error", "error", err)
+	}
+
+	if err := migration.Check(db); err != nil {
+		log.Fatal("database schema check failed, run migrate up with "+
+			"a matching binary", "error", err)
+	}
+
+	node, err := chain.NewMultiNodeClient(
+		ctx.Context,
+		cfg.NodeGatewayProvider,
+		cfg.NodeClient,
+	)
+	if err != nil {
+		log.Fatal("initialize photon node client error", "error", err)
+	}
+
+	broker := stream.NewBroker(ctx.Context, cfg.Stream, db)
+	go broker.Run()
+
+	executor, err := graphql.New(db, cfg.GraphQL)
 	if err != nil {
-		log.Fatal("initialize mysql db error", "error", err)
+		log.Fatal("initialize graphql schema error", "error", err)
+	}
+
+	instrumented := chain.Instrument(node)
+	svc := service.New(db, instrumented, broker, executor)
+	if cfg.GRPCPort != 0 {
+		log.Info("Starting explorer grpc server...")
+		go server.NewGRPC(cfg.GRPCPort, cfg.AdminTokens, svc).Run()
 	}
 
 	log.Info("Starting explorer api server...")
 
-	server.New(
-		cfg.Port,
-		service.New(db, cfg.NodeGatewayProvider),
-	).Run()
+	checker := health.NewChecker(cfg.Health, db, instrumented)
+	server.New(cfg.Port, cfg.AdminTokens, svc, checker).Run()
 	return nil
 }
 
 // Config defines the config for api service.
 type Config struct {
-	Port                int          `yaml:"port"`
-	MySQL               mysql.Config `yaml:"mysql"`
-	NodeGatewayProvider string       `yaml:"node_gateway_provider"`
+	Port                int             `yaml:"port"`
+	Database            database.Config `yaml:",inline"`
+	NodeGatewayProvider chain.Endpoints `yaml:"node_gateway_provider"`
+	NodeClient          chain.Config    `yaml:"node_client"`
+	Stream              stream.Config   `yaml:"stream"`
+	GraphQL             graphql.Config  `yaml:"graphql"`
+	Health              health.Config   `yaml:"health"`
+	// AdminTokens are the bearer tokens of the webhook management
+	// requests, which are disabled when empty.
+	AdminTokens []string `yaml:"admin_tokens"`
+	// GRPCPort is the port of the gRPC server, which is disabled when
+	// zero.
+	GRPCPort int `yaml:"grpc_port"`
+}
+
+func openDB(ctx *cli.Context) (*gorm.DB, error) {
+	cfg := &Config{}
+	if err := config.Load(ctx.String(configPathFlag.Name), cfg); err != nil {
+		return nil, err
+	}
+
+	return database.New(cfg.Database)
 }
@@ -1,19 +1,28 @@
 package main
 
 import (
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"github.com/photon-storage/go-common/log"
 	pc "github.com/photon-storage/go-photon/config/config"
 
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/cmd/runtime/migrate"
 	"github.com/photon-storage/photon-explorer/cmd/runtime/version"
 	"github.com/photon-storage/photon-explorer/config"
-	"github.com/photon-storage/photon-explorer/database/mysql"
+	"github.com/photon-storage/photon-explorer/database"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/health"
 	"github.com/photon-storage/photon-explorer/indexer"
+	"github.com/photon-storage/photon-explorer/webhook"
 )
 
 var (
@@ -72,6 +81,13 @@ func main() {
 			logFilenameFlag,
 			logColorFlag,
 		},
+		Commands: []*cli.Command{
+			migrate.Command(openDB),
+			reindexCommand(),
+			verifyCommand(),
+			exportCommand(),
+			importCommand(),
+		},
 	}
 
 	app.Before = func(ctx *cli.Context) error {
@@ -119,18 +135,50 @@ func exec(ctx *cli.Context) error {
 		log.Fatal("fail on read config", "error", err)
 	}
 
-	db, err := mysql.NewMySQLDB(cfg.MySQL)
+	db, err := database.New(cfg.Database)
 	if err != nil {
-		log.Fatal("initialize mysql db error", "error", err)
+		log.Fatal("initialize database error", "error", err)
 	}
 
-	eventProcessor := indexer.NewEventProcessor(
+	if err := migration.Check(db); err != nil {
+		log.Fatal("database schema check failed, run migrate up with "+
+			"a matching binary", "error", err)
+	}
+
+	node, err := chain.NewMultiNodeClient(
 		ctx.Context,
-		cfg.RefreshInterval,
 		cfg.NodeGatewayProvider,
+		cfg.NodeClient,
+	)
+	if err != nil {
+		log.Fatal("initialize photon node client error", "error", err)
+	}
+
+	instrumented := chain.Instrument(node)
+	eventProcessor := indexer.NewEventProcessor(
+		ctx.Context,
+		cfg.Indexer,
+		instrumented,
 		db,
 	)
 
+	if cfg.MetricsPort != 0 {
+		go serveMetrics(cfg.MetricsPort)
+	}
+
+	if cfg.HealthPort != 0 {
+		checker := health.NewChecker(cfg.Health, db, instrumented)
+		go serveHealth(cfg.HealthPort, checker)
+	}
+
+	worker := webhook.NewWorker(ctx.Context, cfg.Webhook, db)
+	go worker.Run()
+
+	verifier := indexer.NewVerifier(ctx.Context, cfg.Verify, instrumented, db)
+	if cfg.Verify.Interval != 0 {
+		go verifier.Run()
+	}
+
 	go func() {
 		sigc := make(chan os.Signal, 1)
 		signal.Notify(sigc, syscall.SIGQUIT, syscall.SIGINT, syscall.SIGTERM)
@@ -139,6 +187,8 @@ func exec(ctx *cli.Context) error {
 		log.Info("Got interrupt, shutting down...")
 
 		go eventProcessor.Stop()
+		go worker.Stop()
+		go verifier.Stop()
 		for i := 10; i > 0; i-- {
 			<-sigc
 			if i > 1 {
@@ -157,7 +207,49 @@ func exec(ctx *cli.Context) error {
 
 // Config defines the config for indexer service.
 type Config struct {
-	MySQL               mysql.Config `yaml:"mysql"`
-	RefreshInterval     uint64       `yaml:"refresh_interval"`
-	NodeGatewayProvider string       `yaml:"node_gateway_provider"`
+	Database            database.Config `yaml:",inline"`
+	Indexer             indexer.Config  `yaml:",inline"`
+	NodeGatewayProvider chain.Endpoints `yaml:"node_gateway_provider"`
+	NodeClient          chain.Config    `yaml:"node_client"`
+	Webhook             webhook.Config  `yaml:"webhook"`
+	// Verify compares the index with the node in the background when an
+	// interval is set.
+	Verify indexer.VerifyConfig `yaml:"verify"`
+	// MetricsPort serves the Prometheus metrics at /metrics, 0 disables.
+	MetricsPort int `yaml:"metrics_port"`
+	// HealthPort serves the /healthz and /readyz probes, 0 disables.
+	HealthPort int           `yaml:"health_port"`
+	Health     health.Config `yaml:"health"`
+}
+
+func serveMetrics(port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	log.Info("Starting explorer metrics server...", "port", port)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), mux); err != nil {
+		log.Error("run the metrics server failed", "error", err)
+	}
+}
+
+// serveHealth answers /healthz with the stall check, so that a stuck
+// indexer gets restarted, and /readyz with the readiness check.
+func serveHealth(port int, checker *health.Checker) {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", health.Handler(checker.Live))
+	mux.Handle("/readyz", health.Handler(checker.Ready))
+
+	log.Info("Starting explorer health server...", "port", port)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), mux); err != nil {
+		log.Error("run the health server failed", "error", err)
+	}
+}
+
+func openDB(ctx *cli.Context) (*gorm.DB, error) {
+	cfg := &Config{}
+	if err := config.Load(ctx.String(configPathFlag.Name), cfg); err != nil {
+		return nil, err
+	}
+
+	return database.New(cfg.Database)
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/config"
+	"github.com/photon-storage/photon-explorer/database"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/indexer"
+)
+
+var (
+	// fromSlotFlag defines the first slot reindexed.
+	fromSlotFlag = &cli.Uint64Flag{
+		Name:     "from-slot",
+		Usage:    "First slot to reindex",
+		Required: true,
+	}
+
+	// toSlotFlag defines the last slot reindexed.
+	toSlotFlag = &cli.Uint64Flag{
+		Name:     "to-slot",
+		Usage:    "Last slot to reindex, included",
+		Required: true,
+	}
+
+	// dryRunFlag reports the changes of the reindex without applying
+	// them.
+	dryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Report what would change and roll back",
+	}
+)
+
+func reindexCommand() *cli.Command {
+	return &cli.Command{
+		Name: "reindex",
+		Usage: "Rebuild the indexed blocks of a slot range from the node " +
+			"and recompute the state of the accounts involved",
+		Flags:  []cli.Flag{fromSlotFlag, toSlotFlag, dryRunFlag},
+		Action: reindex,
+	}
+}
+
+func reindex(ctx *cli.Context) error {
+	cfg := &Config{}
+	if err := config.Load(ctx.String(configPathFlag.Name), cfg); err != nil {
+		return err
+	}
+
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	if err := migration.Check(db); err != nil {
+		return err
+	}
+
+	node, err := chain.NewMultiNodeClient(
+		ctx.Context,
+		cfg.NodeGatewayProvider,
+		cfg.NodeClient,
+	)
+	if err != nil {
+		return err
+	}
+
+	report, err := indexer.Reindex(
+		ctx.Context,
+		node,
+		db,
+		ctx.Uint64(fromSlotFlag.Name),
+		ctx.Uint64(toSlotFlag.Name),
+		ctx.Bool(dryRunFlag.Name),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range report.Changes {
+		fmt.Printf("%-10d %-8s %-22s %s %s\n",
+			c.Slot, c.Action, c.Entity, c.Key, c.Detail)
+	}
+	for _, c := range report.State {
+		fmt.Printf("%-10s %-8s %-22s %s %s\n",
+			"state", c.Action, c.Entity, c.Key, c.Detail)
+	}
+
+	verb := "reindexed"
+	if report.DryRun {
+		verb = "dry run, rolled back"
+	}
+	fmt.Printf("%s slots %d to %d: %d row changes, %d state changes\n",
+		verb,
+		report.FromSlot,
+		report.ToSlot,
+		len(report.Changes),
+		len(report.State),
+	)
+
+	return nil
+}
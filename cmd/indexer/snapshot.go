@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/photon-storage/photon-explorer/config"
+	"github.com/photon-storage/photon-explorer/database"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/indexer"
+	"github.com/photon-storage/photon-explorer/snapshot"
+)
+
+// snapshotFileFlag defines the snapshot file path.
+var snapshotFileFlag = &cli.StringFlag{
+	Name:     "file",
+	Usage:    "Path of the snapshot file",
+	Required: true,
+}
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name: "export",
+		Usage: "Write a snapshot of the index at the finalized slot to " +
+			"bootstrap a replica",
+		Flags:  []cli.Flag{snapshotFileFlag},
+		Action: exportSnapshot,
+	}
+}
+
+func importCommand() *cli.Command {
+	return &cli.Command{
+		Name: "import",
+		Usage: "Load a snapshot into an empty database, the indexer " +
+			"resumes from its chain status",
+		Flags:  []cli.Flag{snapshotFileFlag},
+		Action: importSnapshot,
+	}
+}
+
+func exportSnapshot(ctx *cli.Context) error {
+	cfg := &Config{}
+	if err := config.Load(ctx.String(configPathFlag.Name), cfg); err != nil {
+		return err
+	}
+
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	if err := migration.Check(db); err != nil {
+		return err
+	}
+
+	path := ctx.String(snapshotFileFlag.Name)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	m, err := indexer.ExportSnapshot(db, f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	printManifest("exported", m)
+	return nil
+}
+
+func importSnapshot(ctx *cli.Context) error {
+	cfg := &Config{}
+	if err := config.Load(ctx.String(configPathFlag.Name), cfg); err != nil {
+		return err
+	}
+
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	if err := migration.Check(db); err != nil {
+		return err
+	}
+
+	f, err := os.Open(ctx.String(snapshotFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m, err := snapshot.Import(db, f)
+	if err != nil {
+		return err
+	}
+
+	printManifest("imported", m)
+	return nil
+}
+
+func printManifest(verb string, m *snapshot.Manifest) {
+	for _, t := range m.Tables {
+		fmt.Printf("%-26s %10d rows sha256 %s\n", t.Name, t.Rows, t.SHA256)
+	}
+	fmt.Printf("%s snapshot of schema %d at finalized slot %d, next slot %d "+
+		"after %s\n",
+		verb,
+		m.SchemaVersion,
+		m.FinalizedSlot,
+		m.NextSlot,
+		m.CurrentHash,
+	)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/config"
+	"github.com/photon-storage/photon-explorer/database"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/indexer"
+)
+
+var (
+	// sampleSizeFlag defines the number of rows of every table verified.
+	sampleSizeFlag = &cli.IntFlag{
+		Name:  "sample-size",
+		Usage: "Rows of every table to verify, 0 verifies every row",
+	}
+
+	// repairFlag overwrites the mismatching rows with the node state.
+	repairFlag = &cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Overwrite the mismatching rows with the node state",
+	}
+)
+
+func verifyCommand() *cli.Command {
+	return &cli.Command{
+		Name: "verify",
+		Usage: "Compare the indexed accounts, validators, auditors and " +
+			"storage contracts with the node",
+		Flags:  []cli.Flag{sampleSizeFlag, repairFlag},
+		Action: verify,
+	}
+}
+
+func verify(ctx *cli.Context) error {
+	cfg := &Config{}
+	if err := config.Load(ctx.String(configPathFlag.Name), cfg); err != nil {
+		return err
+	}
+
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	if err := migration.Check(db); err != nil {
+		return err
+	}
+
+	node, err := chain.NewMultiNodeClient(
+		ctx.Context,
+		cfg.NodeGatewayProvider,
+		cfg.NodeClient,
+	)
+	if err != nil {
+		return err
+	}
+
+	report, err := indexer.NewVerifier(
+		ctx.Context,
+		indexer.VerifyConfig{
+			SampleSize: ctx.Int(sampleSizeFlag.Name),
+			Repair:     ctx.Bool(repairFlag.Name),
+		},
+		node,
+		db,
+	).Verify()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range report.Mismatches {
+		fmt.Printf("%-10d %-18s %s %s: indexed %s, node %s\n",
+			m.Slot, m.Entity, m.Key, m.Field, m.Indexed, m.Node)
+	}
+
+	verb := "found"
+	if ctx.Bool(repairFlag.Name) {
+		verb = "repaired"
+	}
+	fmt.Printf("verified %d accounts, %d validators, %d auditors, "+
+		"%d storage contracts: %s %d mismatches\n",
+		report.Checked["accounts"],
+		report.Checked["validators"],
+		report.Checked["auditors"],
+		report.Checked["storage_contracts"],
+		verb,
+		len(report.Mismatches),
+	)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package migrate provides the migrate subcommand shared by the
+// explorer binaries.
+package migrate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/database/migration"
+)
+
+// stepsFlag defines the number of migrations reverted by migrate down.
+var stepsFlag = &cli.IntFlag{
+	Name:  "steps",
+	Usage: "Number of migrations to revert",
+	Value: 1,
+}
+
+// Command returns the migrate command. openDB opens the database of the
+// binary from its config.
+func Command(openDB func(ctx *cli.Context) (*gorm.DB, error)) *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "Manage the database schema migrations",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "up",
+				Usage: "Apply all pending migrations",
+				Action: func(ctx *cli.Context) error {
+					db, err := openDB(ctx)
+					if err != nil {
+						return err
+					}
+
+					return migration.Up(db)
+				},
+			},
+			{
+				Name:  "down",
+				Usage: "Revert the most recently applied migrations",
+				Flags: []cli.Flag{stepsFlag},
+				Action: func(ctx *cli.Context) error {
+					db, err := openDB(ctx)
+					if err != nil {
+						return err
+					}
+
+					return migration.Down(db, ctx.Int(stepsFlag.Name))
+				},
+			},
+			{
+				Name: "baseline",
+				Usage: "Mark the initial migration applied on a database " +
+					"created from the legacy init.sql",
+				Action: func(ctx *cli.Context) error {
+					db, err := openDB(ctx)
+					if err != nil {
+						return err
+					}
+
+					return migration.Baseline(db)
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "Print the status of every migration",
+				Action: func(ctx *cli.Context) error {
+					db, err := openDB(ctx)
+					if err != nil {
+						return err
+					}
+
+					ss, err := migration.List(db)
+					if err != nil {
+						return err
+					}
+
+					for _, s := range ss {
+						applied := "pending"
+						if s.Applied {
+							applied = "applied at " +
+								s.AppliedAt.Format(time.RFC3339)
+						}
+						fmt.Printf(
+							"%04d %-32s %s\n",
+							s.Version,
+							s.Name,
+							applied,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	}
+}
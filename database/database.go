@@ -0,0 +1,44 @@
+// Package database opens the explorer database with the driver selected
+// by config.
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/database/mysql"
+	"github.com/photon-storage/photon-explorer/database/postgres"
+	"github.com/photon-storage/photon-explorer/database/sqlite"
+)
+
+// Supported database drivers.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Config defines the database configuration. Only the section of the
+// selected driver is used.
+type Config struct {
+	// Driver is one of mysql, postgres and sqlite, defaults to mysql.
+	Driver   string          `yaml:"driver"`
+	MySQL    mysql.Config    `yaml:"mysql"`
+	Postgres postgres.Config `yaml:"postgres"`
+	SQLite   sqlite.Config   `yaml:"sqlite"`
+}
+
+// New opens the database of the configured driver.
+func New(cfg Config) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case "", DriverMySQL:
+		return mysql.NewMySQLDB(cfg.MySQL)
+	case DriverPostgres:
+		return postgres.NewPostgresDB(cfg.Postgres)
+	case DriverSQLite:
+		return sqlite.NewSQLiteDB(cfg.SQLite)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,18 @@
+// Package gormlog maps the log_level database config to gorm log levels.
+package gormlog
+
+import "gorm.io/gorm/logger"
+
+// Level parses the log level name, defaulting to info.
+func Level(logStr string) logger.LogLevel {
+	switch logStr {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "warn":
+		return logger.Warn
+	default:
+		return logger.Info
+	}
+}
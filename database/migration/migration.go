@@ -0,0 +1,371 @@
+// Package migration applies the versioned schema migrations embedded in
+// the binary. Migrations live in migrations/<dialect> as
+// <version>_<name>.up.sql and <version>_<name>.down.sql pairs, one
+// directory per database driver, and the applied versions are tracked
+// in the schema_migrations table.
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+)
+
+//go:embed migrations/*/*.sql
+var files embed.FS
+
+var (
+	// ErrSchemaOutdated is returned by Check when migrations known to the
+	// binary have not been applied.
+	ErrSchemaOutdated = errors.New("database schema is outdated")
+	// ErrSchemaUnknown is returned by Check when the database has a
+	// migration applied that the binary does not know.
+	ErrSchemaUnknown = errors.New("database schema version is unknown")
+	// ErrNoLegacySchema is returned by Baseline when the database was not
+	// created from the former database/sqldump/init.sql.
+	ErrNoLegacySchema = errors.New("database schema is not the legacy one")
+)
+
+var (
+	fileRegexp  = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	tableRegexp = regexp.MustCompile("(?i)^create table [`\"]?(\\w+)")
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version uint64
+	Name    string
+	up      string
+	down    string
+}
+
+// SchemaMigration is a gorm table definition represents the
+// schema_migrations, one row per applied migration.
+type SchemaMigration struct {
+	Version   uint64 `gorm:"primary_key;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// Status is the state of a migration in a database.
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrations returns the embedded migrations of the gorm dialect, e.g.
+// mysql, ordered by version.
+func Migrations(dialect string) ([]*Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := files.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "no migrations for dialect %s", dialect)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, e := range entries {
+		m := fileRegexp.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := files.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mg
+		} else if mg.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names", version)
+		}
+
+		if m[3] == "up" {
+			mg.up = string(content)
+		} else {
+			mg.down = string(content)
+		}
+	}
+
+	ms := make([]*Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.up == "" || mg.down == "" {
+			return nil, fmt.Errorf(
+				"migration %d misses its up or down file",
+				mg.Version,
+			)
+		}
+		ms = append(ms, mg)
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Version < ms[j].Version
+	})
+
+	return ms, nil
+}
+
+// Up applies every pending migration in order.
+func Up(db *gorm.DB) error {
+	ms, applied, err := load(db)
+	if err != nil {
+		return err
+	}
+
+	if err := checkLegacy(db, ms, applied); err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		log.Info("Applying migration", "version", m.Version, "name", m.Name)
+		if err := run(db, m.up, func(tx *gorm.DB) error {
+			return tx.Create(&SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		}); err != nil {
+			return errors.Wrapf(err, "apply migration %d", m.Version)
+		}
+	}
+
+	return nil
+}
+
+// Down reverts the given number of most recently applied migrations.
+func Down(db *gorm.DB, steps int) error {
+	ms, applied, err := load(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(ms) - 1; i >= 0 && steps > 0; i-- {
+		m := ms[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		log.Info("Reverting migration", "version", m.Version, "name", m.Name)
+		if err := run(db, m.down, func(tx *gorm.DB) error {
+			return tx.Where("version = ?", m.Version).
+				Delete(&SchemaMigration{}).
+				Error
+		}); err != nil {
+			return errors.Wrapf(err, "revert migration %d", m.Version)
+		}
+		steps--
+	}
+
+	return nil
+}
+
+// List returns the status of every embedded migration.
+func List(db *gorm.DB) ([]*Status, error) {
+	ms, applied, err := load(db)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := make([]*Status, len(ms))
+	for i, m := range ms {
+		ss[i] = &Status{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			ss[i].Applied = true
+			ss[i].AppliedAt = a.AppliedAt
+		}
+	}
+
+	return ss, nil
+}
+
+// Check returns an error unless the database has exactly the embedded
+// migrations applied.
+func Check(db *gorm.DB) error {
+	ms, applied, err := load(db)
+	if err != nil {
+		return err
+	}
+
+	if err := checkLegacy(db, ms, applied); err != nil {
+		return err
+	}
+
+	known := make(map[uint64]bool)
+	for _, m := range ms {
+		known[m.Version] = true
+		if _, ok := applied[m.Version]; !ok {
+			return errors.Wrapf(
+				ErrSchemaOutdated,
+				"migration %d %s not applied",
+				m.Version,
+				m.Name,
+			)
+		}
+	}
+
+	for v := range applied {
+		if !known[v] {
+			return errors.Wrapf(ErrSchemaUnknown, "version %d", v)
+		}
+	}
+
+	return nil
+}
+
+// Baseline records the initial migration as applied on a database
+// created from the former database/sqldump/init.sql, which holds its
+// schema without tracking it. The later migrations are left to Up.
+func Baseline(db *gorm.DB) error {
+	ms, applied, err := load(db)
+	if err != nil {
+		return err
+	}
+
+	if len(applied) > 0 {
+		return errors.Wrap(ErrNoLegacySchema, "migrations already applied")
+	}
+
+	m := ms[0]
+	if missing := missingTables(db, m); len(missing) > 0 {
+		return errors.Wrapf(
+			ErrNoLegacySchema,
+			"tables %s missing",
+			strings.Join(missing, ", "),
+		)
+	}
+
+	log.Info("Baselining migration", "version", m.Version, "name", m.Name)
+	return db.Create(&SchemaMigration{
+		Version:   m.Version,
+		Name:      m.Name,
+		AppliedAt: time.Now(),
+	}).Error
+}
+
+// checkLegacy returns an error pointing to Baseline when the database
+// holds the legacy schema without any migration recorded, which Up
+// would otherwise try to create again.
+func checkLegacy(
+	db *gorm.DB,
+	ms []*Migration,
+	applied map[uint64]*SchemaMigration,
+) error {
+	if len(applied) > 0 || len(missingTables(db, ms[0])) > 0 {
+		return nil
+	}
+
+	return errors.Wrap(
+		ErrSchemaOutdated,
+		"legacy schema without migrations, run migrate baseline first",
+	)
+}
+
+// missingTables returns the tables created by the migration that the
+// database doesn't have.
+func missingTables(db *gorm.DB, m *Migration) []string {
+	var missing []string
+	for _, stmt := range statements(m.up) {
+		sm := tableRegexp.FindStringSubmatch(stmt)
+		if sm != nil && !db.Migrator().HasTable(sm[1]) {
+			missing = append(missing, sm[1])
+		}
+	}
+
+	return missing
+}
+
+func load(db *gorm.DB) ([]*Migration, map[uint64]*SchemaMigration, error) {
+	ms, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]*SchemaMigration, 0)
+	if err := db.Model(&SchemaMigration{}).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	applied := make(map[uint64]*SchemaMigration)
+	for _, r := range rows {
+		applied[r.Version] = r
+	}
+
+	return ms, applied, nil
+}
+
+// run executes the statements of a migration script followed by record.
+// PostgreSQL and SQLite have transactional DDL, so a failed migration is
+// rolled back as a whole there. DDL statements commit implicitly in
+// MySQL, so the script only runs on a single connection and a failed
+// migration may be left partially applied.
+func run(db *gorm.DB, script string, record func(tx *gorm.DB) error) error {
+	fn := func(tx *gorm.DB) error {
+		for _, stmt := range statements(script) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+
+		return record(tx)
+	}
+
+	if db.Dialector.Name() == "mysql" {
+		return db.Connection(fn)
+	}
+
+	return db.Transaction(fn)
+}
+
+// statements splits a script into statements terminated by a semicolon
+// at the end of a line, dropping comment lines.
+func statements(script string) []string {
+	var (
+		stmts []string
+		cur   strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		cur.WriteString(line)
+		cur.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		}
+	}
+
+	if s := strings.TrimSpace(cur.String()); s != "" {
+		stmts = append(stmts, s)
+	}
+
+	return stmts
+}
@@ -0,0 +1,473 @@
+package migration
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/database/sqlite"
+)
+
+func TestMigrations(t *testing.T) {
+	var versions []uint64
+	for _, dialect := range []string{"mysql", "postgres", "sqlite"} {
+		ms, err := Migrations(dialect)
+		if err != nil {
+			t.Fatalf("loading %s migrations: %v", dialect, err)
+		}
+
+		if len(ms) == 0 {
+			t.Fatalf("no %s migration embedded", dialect)
+		}
+
+		var vs []uint64
+		for i, m := range ms {
+			if i > 0 && m.Version <= ms[i-1].Version {
+				t.Errorf("%s migration %d is out of order", dialect, m.Version)
+			}
+
+			if len(statements(m.up)) == 0 || len(statements(m.down)) == 0 {
+				t.Errorf("%s migration %d has an empty script", dialect, m.Version)
+			}
+			vs = append(vs, m.Version)
+		}
+
+		// Every dialect must provide the same migrations.
+		if versions == nil {
+			versions = vs
+		} else if !reflect.DeepEqual(vs, versions) {
+			t.Errorf("%s migrations %v, want %v", dialect, vs, versions)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (
+  id int
+);
+
+-- another
+DROP TABLE b;
+SELECT 1`
+
+	want := []string{
+		"CREATE TABLE a (\n  id int\n);",
+		"DROP TABLE b;",
+		"SELECT 1",
+	}
+	if got := statements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements() = %q, want %q", got, want)
+	}
+}
+
+func TestEventsSurviveRebuild(t *testing.T) {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []string{orm.EventBlock, orm.EventMissedAttestation}
+	for _, kind := range kinds {
+		if err := db.Create(&orm.Event{
+			Kind:    kind,
+			Payload: []byte("{}"),
+		}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Down to before 0006, which rebuilds the events table on SQLite.
+	if err := Down(db, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := make([]string, 0)
+	if err := db.Model(&orm.Event{}).
+		Order("id asc").
+		Pluck("kind", &stored).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored, kinds) {
+		t.Errorf("stored kinds %v, want %v", stored, kinds)
+	}
+}
+
+func TestRunRollsBack(t *testing.T) {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script := `CREATE TABLE a (id int);
+INSERT INTO missing VALUES (1);`
+	if err := run(db, script, func(tx *gorm.DB) error {
+		t.Fatal("record called after a failed statement")
+		return nil
+	}); err == nil {
+		t.Fatal("run() succeeded, want the failed statement error")
+	}
+
+	// The statements applied before the failure are rolled back too.
+	if db.Migrator().HasTable("a") {
+		t.Fatal("table a left behind by the failed migration")
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Baseline(db); !errors.Is(err, ErrNoLegacySchema) {
+		t.Fatalf("Baseline() on an empty database = %v, want %v",
+			err, ErrNoLegacySchema)
+	}
+
+	// A database created from the legacy init.sql, the schema of the
+	// initial migration without its schema_migrations row.
+	ms, err := Migrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range statements(ms[0].up) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, fn := range map[string]func(*gorm.DB) error{
+		"Check": Check,
+		"Up":    Up,
+	} {
+		if err := fn(db); !errors.Is(err, ErrSchemaOutdated) {
+			t.Errorf("%s() on the legacy schema = %v, want %v",
+				name, err, ErrSchemaOutdated)
+		}
+	}
+
+	if err := Baseline(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Baseline(db); !errors.Is(err, ErrNoLegacySchema) {
+		t.Errorf("Baseline() on a migrated database = %v, want %v",
+			err, ErrNoLegacySchema)
+	}
+}
+
+func TestTransactionBackfill(t *testing.T) {
+	db, ids := legacyTransactions(t, []*gateway.Tx{
+		transferTx("transfer", "alice", "bob", 10),
+		transferTx("self transfer", "alice", "alice", 5),
+		commitTx("commit", "alice", "carol", 7),
+		auditTx("audit", "bob", "dave"),
+		depositTx("deposit", "carol", 3),
+	})
+
+	// The self transfer and the audit have no counterparty, the depot
+	// of the audit has no account.
+	want := map[string]orm.Transaction{
+		"transfer":      {ToAccountID: ids["bob"], Amount: 10},
+		"self transfer": {Amount: 5},
+		"commit":        {ToAccountID: ids["carol"], Amount: 7},
+		"audit":         {},
+		"deposit":       {Amount: 3},
+	}
+	var stored []*orm.Transaction
+	if err := db.Find(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range stored {
+		w := want[tx.Hash]
+		if tx.ToAccountID != w.ToAccountID || tx.Amount != w.Amount {
+			t.Errorf("%s: counterparty %d amount %d, want %d %d", tx.Hash,
+				tx.ToAccountID, tx.Amount, w.ToAccountID, w.Amount)
+		}
+	}
+}
+
+func TestParticipantBackfill(t *testing.T) {
+	db, _ := legacyTransactions(t, []*gateway.Tx{
+		transferTx("transfer", "alice", "bob", 10),
+		transferTx("self transfer", "alice", "alice", 5),
+		auditTx("audit", "carol", "bob"),
+	})
+
+	var got []string
+	if err := db.Table("transaction_participants AS tp").
+		Select("t.hash || ' ' || a.public_key || ' ' || tp.role").
+		Joins("JOIN transactions AS t ON t.id = tp.transaction_id").
+		Joins("JOIN accounts AS a ON a.id = tp.account_id").
+		Order("tp.transaction_id, tp.role").
+		Scan(&got).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"transfer bob recipient",
+		"transfer alice sender",
+		"self transfer alice recipient",
+		"self transfer alice sender",
+		"audit carol auditor",
+		"audit carol sender",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("participants %q, want %q", got, want)
+	}
+}
+
+// legacyTransactions returns a database holding the transactions as
+// indexed by the initial schema and migrated up, with the ids of the
+// accounts alice, bob and carol.
+func legacyTransactions(
+	t *testing.T,
+	txs []*gateway.Tx,
+) (*gorm.DB, map[string]uint64) {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ms, err := Migrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := Down(db, len(ms)-1); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make(map[string]uint64)
+	for _, pk := range []string{"alice", "bob", "carol"} {
+		if err := db.Exec("INSERT INTO accounts (public_key, nonce, balance) "+
+			"VALUES (?, 0, 0)", pk).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		var id uint64
+		if err := db.Raw("SELECT id FROM accounts WHERE public_key = ?", pk).
+			Scan(&id).
+			Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[pk] = id
+	}
+
+	for i, tx := range txs {
+		raw, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := db.Exec("INSERT INTO transactions "+
+			"(block_id, hash, from_account_id, position, type, gas_price, raw) "+
+			"VALUES (1, ?, ?, ?, ?, 1, ?)",
+			tx.TxHash, ids[tx.From], i, pbc.TxType_value[tx.Type], raw,
+		).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db, ids
+}
+
+// alloc sets the pointer to a new value and returns it, which fills the
+// typed payloads of a gateway transaction.
+func alloc[T any](p **T) *T {
+	*p = new(T)
+	return *p
+}
+
+func transferTx(hash string, from string, to string, amount uint64) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash: hash,
+		Type:   pbc.TxType_BALANCE_TRANSFER.String(),
+		From:   from,
+	}
+	bt := alloc(&tx.BalanceTransfer)
+	bt.To = to
+	bt.Amount = amount
+
+	return tx
+}
+
+func commitTx(hash string, owner string, depot string, fee uint64) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash: hash,
+		Type:   pbc.TxType_OBJECT_COMMIT.String(),
+		From:   owner,
+	}
+	oc := alloc(&tx.ObjectCommit)
+	oc.Owner = owner
+	oc.Depot = depot
+	oc.Fee = fee
+
+	return tx
+}
+
+func auditTx(hash string, auditor string, depot string) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash: hash,
+		Type:   pbc.TxType_OBJECT_AUDIT.String(),
+		From:   auditor,
+	}
+	oa := alloc(&tx.ObjectAudit)
+	oa.Auditor = auditor
+	oa.Depot = depot
+
+	return tx
+}
+
+func depositTx(hash string, from string, amount uint64) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash: hash,
+		Type:   pbc.TxType_VALIDATOR_DEPOSIT.String(),
+		From:   from,
+	}
+	alloc(&tx.ValidatorDeposit).Amount = amount
+
+	return tx
+}
+
+func TestSchemaMatchesORM(t *testing.T) {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	models := []interface{}{
+		&orm.Account{},
+		&orm.AccountLedger{},
+		&orm.Attestation{},
+		&orm.Auditor{},
+		&orm.Block{},
+		&orm.ChainStatus{},
+		&orm.Event{},
+		&orm.Reorg{},
+		&orm.StateJournal{},
+		&orm.StorageContract{},
+		&orm.StorageContractStatus{},
+		&orm.StorageProof{},
+		&orm.Transaction{},
+		&orm.TransactionContract{},
+		&orm.TransactionParticipant{},
+		&orm.Validator{},
+		&orm.ValidatorParticipation{},
+		&orm.Webhook{},
+		&orm.WebhookDelivery{},
+		&orm.WebhookCursor{},
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		table := stmt.Schema.Table
+
+		cts, err := db.Migrator().ColumnTypes(model)
+		if err != nil {
+			t.Fatalf("%s: %v", table, err)
+		}
+
+		columns := make(map[string]gorm.ColumnType)
+		for _, ct := range cts {
+			columns[ct.Name()] = ct
+		}
+
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" {
+				continue
+			}
+
+			ct, ok := columns[f.DBName]
+			if !ok {
+				t.Errorf("%s.%s of %s has no column", table, f.DBName, f.Name)
+				continue
+			}
+			delete(columns, f.DBName)
+
+			// A nil pointer is stored as null. A null read into a value
+			// field is its zero value, so nullable columns may map to one.
+			if nullable, _ := ct.Nullable(); !nullable &&
+				f.FieldType.Kind() == reflect.Ptr {
+				t.Errorf("%s.%s is NOT NULL, %s is a pointer",
+					table, f.DBName, f.Name)
+			}
+
+			if !matchesClass(ct.DatabaseTypeName(), f.FieldType) {
+				t.Errorf("%s.%s is %s, %s is a %s",
+					table, f.DBName, ct.DatabaseTypeName(), f.Name, f.FieldType)
+			}
+		}
+
+		for name := range columns {
+			t.Errorf("%s.%s has no field", table, name)
+		}
+	}
+}
+
+// matchesClass reports whether the SQLite column type stores values of
+// type t.
+func matchesClass(columnType string, t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var classes []string
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		classes = []string{"timestamp", "datetime"}
+	case t.Kind() == reflect.String:
+		classes = []string{"char", "text"}
+	case t.Kind() == reflect.Bool:
+		classes = []string{"bool"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		classes = []string{"int"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		classes = []string{"blob"}
+	default:
+		return true
+	}
+
+	for _, c := range classes {
+		if strings.Contains(strings.ToLower(columnType), c) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,33 +1,5 @@
-/*
-The database initialization process is as follows:
-  1. CREATE SCHEMA `photon_explorer` DEFAULT CHARACTER SET utf8 ;
-  2. USE photon_explorer;
-  3. copy the table building scripts and execute it in the sql client;
-*/;
--- MySQL dump 10.13  Distrib 8.0.28, for macos11 (x86_64)
---
--- Host: localhost    Database: photon_explorer
--- ------------------------------------------------------
--- Server version	5.7.36-log
+-- Initial schema of the explorer.
 
-/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
-/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;
-/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;
-/*!50503 SET NAMES utf8 */;
-/*!40103 SET @OLD_TIME_ZONE=@@TIME_ZONE */;
-/*!40103 SET TIME_ZONE='+00:00' */;
-/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;
-/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;
-/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;
-/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;
-
---
--- Table structure for table `accounts`
---
-
-DROP TABLE IF EXISTS `accounts`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
 CREATE TABLE `accounts` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `public_key` char(192) NOT NULL,
@@ -38,15 +10,41 @@ CREATE TABLE `accounts` (
   PRIMARY KEY (`id`),
   UNIQUE KEY `address_UNIQUE` (`public_key`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
 
---
--- Table structure for table `attestations`
---
+CREATE TABLE `account_ledgers` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `account_id` int(11) NOT NULL,
+  `block_id` int(11) DEFAULT NULL,
+  `kind` varchar(32) NOT NULL,
+  `slot` bigint(20) NOT NULL,
+  `tx_hash` varchar(128) NOT NULL DEFAULT '',
+  `delta` bigint(20) NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `account_slot` (`account_id`,`slot`),
+  KEY `block_id` (`block_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE `blocks` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `slot` bigint(11) NOT NULL,
+  `hash` char(64) NOT NULL,
+  `parent_hash` char(64) NOT NULL,
+  `state_hash` char(64) NOT NULL,
+  `proposal_index` int(11) NOT NULL,
+  `proposal_signature` char(192) NOT NULL,
+  `randao_reveal` char(192) NOT NULL,
+  `graffiti` char(64) NOT NULL,
+  `timestamp` int(11) NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `sd_UNIQUE` (`slot`,`deleted_at`),
+  KEY `deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
-DROP TABLE IF EXISTS `attestations`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
 CREATE TABLE `attestations` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `block_id` int(11) NOT NULL,
@@ -65,15 +63,7 @@ CREATE TABLE `attestations` (
   KEY `deleted_at` (`deleted_at`),
   CONSTRAINT `at_ibfk_1` FOREIGN KEY (`block_id`) REFERENCES `blocks` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
-
---
--- Table structure for table `auditors`
---
 
-DROP TABLE IF EXISTS `auditors`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
 CREATE TABLE `auditors` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `account_id` int(11) NOT NULL,
@@ -87,61 +77,64 @@ CREATE TABLE `auditors` (
   KEY `auditor_ibfk_1_idx` (`account_id`),
   CONSTRAINT `auditor_ibfk_1` FOREIGN KEY (`account_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
 
---
--- Table structure for table `blocks`
---
+CREATE TABLE `chain_status` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `next_slot` bigint(11) NOT NULL,
+  `current_hash` char(64) NOT NULL,
+  `finalized_slot` bigint(11) DEFAULT NULL,
+  `finalized_hash` char(64) DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
-DROP TABLE IF EXISTS `blocks`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
-CREATE TABLE `blocks` (
+CREATE TABLE `reorgs` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
-  `slot` bigint(11) NOT NULL,
-  `hash` char(64) NOT NULL,
-  `parent_hash` char(64) NOT NULL,
-  `state_hash` char(64) NOT NULL,
-  `proposal_index` int(11) NOT NULL,
-  `proposal_signature` char(192) NOT NULL,
-  `randao_reveal` char(192) NOT NULL,
-  `graffiti` char(64) NOT NULL,
-  `timestamp` int(11) NOT NULL,
+  `depth` int(11) NOT NULL,
+  `old_head_slot` bigint(20) NOT NULL,
+  `old_head_hash` char(64) NOT NULL,
+  `new_head_slot` bigint(20) NOT NULL,
+  `new_head_hash` char(64) NOT NULL,
+  `ancestor_slot` bigint(20) NOT NULL,
+  `ancestor_hash` char(64) NOT NULL,
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  `deleted_at` timestamp NULL DEFAULT NULL,
   PRIMARY KEY (`id`),
-  UNIQUE KEY `sd_UNIQUE` (`slot`,`deleted_at`),
-  KEY `deleted_at` (`deleted_at`)
+  KEY `new_head_slot` (`new_head_slot`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
 
---
--- Table structure for table `chain_status`
---
+CREATE TABLE `state_journals` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `block_id` int(11) NOT NULL,
+  `entity` varchar(32) NOT NULL,
+  `entity_id` int(11) NOT NULL,
+  `created` tinyint(1) NOT NULL DEFAULT '0',
+  `pre_state` text,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `sj_ibfk_1_idx` (`block_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
-DROP TABLE IF EXISTS `chain_status`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
-CREATE TABLE `chain_status` (
+CREATE TABLE `transactions` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
-  `next_slot` bigint(11) NOT NULL,
-  `current_hash` char(64) NOT NULL,
-  `finalized_slot` bigint(11) DEFAULT NULL,
-  `finalized_hash` char(64) DEFAULT NULL,
+  `block_id` int(11) NOT NULL,
+  `hash` char(64) NOT NULL,
+  `from_account_id` int(11) NOT NULL,
+  `position` int(11) NOT NULL,
+  `type` tinyint(1) NOT NULL,
+  `gas_price` int(11) NOT NULL,
+  `raw` text NOT NULL,
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  PRIMARY KEY (`id`)
+  `deleted_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `hash_UNIQUE` (`hash`),
+  KEY `tx_ibfk_1_idx` (`block_id`),
+  KEY `deleted_at` (`deleted_at`),
+  CONSTRAINT `tx_ibfk_1` FOREIGN KEY (`from_account_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
-
---
--- Table structure for table `storage_contracts`
---
 
-DROP TABLE IF EXISTS `storage_contracts`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
 CREATE TABLE `storage_contracts` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `commit_transaction_id` int(11) NOT NULL,
@@ -169,15 +162,40 @@ CREATE TABLE `storage_contracts` (
   CONSTRAINT `sc_ibfk_3` FOREIGN KEY (`depot_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION,
   CONSTRAINT `sc_ibfk_4` FOREIGN KEY (`auditor_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
 
---
--- Table structure for table `transaction_contracts`
---
+CREATE TABLE `storage_contract_statuses` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `contract_id` int(11) NOT NULL,
+  `prev_status` int(11) NOT NULL,
+  `status` int(11) NOT NULL,
+  `slot` bigint(20) NOT NULL,
+  `transaction_id` int(11) DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `scs_ibfk_1_idx` (`contract_id`),
+  KEY `scs_ibfk_2_idx` (`transaction_id`),
+  CONSTRAINT `scs_ibfk_1` FOREIGN KEY (`contract_id`) REFERENCES `storage_contracts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `scs_ibfk_2` FOREIGN KEY (`transaction_id`) REFERENCES `transactions` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE `storage_proofs` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `contract_id` int(11) NOT NULL,
+  `transaction_id` int(11) NOT NULL,
+  `depot_id` int(11) NOT NULL,
+  `slot` bigint(20) NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `sp_ibfk_1_idx` (`contract_id`),
+  KEY `sp_ibfk_2_idx` (`transaction_id`),
+  KEY `deleted_at` (`deleted_at`),
+  CONSTRAINT `sp_ibfk_1` FOREIGN KEY (`contract_id`) REFERENCES `storage_contracts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `sp_ibfk_2` FOREIGN KEY (`transaction_id`) REFERENCES `transactions` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
-DROP TABLE IF EXISTS `transaction_contracts`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
 CREATE TABLE `transaction_contracts` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `transaction_id` int(11) NOT NULL,
@@ -192,42 +210,20 @@ CREATE TABLE `transaction_contracts` (
   CONSTRAINT `tc_ibfk_1` FOREIGN KEY (`transaction_id`) REFERENCES `transactions` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION,
   CONSTRAINT `tc_ibfk_2` FOREIGN KEY (`contract_id`) REFERENCES `storage_contracts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
-
---
--- Table structure for table `transactions`
---
 
-DROP TABLE IF EXISTS `transactions`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
-CREATE TABLE `transactions` (
+CREATE TABLE `validator_participations` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
-  `block_id` int(11) NOT NULL,
-  `hash` char(64) NOT NULL,
-  `from_account_id` int(11) NOT NULL,
-  `position` int(11) NOT NULL,
-  `type` tinyint(1) NOT NULL,
-  `gas_price` int(11) NOT NULL,
-  `raw` text NOT NULL,
+  `validator_idx` int(11) NOT NULL,
+  `epoch` bigint(20) NOT NULL,
+  `attestation_slot` bigint(20) NOT NULL,
+  `attested` tinyint(1) NOT NULL DEFAULT '0',
+  `inclusion_slot` bigint(20) NOT NULL DEFAULT '0',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  `deleted_at` timestamp NULL DEFAULT NULL,
   PRIMARY KEY (`id`),
-  UNIQUE KEY `hash_UNIQUE` (`hash`),
-  KEY `tx_ibfk_1_idx` (`block_id`),
-  KEY `deleted_at` (`deleted_at`),
-  CONSTRAINT `tx_ibfk_1` FOREIGN KEY (`from_account_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
+  UNIQUE KEY `ve_UNIQUE` (`validator_idx`,`epoch`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
 
---
--- Table structure for table `validators`
---
-
-DROP TABLE IF EXISTS `validators`;
-/*!40101 SET @saved_cs_client     = @@character_set_client */;
-/*!50503 SET character_set_client = utf8mb4 */;
 CREATE TABLE `validators` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `account_id` int(11) NOT NULL,
@@ -244,15 +240,3 @@ CREATE TABLE `validators` (
   KEY `validator_ibfk_2_idx` (`attest_block_id`),
   CONSTRAINT `validator_ibfk_1` FOREIGN KEY (`account_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
-/*!40101 SET character_set_client = @saved_cs_client */;
-/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;
-
-/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;
-/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;
-/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;
-/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;
-/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;
-/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;
-/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;
-
--- Dump completed on 2022-09-15 21:40:41
\ No newline at end of file
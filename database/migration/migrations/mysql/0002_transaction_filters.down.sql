@@ -0,0 +1,8 @@
+ALTER TABLE `transactions` DROP FOREIGN KEY `tx_ibfk_2`;
+ALTER TABLE `transactions`
+  DROP KEY `tx_ibfk_2_idx`,
+  DROP KEY `type`,
+  DROP KEY `gas_price`,
+  DROP KEY `amount`,
+  DROP COLUMN `to_account_id`,
+  DROP COLUMN `amount`;
@@ -0,0 +1,12 @@
+-- Counterparty and amount of transactions for filtering. Transactions
+-- indexed before this migration are backfilled from their raw
+-- transaction by 0008.
+
+ALTER TABLE `transactions`
+  ADD COLUMN `to_account_id` int(11) DEFAULT NULL AFTER `from_account_id`,
+  ADD COLUMN `amount` bigint(20) NOT NULL DEFAULT '0' AFTER `gas_price`,
+  ADD KEY `tx_ibfk_2_idx` (`to_account_id`),
+  ADD KEY `type` (`type`),
+  ADD KEY `gas_price` (`gas_price`),
+  ADD KEY `amount` (`amount`),
+  ADD CONSTRAINT `tx_ibfk_2` FOREIGN KEY (`to_account_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION;
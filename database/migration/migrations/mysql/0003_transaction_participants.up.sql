@@ -0,0 +1,38 @@
+-- Accounts taking part in each transaction by role. Senders and the
+-- parties of linked storage contracts are backfilled, recipients of
+-- transfers and auditors named by audits are backfilled by 0009.
+
+CREATE TABLE `transaction_participants` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `transaction_id` int(11) NOT NULL,
+  `account_id` int(11) NOT NULL,
+  `role` varchar(16) NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `tar_UNIQUE` (`transaction_id`,`account_id`,`role`),
+  KEY `account_role` (`account_id`,`role`),
+  CONSTRAINT `tp_ibfk_1` FOREIGN KEY (`transaction_id`) REFERENCES `transactions` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `tp_ibfk_2` FOREIGN KEY (`account_id`) REFERENCES `accounts` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+INSERT INTO `transaction_participants` (`transaction_id`, `account_id`, `role`)
+SELECT `id`, `from_account_id`, 'sender' FROM `transactions`;
+
+INSERT INTO `transaction_participants` (`transaction_id`, `account_id`, `role`)
+SELECT tc.`transaction_id`, sc.`owner_id`, 'owner'
+FROM `transaction_contracts` tc
+JOIN `storage_contracts` sc ON sc.`id` = tc.`contract_id`
+WHERE tc.`deleted_at` IS NULL;
+
+INSERT INTO `transaction_participants` (`transaction_id`, `account_id`, `role`)
+SELECT tc.`transaction_id`, sc.`depot_id`, 'depot'
+FROM `transaction_contracts` tc
+JOIN `storage_contracts` sc ON sc.`id` = tc.`contract_id`
+WHERE tc.`deleted_at` IS NULL;
+
+INSERT INTO `transaction_participants` (`transaction_id`, `account_id`, `role`)
+SELECT tc.`transaction_id`, sc.`auditor_id`, 'auditor'
+FROM `transaction_contracts` tc
+JOIN `storage_contracts` sc ON sc.`id` = tc.`contract_id`
+WHERE tc.`deleted_at` IS NULL AND sc.`auditor_id` IS NOT NULL;
@@ -0,0 +1,43 @@
+-- Webhook registry, deliveries and the outbox position of the delivery
+-- worker.
+
+CREATE TABLE `webhooks` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `url` varchar(512) NOT NULL,
+  `secret` varchar(128) NOT NULL,
+  `event_kinds` varchar(256) NOT NULL,
+  `accounts` text NOT NULL,
+  `contracts` text NOT NULL,
+  `tx_types` varchar(256) NOT NULL DEFAULT '',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE `webhook_deliveries` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `webhook_id` int(11) NOT NULL,
+  `event_id` bigint(20) NOT NULL,
+  `kind` varchar(32) NOT NULL,
+  `payload` text NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `attempts` int(11) NOT NULL DEFAULT '0',
+  `response_code` int(11) NOT NULL DEFAULT '0',
+  `last_error` varchar(512) NOT NULL DEFAULT '',
+  `next_attempt_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `status_next_attempt` (`status`,`next_attempt_at`),
+  KEY `webhook_status` (`webhook_id`,`status`),
+  CONSTRAINT `wd_ibfk_1` FOREIGN KEY (`webhook_id`) REFERENCES `webhooks` (`id`) ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE `webhook_cursors` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `last_event_id` bigint(20) NOT NULL,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1 @@
+ALTER TABLE `events` MODIFY `kind` varchar(16) NOT NULL;
@@ -0,0 +1,2 @@
+UPDATE `blocks` SET `proposal_index` = 0 WHERE `proposal_index` IS NULL;
+ALTER TABLE `blocks` MODIFY `proposal_index` int(11) NOT NULL;
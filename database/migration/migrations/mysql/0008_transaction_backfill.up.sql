@@ -0,0 +1,34 @@
+-- Backfills the counterparty and amount of the transactions indexed
+-- before 0002 from their raw transaction, the way the indexer derives
+-- them. The counterparty is the first of the recipient of a transfer,
+-- the owner or depot of an object commit and the auditor or depot of an
+-- object audit that isn't the sender and has an account. The types are
+-- the values of consensus.TxType.
+
+UPDATE `transactions` SET `amount` = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(`raw`, '$.balance_transfer.amount')), 0) WHERE `amount` = 0 AND `type` = 1;
+UPDATE `transactions` SET `amount` = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(`raw`, '$.validator_deposit.amount')), 0) WHERE `amount` = 0 AND `type` = 2;
+UPDATE `transactions` SET `amount` = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(`raw`, '$.auditor_deposit.amount')), 0) WHERE `amount` = 0 AND `type` = 4;
+UPDATE `transactions` SET `amount` = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(`raw`, '$.object_commit.fee')), 0) WHERE `amount` = 0 AND `type` = 6;
+UPDATE `transactions` SET `to_account_id` = (
+  SELECT `id` FROM `accounts`
+  WHERE `public_key` = JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.balance_transfer.to'))
+    AND `public_key` <> JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.from'))
+) WHERE `to_account_id` IS NULL AND `type` = 1;
+UPDATE `transactions` SET `to_account_id` = COALESCE((
+  SELECT `id` FROM `accounts`
+  WHERE `public_key` = JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.object_commit.owner'))
+    AND `public_key` <> JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.from'))
+), (
+  SELECT `id` FROM `accounts`
+  WHERE `public_key` = JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.object_commit.depot'))
+    AND `public_key` <> JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.from'))
+)) WHERE `to_account_id` IS NULL AND `type` = 6;
+UPDATE `transactions` SET `to_account_id` = COALESCE((
+  SELECT `id` FROM `accounts`
+  WHERE `public_key` = JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.object_audit.auditor'))
+    AND `public_key` <> JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.from'))
+), (
+  SELECT `id` FROM `accounts`
+  WHERE `public_key` = JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.object_audit.depot'))
+    AND `public_key` <> JSON_UNQUOTE(JSON_EXTRACT(`transactions`.`raw`, '$.from'))
+)) WHERE `to_account_id` IS NULL AND `type` = 7;
@@ -0,0 +1,4 @@
+-- The backfilled participants are the ones the indexer records, there
+-- is nothing to revert.
+
+SELECT 1;
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS "validators";
+DROP TABLE IF EXISTS "validator_participations";
+DROP TABLE IF EXISTS "transaction_contracts";
+DROP TABLE IF EXISTS "storage_proofs";
+DROP TABLE IF EXISTS "storage_contract_statuses";
+DROP TABLE IF EXISTS "storage_contracts";
+DROP TABLE IF EXISTS "transactions";
+DROP TABLE IF EXISTS "state_journals";
+DROP TABLE IF EXISTS "reorgs";
+DROP TABLE IF EXISTS "chain_status";
+DROP TABLE IF EXISTS "auditors";
+DROP TABLE IF EXISTS "attestations";
+DROP TABLE IF EXISTS "blocks";
+DROP TABLE IF EXISTS "account_ledgers";
+DROP TABLE IF EXISTS "accounts";
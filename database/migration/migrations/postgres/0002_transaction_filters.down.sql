@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "transactions_amount";
+DROP INDEX IF EXISTS "transactions_gas_price";
+DROP INDEX IF EXISTS "transactions_type";
+DROP INDEX IF EXISTS "transactions_tx_ibfk_2_idx";
+ALTER TABLE "transactions"
+  DROP COLUMN "amount",
+  DROP COLUMN "to_account_id";
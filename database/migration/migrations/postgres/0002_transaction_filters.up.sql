@@ -0,0 +1,12 @@
+-- Counterparty and amount of transactions for filtering. Transactions
+-- indexed before this migration are backfilled from their raw
+-- transaction by 0008.
+
+ALTER TABLE "transactions"
+  ADD COLUMN "to_account_id" bigint DEFAULT NULL,
+  ADD COLUMN "amount" bigint NOT NULL DEFAULT 0,
+  ADD CONSTRAINT "tx_ibfk_2" FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+CREATE INDEX "transactions_tx_ibfk_2_idx" ON "transactions" ("to_account_id");
+CREATE INDEX "transactions_type" ON "transactions" ("type");
+CREATE INDEX "transactions_gas_price" ON "transactions" ("gas_price");
+CREATE INDEX "transactions_amount" ON "transactions" ("amount");
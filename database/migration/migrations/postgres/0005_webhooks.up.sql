@@ -0,0 +1,40 @@
+-- Webhook registry, deliveries and the outbox position of the delivery
+-- worker.
+
+CREATE TABLE "webhooks" (
+  "id" bigserial PRIMARY KEY,
+  "url" varchar(512) NOT NULL,
+  "secret" varchar(128) NOT NULL,
+  "event_kinds" varchar(256) NOT NULL,
+  "accounts" text NOT NULL,
+  "contracts" text NOT NULL,
+  "tx_types" varchar(256) NOT NULL DEFAULT '',
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL
+);
+CREATE INDEX "webhooks_deleted_at" ON "webhooks" ("deleted_at");
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "webhook_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "kind" varchar(32) NOT NULL,
+  "payload" bytea NOT NULL,
+  "status" varchar(16) NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "response_code" integer NOT NULL DEFAULT 0,
+  "last_error" varchar(512) NOT NULL DEFAULT '',
+  "next_attempt_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "wd_ibfk_1" FOREIGN KEY ("webhook_id") REFERENCES "webhooks" ("id")
+);
+CREATE INDEX "webhook_deliveries_status_next_attempt" ON "webhook_deliveries" ("status", "next_attempt_at");
+CREATE INDEX "webhook_deliveries_webhook_status" ON "webhook_deliveries" ("webhook_id", "status");
+
+CREATE TABLE "webhook_cursors" (
+  "id" bigserial PRIMARY KEY,
+  "last_event_id" bigint NOT NULL,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Event kinds up to 32 characters, like the webhook delivery kinds.
+
+ALTER TABLE "events" ALTER COLUMN "kind" TYPE varchar(32);
@@ -0,0 +1,10 @@
+-- Empty slots indexed before their proposer was recorded kept a zero
+-- proposal_index, which counted as missed proposals of validator 0. The
+-- proposer of those slots is unknown, so it is set to null.
+-- The real misses of validator 0 can't be told apart from them and are
+-- cleared too. The node reports an unset proposer as 0 as well, so the
+-- indexer stores the same null for them and reindexing the range doesn't
+-- bring them back. Rows of other proposers are left as they are.
+
+ALTER TABLE "blocks" ALTER COLUMN "proposal_index" DROP NOT NULL;
+UPDATE "blocks" SET "proposal_index" = NULL WHERE "hash" = '' AND "proposal_index" = 0;
@@ -0,0 +1,4 @@
+-- The backfilled values are the ones the indexer stores, there is
+-- nothing to revert.
+
+SELECT 1;
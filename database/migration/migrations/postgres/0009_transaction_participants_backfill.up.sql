@@ -0,0 +1,23 @@
+-- Backfills the participants 0003 couldn't derive from the tables: the
+-- recipient of a transfer and the auditor named by an audit, read from
+-- the raw transaction as the indexer does. Participants the indexer
+-- already recorded are skipped. The types are the values of
+-- consensus.TxType.
+
+INSERT INTO "transaction_participants" ("transaction_id", "account_id", "role")
+SELECT t."id", a."id", 'recipient'
+FROM "transactions" t
+JOIN "accounts" a ON a."public_key" = (convert_from(t."raw", 'UTF8')::json -> 'balance_transfer' ->> 'to')
+WHERE t."type" = 1 AND NOT EXISTS (
+  SELECT 1 FROM "transaction_participants" tp
+  WHERE tp."transaction_id" = t."id" AND tp."account_id" = a."id" AND tp."role" = 'recipient'
+);
+
+INSERT INTO "transaction_participants" ("transaction_id", "account_id", "role")
+SELECT t."id", a."id", 'auditor'
+FROM "transactions" t
+JOIN "accounts" a ON a."public_key" = (convert_from(t."raw", 'UTF8')::json -> 'object_audit' ->> 'auditor')
+WHERE t."type" = 7 AND NOT EXISTS (
+  SELECT 1 FROM "transaction_participants" tp
+  WHERE tp."transaction_id" = t."id" AND tp."account_id" = a."id" AND tp."role" = 'auditor'
+);
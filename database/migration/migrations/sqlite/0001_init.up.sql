@@ -0,0 +1,241 @@
+-- Initial schema of the explorer.
+
+CREATE TABLE "accounts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "public_key" varchar(192) NOT NULL,
+  "nonce" integer NOT NULL,
+  "balance" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX "accounts_address_UNIQUE" ON "accounts" ("public_key");
+
+CREATE TABLE "account_ledgers" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "account_id" integer NOT NULL,
+  "block_id" integer DEFAULT NULL,
+  "kind" varchar(32) NOT NULL,
+  "slot" integer NOT NULL,
+  "tx_hash" varchar(128) NOT NULL DEFAULT '',
+  "delta" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "account_ledgers_account_slot" ON "account_ledgers" ("account_id","slot");
+CREATE INDEX "account_ledgers_block_id" ON "account_ledgers" ("block_id");
+
+CREATE TABLE "blocks" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "slot" integer NOT NULL,
+  "hash" varchar(64) NOT NULL,
+  "parent_hash" varchar(64) NOT NULL,
+  "state_hash" varchar(64) NOT NULL,
+  "proposal_index" integer NOT NULL,
+  "proposal_signature" varchar(192) NOT NULL,
+  "randao_reveal" varchar(192) NOT NULL,
+  "graffiti" varchar(64) NOT NULL,
+  "timestamp" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL
+);
+
+CREATE UNIQUE INDEX "blocks_sd_UNIQUE" ON "blocks" ("slot","deleted_at");
+CREATE INDEX "blocks_deleted_at" ON "blocks" ("deleted_at");
+
+CREATE TABLE "attestations" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "block_id" integer NOT NULL,
+  "committee_index" integer NOT NULL,
+  "aggregation_bits" varchar(128) NOT NULL,
+  "source_epoch" integer NOT NULL,
+  "source_hash" varchar(64) NOT NULL,
+  "target_epoch" integer NOT NULL,
+  "target_hash" varchar(64) NOT NULL,
+  "signature" varchar(192) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL,
+  CONSTRAINT "at_ibfk_1" FOREIGN KEY ("block_id") REFERENCES "blocks" ("id")
+);
+
+CREATE INDEX "attestations_at_ibfk_1_idx" ON "attestations" ("block_id");
+CREATE INDEX "attestations_deleted_at" ON "attestations" ("deleted_at");
+
+CREATE TABLE "auditors" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "account_id" integer NOT NULL,
+  "deposit" integer NOT NULL,
+  "status" integer NOT NULL,
+  "activation_epoch" integer NOT NULL,
+  "exit_epoch" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "auditor_ibfk_1" FOREIGN KEY ("account_id") REFERENCES "accounts" ("id")
+);
+
+CREATE INDEX "auditors_auditor_ibfk_1_idx" ON "auditors" ("account_id");
+
+CREATE TABLE "chain_status" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "next_slot" integer NOT NULL,
+  "current_hash" varchar(64) NOT NULL,
+  "finalized_slot" integer DEFAULT NULL,
+  "finalized_hash" varchar(64) DEFAULT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "reorgs" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "depth" integer NOT NULL,
+  "old_head_slot" integer NOT NULL,
+  "old_head_hash" varchar(64) NOT NULL,
+  "new_head_slot" integer NOT NULL,
+  "new_head_hash" varchar(64) NOT NULL,
+  "ancestor_slot" integer NOT NULL,
+  "ancestor_hash" varchar(64) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "reorgs_new_head_slot" ON "reorgs" ("new_head_slot");
+
+CREATE TABLE "state_journals" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "block_id" integer NOT NULL,
+  "entity" varchar(32) NOT NULL,
+  "entity_id" integer NOT NULL,
+  "created" boolean NOT NULL DEFAULT 0,
+  "pre_state" blob,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "state_journals_sj_ibfk_1_idx" ON "state_journals" ("block_id");
+
+CREATE TABLE "transactions" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "block_id" integer NOT NULL,
+  "hash" varchar(64) NOT NULL,
+  "from_account_id" integer NOT NULL,
+  "position" integer NOT NULL,
+  "type" integer NOT NULL,
+  "gas_price" integer NOT NULL,
+  "raw" blob NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL,
+  CONSTRAINT "tx_ibfk_1" FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id")
+);
+
+CREATE UNIQUE INDEX "transactions_hash_UNIQUE" ON "transactions" ("hash");
+CREATE INDEX "transactions_tx_ibfk_1_idx" ON "transactions" ("block_id");
+CREATE INDEX "transactions_deleted_at" ON "transactions" ("deleted_at");
+
+CREATE TABLE "storage_contracts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "commit_transaction_id" integer NOT NULL,
+  "object_hash" varchar(64) NOT NULL,
+  "status" integer NOT NULL,
+  "size" integer NOT NULL,
+  "fee" integer NOT NULL,
+  "pledge" integer NOT NULL,
+  "owner_id" integer NOT NULL,
+  "depot_id" integer NOT NULL,
+  "auditor_id" integer DEFAULT NULL,
+  "start_slot" integer NOT NULL,
+  "end_slot" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL,
+  CONSTRAINT "sc_ibfk_1" FOREIGN KEY ("commit_transaction_id") REFERENCES "transactions" ("id"),
+  CONSTRAINT "sc_ibfk_2" FOREIGN KEY ("owner_id") REFERENCES "accounts" ("id"),
+  CONSTRAINT "sc_ibfk_3" FOREIGN KEY ("depot_id") REFERENCES "accounts" ("id"),
+  CONSTRAINT "sc_ibfk_4" FOREIGN KEY ("auditor_id") REFERENCES "accounts" ("id")
+);
+
+CREATE INDEX "storage_contracts_sc_ibfk_1_idx" ON "storage_contracts" ("commit_transaction_id");
+CREATE INDEX "storage_contracts_sc_ibfk_2_idx" ON "storage_contracts" ("owner_id");
+CREATE INDEX "storage_contracts_sc_ibfk_3_idx" ON "storage_contracts" ("depot_id");
+CREATE INDEX "storage_contracts_sc_ibfk_4_idx" ON "storage_contracts" ("auditor_id");
+CREATE INDEX "storage_contracts_deleted_at" ON "storage_contracts" ("deleted_at");
+
+CREATE TABLE "storage_contract_statuses" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "contract_id" integer NOT NULL,
+  "prev_status" integer NOT NULL,
+  "status" integer NOT NULL,
+  "slot" integer NOT NULL,
+  "transaction_id" integer DEFAULT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "scs_ibfk_1" FOREIGN KEY ("contract_id") REFERENCES "storage_contracts" ("id"),
+  CONSTRAINT "scs_ibfk_2" FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id")
+);
+
+CREATE INDEX "storage_contract_statuses_scs_ibfk_1_idx" ON "storage_contract_statuses" ("contract_id");
+CREATE INDEX "storage_contract_statuses_scs_ibfk_2_idx" ON "storage_contract_statuses" ("transaction_id");
+
+CREATE TABLE "storage_proofs" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "contract_id" integer NOT NULL,
+  "transaction_id" integer NOT NULL,
+  "depot_id" integer NOT NULL,
+  "slot" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL,
+  CONSTRAINT "sp_ibfk_1" FOREIGN KEY ("contract_id") REFERENCES "storage_contracts" ("id"),
+  CONSTRAINT "sp_ibfk_2" FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id")
+);
+
+CREATE INDEX "storage_proofs_sp_ibfk_1_idx" ON "storage_proofs" ("contract_id");
+CREATE INDEX "storage_proofs_sp_ibfk_2_idx" ON "storage_proofs" ("transaction_id");
+CREATE INDEX "storage_proofs_deleted_at" ON "storage_proofs" ("deleted_at");
+
+CREATE TABLE "transaction_contracts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "transaction_id" integer NOT NULL,
+  "contract_id" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL,
+  CONSTRAINT "tc_ibfk_1" FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id"),
+  CONSTRAINT "tc_ibfk_2" FOREIGN KEY ("contract_id") REFERENCES "storage_contracts" ("id")
+);
+
+CREATE INDEX "transaction_contracts_tc_ibfk_1_idx" ON "transaction_contracts" ("transaction_id");
+CREATE INDEX "transaction_contracts_tc_ibfk_2_idx" ON "transaction_contracts" ("contract_id");
+CREATE INDEX "transaction_contracts_deleted_at" ON "transaction_contracts" ("deleted_at");
+
+CREATE TABLE "validator_participations" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "validator_idx" integer NOT NULL,
+  "epoch" integer NOT NULL,
+  "attestation_slot" integer NOT NULL,
+  "attested" boolean NOT NULL DEFAULT 0,
+  "inclusion_slot" integer NOT NULL DEFAULT 0,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX "validator_participations_ve_UNIQUE" ON "validator_participations" ("validator_idx","epoch");
+
+CREATE TABLE "validators" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "account_id" integer NOT NULL,
+  "idx" integer NOT NULL,
+  "deposit" integer NOT NULL,
+  "status" integer NOT NULL,
+  "activation_epoch" integer NOT NULL,
+  "exit_epoch" integer NOT NULL,
+  "attest_block_id" integer DEFAULT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "validator_ibfk_1" FOREIGN KEY ("account_id") REFERENCES "accounts" ("id")
+);
+
+CREATE INDEX "validators_validator_ibfk_1_idx" ON "validators" ("account_id");
+CREATE INDEX "validators_validator_ibfk_2_idx" ON "validators" ("attest_block_id");
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "transactions_amount";
+DROP INDEX IF EXISTS "transactions_gas_price";
+DROP INDEX IF EXISTS "transactions_type";
+DROP INDEX IF EXISTS "transactions_tx_ibfk_2_idx";
+ALTER TABLE "transactions" DROP COLUMN "amount";
+ALTER TABLE "transactions" DROP COLUMN "to_account_id";
@@ -0,0 +1,11 @@
+-- Counterparty and amount of transactions for filtering. Transactions
+-- indexed before this migration are backfilled from their raw
+-- transaction by 0008. SQLite can't drop a column holding a
+-- foreign key, so to_account_id is left unconstrained here.
+
+ALTER TABLE "transactions" ADD COLUMN "to_account_id" integer DEFAULT NULL;
+ALTER TABLE "transactions" ADD COLUMN "amount" integer NOT NULL DEFAULT 0;
+CREATE INDEX "transactions_tx_ibfk_2_idx" ON "transactions" ("to_account_id");
+CREATE INDEX "transactions_type" ON "transactions" ("type");
+CREATE INDEX "transactions_gas_price" ON "transactions" ("gas_price");
+CREATE INDEX "transactions_amount" ON "transactions" ("amount");
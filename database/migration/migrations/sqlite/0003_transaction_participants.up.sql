@@ -0,0 +1,37 @@
+-- Accounts taking part in each transaction by role. Senders and the
+-- parties of linked storage contracts are backfilled, recipients of
+-- transfers and auditors named by audits are backfilled by 0009.
+
+CREATE TABLE "transaction_participants" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "transaction_id" integer NOT NULL,
+  "account_id" integer NOT NULL,
+  "role" varchar(16) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "tp_ibfk_1" FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id"),
+  CONSTRAINT "tp_ibfk_2" FOREIGN KEY ("account_id") REFERENCES "accounts" ("id")
+);
+CREATE UNIQUE INDEX "transaction_participants_tar_UNIQUE" ON "transaction_participants" ("transaction_id", "account_id", "role");
+CREATE INDEX "transaction_participants_account_role" ON "transaction_participants" ("account_id", "role");
+
+INSERT INTO "transaction_participants" ("transaction_id", "account_id", "role")
+SELECT "id", "from_account_id", 'sender' FROM "transactions";
+
+INSERT INTO "transaction_participants" ("transaction_id", "account_id", "role")
+SELECT tc."transaction_id", sc."owner_id", 'owner'
+FROM "transaction_contracts" tc
+JOIN "storage_contracts" sc ON sc."id" = tc."contract_id"
+WHERE tc."deleted_at" IS NULL;
+
+INSERT INTO "transaction_participants" ("transaction_id", "account_id", "role")
+SELECT tc."transaction_id", sc."depot_id", 'depot'
+FROM "transaction_contracts" tc
+JOIN "storage_contracts" sc ON sc."id" = tc."contract_id"
+WHERE tc."deleted_at" IS NULL;
+
+INSERT INTO "transaction_participants" ("transaction_id", "account_id", "role")
+SELECT tc."transaction_id", sc."auditor_id", 'auditor'
+FROM "transaction_contracts" tc
+JOIN "storage_contracts" sc ON sc."id" = tc."contract_id"
+WHERE tc."deleted_at" IS NULL AND sc."auditor_id" IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Outbox of the events streamed by the api server.
+
+CREATE TABLE "events" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "kind" varchar(16) NOT NULL,
+  "slot" integer NOT NULL,
+  "payload" blob NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX "events_created_at" ON "events" ("created_at");
@@ -0,0 +1,11 @@
+ALTER TABLE "events" RENAME TO "events_old";
+CREATE TABLE "events" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "kind" varchar(16) NOT NULL,
+  "slot" integer NOT NULL,
+  "payload" blob NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO "events" SELECT * FROM "events_old";
+DROP TABLE "events_old";
+CREATE INDEX "events_created_at" ON "events" ("created_at");
@@ -0,0 +1,16 @@
+-- Event kinds up to 32 characters, like the webhook delivery kinds.
+-- SQLite doesn't enforce the width but can't alter a column either, so
+-- the table is rebuilt to keep the schema in line with the other
+-- dialects.
+
+ALTER TABLE "events" RENAME TO "events_old";
+CREATE TABLE "events" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "kind" varchar(32) NOT NULL,
+  "slot" integer NOT NULL,
+  "payload" blob NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO "events" SELECT * FROM "events_old";
+DROP TABLE "events_old";
+CREATE INDEX "events_created_at" ON "events" ("created_at");
@@ -0,0 +1,23 @@
+PRAGMA defer_foreign_keys = ON;
+UPDATE "blocks" SET "proposal_index" = 0 WHERE "proposal_index" IS NULL;
+CREATE TABLE "blocks_old" AS SELECT * FROM "blocks";
+DROP TABLE "blocks";
+CREATE TABLE "blocks" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "slot" integer NOT NULL,
+  "hash" varchar(64) NOT NULL,
+  "parent_hash" varchar(64) NOT NULL,
+  "state_hash" varchar(64) NOT NULL,
+  "proposal_index" integer NOT NULL,
+  "proposal_signature" varchar(192) NOT NULL,
+  "randao_reveal" varchar(192) NOT NULL,
+  "graffiti" varchar(64) NOT NULL,
+  "timestamp" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL
+);
+INSERT INTO "blocks" SELECT * FROM "blocks_old";
+DROP TABLE "blocks_old";
+CREATE UNIQUE INDEX "blocks_sd_UNIQUE" ON "blocks" ("slot","deleted_at");
+CREATE INDEX "blocks_deleted_at" ON "blocks" ("deleted_at");
@@ -0,0 +1,34 @@
+-- Empty slots indexed before their proposer was recorded kept a zero
+-- proposal_index, which counted as missed proposals of validator 0. The
+-- proposer of those slots is unknown, so it is set to null.
+-- The real misses of validator 0 can't be told apart from them and are
+-- cleared too. The node reports an unset proposer as 0 as well, so the
+-- indexer stores the same null for them and reindexing the range doesn't
+-- bring them back. Rows of other proposers are left as they are.
+-- SQLite can't drop a NOT NULL constraint, so the table is rebuilt. The
+-- rows referencing blocks are only checked at commit, once the blocks
+-- are copied back.
+
+PRAGMA defer_foreign_keys = ON;
+CREATE TABLE "blocks_old" AS SELECT * FROM "blocks";
+DROP TABLE "blocks";
+CREATE TABLE "blocks" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "slot" integer NOT NULL,
+  "hash" varchar(64) NOT NULL,
+  "parent_hash" varchar(64) NOT NULL,
+  "state_hash" varchar(64) NOT NULL,
+  "proposal_index" integer NULL,
+  "proposal_signature" varchar(192) NOT NULL,
+  "randao_reveal" varchar(192) NOT NULL,
+  "graffiti" varchar(64) NOT NULL,
+  "timestamp" integer NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" timestamp NULL
+);
+INSERT INTO "blocks" SELECT * FROM "blocks_old";
+DROP TABLE "blocks_old";
+CREATE UNIQUE INDEX "blocks_sd_UNIQUE" ON "blocks" ("slot","deleted_at");
+CREATE INDEX "blocks_deleted_at" ON "blocks" ("deleted_at");
+UPDATE "blocks" SET "proposal_index" = NULL WHERE "hash" = '' AND "proposal_index" = 0;
@@ -9,6 +9,8 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/plugin/dbresolver"
+
+	"github.com/photon-storage/photon-explorer/database/internal/gormlog"
 )
 
 // NewMySQLDB creates the mysql master/slaves cluster.
@@ -23,7 +25,7 @@ func NewMySQLDB(cfg Config) (*gorm.DB, error) {
 	)
 
 	db, err := gorm.Open(mysql.Open(masterDSN), &gorm.Config{
-		Logger:          logger.Default.LogMode(parseLoggerLevel(cfg.LogLevel)),
+		Logger:          logger.Default.LogMode(gormlog.Level(cfg.LogLevel)),
 		CreateBatchSize: 100,
 	})
 	if err != nil {
@@ -58,16 +60,3 @@ func NewMySQLDB(cfg Config) (*gorm.DB, error) {
 
 	return db, nil
 }
-
-func parseLoggerLevel(logStr string) logger.LogLevel {
-	switch logStr {
-	case "silent":
-		return logger.Silent
-	case "error":
-		return logger.Error
-	case "warn":
-		return logger.Warn
-	default:
-		return logger.Info
-	}
-}
@@ -0,0 +1,36 @@
+package orm
+
+import "time"
+
+// Kinds of the balance-affecting events recorded in the account ledger.
+const (
+	LedgerGenesis        = "genesis"
+	LedgerTransferIn     = "transfer_in"
+	LedgerTransferOut    = "transfer_out"
+	LedgerGasFee         = "gas_fee"
+	LedgerContractFee    = "contract_fee"
+	LedgerDepotPledge    = "depot_pledge"
+	LedgerDeposit        = "deposit"
+	LedgerReconciliation = "reconciliation"
+	LedgerRollback       = "rollback"
+)
+
+// AccountLedger is a gorm table definition represents the
+// account_ledgers, one row per event changing an account balance. Rows
+// are never deleted, a rolled back block gets reversal rows instead, so
+// summing the deltas up to a slot gives the balance at that slot.
+type AccountLedger struct {
+	ID        uint64 `gorm:"primary_key"`
+	AccountID uint64
+	// BlockID is the block of the event, null for the genesis balances
+	// and the per epoch reconciliation with the node.
+	BlockID   uint64 `gorm:"default:null"`
+	Kind      string
+	Slot      uint64
+	TxHash    string
+	Delta     int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Account *Account `gorm:"foreignkey:AccountID"`
+}
@@ -6,14 +6,16 @@ import (
 	"gorm.io/gorm"
 )
 
-// Block is a gorm table definition represents the blocks.
+// Block is a gorm table definition represents the blocks. Empty slots
+// are kept with an empty hash, and a nil ProposalIndex when their
+// proposer is unknown.
 type Block struct {
 	ID                uint64 `gorm:"primary_key"`
 	Slot              uint64
 	Hash              string
 	ParentHash        string
 	StateHash         string
-	ProposalIndex     uint64
+	ProposalIndex     *uint64
 	ProposalSignature string
 	RandaoReveal      string
 	Graffiti          string
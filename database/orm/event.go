@@ -0,0 +1,89 @@
+package orm
+
+import "time"
+
+// Kinds of the events in the outbox.
+const (
+	EventBlock       = "block"
+	EventTransaction = "transaction"
+	EventFinality    = "finality"
+	EventReorg       = "reorg"
+
+	EventContractStatus    = "contract_status"
+	EventMissedAttestation = "missed_attestation"
+)
+
+// Event is a gorm table definition represents the events, the outbox the
+// indexer writes in the same DB transaction as the data it announces.
+// Rows are appended in id order and never rewritten, a rolled back block
+// is announced by a later reorg event.
+type Event struct {
+	ID        uint64 `gorm:"primary_key"`
+	Kind      string
+	Slot      uint64
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// BlockEvent is the payload of a block event.
+type BlockEvent struct {
+	Slot          uint64 `json:"slot"`
+	Hash          string `json:"hash"`
+	ParentHash    string `json:"parent_hash"`
+	ProposerIndex uint64 `json:"proposer_index"`
+	TxCount       int    `json:"tx_count"`
+	Timestamp     uint64 `json:"timestamp"`
+}
+
+// TransactionEvent is the payload of a transaction event. Accounts are
+// the public keys of all participants.
+type TransactionEvent struct {
+	Hash     string   `json:"hash"`
+	Slot     uint64   `json:"slot"`
+	Position uint64   `json:"position"`
+	Type     string   `json:"type"`
+	From     string   `json:"from"`
+	To       string   `json:"to,omitempty"`
+	Accounts []string `json:"accounts"`
+	Amount   uint64   `json:"amount"`
+	GasPrice uint64   `json:"gas_price"`
+}
+
+// FinalityEvent is the payload of a finality event.
+type FinalityEvent struct {
+	Slot uint64 `json:"slot"`
+	Hash string `json:"hash"`
+}
+
+// ReorgEvent is the payload of a reorg event. Events of the blocks above
+// AncestorSlot published before it are void.
+type ReorgEvent struct {
+	Depth        uint64 `json:"depth"`
+	OldHeadSlot  uint64 `json:"old_head_slot"`
+	OldHeadHash  string `json:"old_head_hash"`
+	NewHeadSlot  uint64 `json:"new_head_slot"`
+	NewHeadHash  string `json:"new_head_hash"`
+	AncestorSlot uint64 `json:"ancestor_slot"`
+	AncestorHash string `json:"ancestor_hash"`
+}
+
+// ContractStatusEvent is the payload of a contract_status event. Hash is
+// the hash of the commit transaction.
+type ContractStatusEvent struct {
+	Hash       string `json:"hash"`
+	Owner      string `json:"owner"`
+	Depot      string `json:"depot"`
+	Auditor    string `json:"auditor,omitempty"`
+	PrevStatus string `json:"prev_status"`
+	Status     string `json:"status"`
+	Slot       uint64 `json:"slot"`
+	TxHash     string `json:"tx_hash,omitempty"`
+}
+
+// MissedAttestationEvent is the payload of a missed_attestation event.
+type MissedAttestationEvent struct {
+	ValidatorIndex  uint64 `json:"validator_index"`
+	PublicKey       string `json:"public_key"`
+	Epoch           uint64 `json:"epoch"`
+	AttestationSlot uint64 `json:"attestation_slot"`
+}
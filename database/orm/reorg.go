@@ -0,0 +1,18 @@
+package orm
+
+import "time"
+
+// Reorg is a gorm table definition represents the reorgs, one row per
+// chain reorganization unwound by the indexer.
+type Reorg struct {
+	ID           uint64 `gorm:"primary_key"`
+	Depth        uint64
+	OldHeadSlot  uint64
+	OldHeadHash  string
+	NewHeadSlot  uint64
+	NewHeadHash  string
+	AncestorSlot uint64
+	AncestorHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
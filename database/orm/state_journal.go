@@ -0,0 +1,19 @@
+package orm
+
+import "time"
+
+// StateJournal is a gorm table definition represents the
+// state_journals. Every row an indexed block modifies gets one entry
+// holding the row as it was before the block, or a creation marker, so
+// the block can be rolled back without the photon node.
+type StateJournal struct {
+	ID       uint64 `gorm:"primary_key"`
+	BlockID  uint64
+	Entity   string
+	EntityID uint64
+	// Created marks a row inserted by the block.
+	Created bool
+	// PreState is the JSON encoded row before the block modified it.
+	PreState  []byte
+	CreatedAt time.Time
+}
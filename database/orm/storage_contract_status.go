@@ -0,0 +1,21 @@
+package orm
+
+import "time"
+
+// StorageContractStatus is a gorm table definition represents the
+// storage_contract_statuses, one row per status transition of a storage
+// contract.
+type StorageContractStatus struct {
+	ID         uint64 `gorm:"primary_key"`
+	ContractID uint64
+	PrevStatus int32
+	Status     int32
+	Slot       uint64
+	// TransactionID is the transaction triggering the transition, null
+	// for transitions found by the per epoch refresh, e.g. expiry.
+	TransactionID uint64 `gorm:"default:null"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Transaction *Transaction `gorm:"foreignkey:TransactionID"`
+}
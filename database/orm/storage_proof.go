@@ -0,0 +1,23 @@
+package orm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StorageProof is a gorm table definition represents the storage_proofs,
+// the proofs of retrievability submitted for a storage contract.
+type StorageProof struct {
+	ID            uint64 `gorm:"primary_key"`
+	ContractID    uint64
+	TransactionID uint64
+	DepotID       uint64
+	Slot          uint64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt
+
+	Depot       *Account     `gorm:"foreignkey:DepotID"`
+	Transaction *Transaction `gorm:"foreignkey:TransactionID"`
+}
@@ -12,8 +12,10 @@ type Transaction struct {
 	BlockID       uint64
 	Hash          string
 	FromAccountID uint64
+	ToAccountID   uint64 `gorm:"default:null"`
 	Position      uint64
 	GasPrice      uint64
+	Amount        uint64
 	Type          int32
 	Raw           []byte
 	CreatedAt     time.Time
@@ -22,4 +24,5 @@ type Transaction struct {
 
 	Block       *Block   `gorm:"foreignkey:BlockID"`
 	FromAccount *Account `gorm:"foreignkey:FromAccountID"`
+	ToAccount   *Account `gorm:"foreignkey:ToAccountID"`
 }
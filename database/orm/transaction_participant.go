@@ -0,0 +1,27 @@
+package orm
+
+import "time"
+
+// Roles of the accounts taking part in a transaction.
+const (
+	RoleSender    = "sender"
+	RoleRecipient = "recipient"
+	RoleOwner     = "owner"
+	RoleDepot     = "depot"
+	RoleAuditor   = "auditor"
+)
+
+// TransactionParticipant is a gorm table definition represents the
+// transaction_participants, one row per account and role in a
+// transaction.
+type TransactionParticipant struct {
+	ID            uint64 `gorm:"primary_key"`
+	TransactionID uint64
+	AccountID     uint64
+	Role          string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Transaction *Transaction `gorm:"foreignkey:TransactionID"`
+	Account     *Account     `gorm:"foreignkey:AccountID"`
+}
@@ -0,0 +1,20 @@
+package orm
+
+import "time"
+
+// ValidatorParticipation is a gorm table definition represents the
+// validator_participations, the attestation duty of a validator in one
+// epoch and whether an attestation for it was included.
+type ValidatorParticipation struct {
+	ID             uint64 `gorm:"primary_key"`
+	ValidatorIndex uint64 `gorm:"column:validator_idx"`
+	Epoch          uint64
+	// AttestationSlot is the slot the validator was assigned to attest.
+	AttestationSlot uint64
+	Attested        bool
+	// InclusionSlot is the slot of the block first including the
+	// attestation.
+	InclusionSlot uint64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of the notifications a webhook can subscribe to.
+const (
+	WebhookTransaction       = "transaction"
+	WebhookFundsReceived     = "funds_received"
+	WebhookContractStatus    = "contract_status"
+	WebhookMissedAttestation = "missed_attestation"
+	WebhookReorg             = "reorg"
+)
+
+// Statuses of a webhook delivery. A delivery out of attempts is dead and
+// stays in the table as the dead-letter log.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Webhook is a gorm table definition represents the webhooks. The
+// filters are comma separated lists, an empty list matches everything.
+type Webhook struct {
+	ID         uint64 `gorm:"primary_key"`
+	URL        string
+	Secret     string
+	EventKinds string
+	Accounts   string
+	Contracts  string
+	TxTypes    string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt
+}
+
+// WebhookDelivery is a gorm table definition represents the
+// webhook_deliveries, one row per notification sent to a webhook.
+type WebhookDelivery struct {
+	ID            uint64 `gorm:"primary_key"`
+	WebhookID     uint64
+	EventID       uint64
+	Kind          string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	ResponseCode  int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Webhook *Webhook `gorm:"foreignkey:WebhookID"`
+}
+
+// WebhookCursor is a gorm table definition represents the
+// webhook_cursors, the last outbox event turned into deliveries.
+type WebhookCursor struct {
+	ID          uint64 `gorm:"primary_key"`
+	LastEventID uint64
+	UpdatedAt   time.Time
+}
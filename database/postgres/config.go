@@ -0,0 +1,38 @@
+package postgres
+
+import "fmt"
+
+// Config defines postgres configuration.
+type Config struct {
+	Master       connection   `yaml:"master"`
+	Slaves       []connection `yaml:"slaves"`
+	MaxOpenConns int          `yaml:"max_open_conns"`
+	MaxIdleConns int          `yaml:"max_idle_conns"`
+	LogLevel     string       `yaml:"log_level"`
+}
+
+type connection struct {
+	Host     string `yaml:"host"`
+	Port     uint   `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"db_name"`
+	SSLMode  string `yaml:"ssl_mode"`
+}
+
+func (c connection) dsn() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
+		c.Host,
+		c.Port,
+		c.Username,
+		c.Password,
+		c.DBName,
+		sslMode,
+	)
+}
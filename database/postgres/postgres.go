@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/photon-storage/photon-explorer/database/internal/gormlog"
+)
+
+// NewPostgresDB creates the postgres master/slaves cluster.
+func NewPostgresDB(cfg Config) (*gorm.DB, error) {
+	masterDSN := cfg.Master.dsn()
+	db, err := gorm.Open(postgres.Open(masterDSN), &gorm.Config{
+		Logger:          logger.Default.LogMode(gormlog.Level(cfg.LogLevel)),
+		CreateBatchSize: 100,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "open master postgres")
+	}
+
+	var slaveDSNs []gorm.Dialector
+	for _, slave := range cfg.Slaves {
+		slaveDSNs = append(slaveDSNs, postgres.Open(slave.dsn()))
+	}
+
+	dbResolverCfg := dbresolver.Config{
+		Sources:  []gorm.Dialector{postgres.Open(masterDSN)},
+		Replicas: slaveDSNs,
+		Policy:   dbresolver.RandomPolicy{},
+	}
+	if err := db.Use(dbresolver.Register(dbResolverCfg).
+		SetConnMaxIdleTime(time.Hour).
+		SetConnMaxLifetime(24 * time.Hour).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetMaxOpenConns(cfg.MaxOpenConns),
+	); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
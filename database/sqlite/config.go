@@ -0,0 +1,8 @@
+package sqlite
+
+// Config defines sqlite configuration.
+type Config struct {
+	// Path is the database file, ":memory:" keeps the database in memory.
+	Path     string `yaml:"path"`
+	LogLevel string `yaml:"log_level"`
+}
@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/photon-storage/photon-explorer/database/internal/gormlog"
+)
+
+// NewSQLiteDB opens the sqlite database. Foreign keys are enforced like
+// in the server backends, and a single connection is used since sqlite
+// allows one writer at a time.
+func NewSQLiteDB(cfg Config) (*gorm.DB, error) {
+	path := cfg.Path
+	if path == "" {
+		return nil, errors.New("missing sqlite path")
+	}
+
+	dsn := fmt.Sprintf(
+		"file:%s?_foreign_keys=1&_busy_timeout=5000&_journal_mode=WAL",
+		path,
+	)
+	if path == ":memory:" {
+		dsn = "file::memory:?_foreign_keys=1"
+	}
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:          logger.Default.LogMode(gormlog.Level(cfg.LogLevel)),
+		CreateBatchSize: 100,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "open sqlite")
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	return db, nil
+}
@@ -0,0 +1,192 @@
+// Package health answers the liveness and readiness probes of the
+// explorer binaries. A binary is ready once its database is reachable
+// with the expected schema and the index is close enough to the photon
+// node head. The indexer is live as long as its head keeps moving while
+// it is behind.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// Config defines the health check configuration.
+type Config struct {
+	// MaxLag is the number of slots the index may be behind the node
+	// head while ready.
+	MaxLag uint64 `yaml:"max_lag"`
+	// StallTimeout is how long the indexed head may stay still while
+	// more than MaxLag behind before the indexer is reported dead.
+	StallTimeout time.Duration `yaml:"stall_timeout"`
+	// Timeout bounds a single check.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// DefaultConfig returns the health check configuration used when a field
+// is not set.
+func DefaultConfig() Config {
+	return Config{
+		MaxLag:       32,
+		StallTimeout: 5 * time.Minute,
+		Timeout:      5 * time.Second,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.MaxLag == 0 {
+		c.MaxLag = d.MaxLag
+	}
+
+	if c.StallTimeout == 0 {
+		c.StallTimeout = d.StallTimeout
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = d.Timeout
+	}
+
+	return c
+}
+
+// Checker runs the checks against the database and the photon node.
+type Checker struct {
+	cfg  Config
+	db   *gorm.DB
+	node chain.NodeClient
+
+	mu      sync.Mutex
+	head    uint64
+	movedAt time.Time
+}
+
+// NewChecker returns the new instance of Checker. The stall timeout runs
+// from its creation until the indexed head first moves.
+func NewChecker(cfg Config, db *gorm.DB, node chain.NodeClient) *Checker {
+	return &Checker{
+		cfg:     cfg.withDefaults(),
+		db:      db,
+		node:    node,
+		movedAt: time.Now(),
+	}
+}
+
+// Ready returns why the index can't be served, nil when it can.
+func (c *Checker) Ready(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return errors.Wrap(err, "database unreachable")
+	}
+
+	if err := migration.Check(c.db.WithContext(ctx)); err != nil {
+		return err
+	}
+
+	head, nodeHead, err := c.heads(ctx)
+	if err != nil {
+		return err
+	}
+
+	if nodeHead > head+c.cfg.MaxLag {
+		return errors.Errorf("indexed head %d is %d slots behind the node",
+			head, nodeHead-head)
+	}
+
+	return nil
+}
+
+// Live returns an error once the indexed head has not moved for the
+// stall timeout while more than MaxLag slots behind the node. The
+// failures of the database or the node are left to Ready, restarting
+// the indexer does not fix them.
+func (c *Checker) Live(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	head, nodeHead, err := c.heads(ctx)
+	if err != nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if head != c.head || nodeHead <= head+c.cfg.MaxLag {
+		c.head = head
+		c.movedAt = now
+		return nil
+	}
+
+	if stalled := now.Sub(c.movedAt); stalled > c.cfg.StallTimeout {
+		return errors.Errorf("indexed head %d stalled for %s, %d slots "+
+			"behind the node", head, stalled.Round(time.Second), nodeHead-head)
+	}
+
+	return nil
+}
+
+// heads returns the last indexed slot and the node head slot.
+func (c *Checker) heads(ctx context.Context) (uint64, uint64, error) {
+	cs := &orm.ChainStatus{}
+	if err := c.db.WithContext(ctx).
+		Model(cs).
+		First(cs).
+		Error; err != nil {
+		return 0, 0, errors.Wrap(err, "query chain status")
+	}
+
+	if cs.NextSlot == 0 {
+		return 0, 0, errors.New("nothing indexed yet")
+	}
+
+	status, err := c.node.ChainStatus(ctx)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "request node chain status")
+	}
+
+	return cs.NextSlot - 1, status.Best.Slot, nil
+}
+
+// Alive is the liveness check of a binary that is live while it serves.
+func Alive(context.Context) error {
+	return nil
+}
+
+type statusResp struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Handler answers a probe with 200 when check passes and 503 with the
+// error otherwise.
+func Handler(check func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code, resp := http.StatusOK, &statusResp{Status: "ok"}
+		if err := check(r.Context()); err != nil {
+			code = http.StatusServiceUnavailable
+			resp = &statusResp{Status: "unavailable", Error: err.Error()}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/database/sqlite"
+)
+
+func TestChecker(t *testing.T) {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+	for i := 0; i < 10; i++ {
+		gw.AddBlock()
+	}
+
+	ctx := context.Background()
+	c := NewChecker(
+		Config{MaxLag: 4, StallTimeout: 10 * time.Millisecond},
+		db,
+		gw.Client(),
+	)
+
+	if err := c.Ready(ctx); err == nil {
+		t.Errorf("ready without an indexed slot")
+	}
+
+	if err := db.Create(&orm.ChainStatus{NextSlot: 3}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Ready(ctx); err == nil {
+		t.Errorf("ready 7 slots behind")
+	}
+	if err := c.Live(ctx); err != nil {
+		t.Errorf("dead before the stall timeout: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if err := c.Live(ctx); err == nil {
+		t.Errorf("live with a stalled head")
+	}
+
+	if err := db.Model(&orm.ChainStatus{}).
+		Where("id = 1").
+		Update("next_slot", 8).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Ready(ctx); err != nil {
+		t.Errorf("not ready 2 slots behind: %v", err)
+	}
+	if err := c.Live(ctx); err != nil {
+		t.Errorf("dead after the head moved: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Handler(c.Ready)(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("readyz code = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	gw.FailNext("/chain-status", 1)
+	w = httptest.NewRecorder()
+	Handler(c.Ready)(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("readyz code with the node down = %d, want %d",
+			w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -6,11 +6,31 @@ import (
 	"github.com/photon-storage/photon-explorer/database/orm"
 )
 
-func updateAccountBalance(db *gorm.DB, pk string, amount int64) error {
-	return db.Model(&orm.Account{}).
-		Where("public_key = ?", pk).
+func updateAccountBalance(
+	dbTx *gorm.DB,
+	j *journal,
+	pk string,
+	amount int64,
+	kind string,
+	txHash string,
+) error {
+	accountID, err := getAccountIDByPublicKey(dbTx, pk)
+	if err != nil {
+		return err
+	}
+
+	if err := j.accountByID(accountID); err != nil {
+		return err
+	}
+
+	if err := dbTx.Model(&orm.Account{}).
+		Where("id = ?", accountID).
 		Update("balance", gorm.Expr("balance + ?", amount)).
-		Error
+		Error; err != nil {
+		return err
+	}
+
+	return j.ledger(accountID, kind, txHash, amount)
 }
 
 func getAccountIDByPublicKey(dbTx *gorm.DB, pk string) (uint64, error) {
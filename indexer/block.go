@@ -15,7 +15,7 @@ import (
 
 func processBlock(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
 	block *gateway.BlockResp,
 ) (string, uint64, error) {
@@ -24,17 +24,30 @@ func processBlock(
 		return "", 0, err
 	}
 
+	j := newJournal(dbTx, blockID, block.Slot)
 	if err := processAttestations(
 		ctx,
 		node,
 		dbTx,
+		j,
 		blockID,
 		block.Attestations,
 	); err != nil {
 		return "", 0, err
 	}
 
-	if err := processTransactions(ctx, node, dbTx, blockID, block); err != nil {
+	if err := processTransactions(
+		ctx,
+		node,
+		dbTx,
+		j,
+		blockID,
+		block,
+	); err != nil {
+		return "", 0, err
+	}
+
+	if err := publishBlockEvents(dbTx, blockID, block); err != nil {
 		return "", 0, err
 	}
 
@@ -51,28 +64,17 @@ func processBlock(
 
 func processAttestations(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
 	blockID uint64,
 	attestations []*gateway.Attestation,
 ) error {
 	committeesCache := make(map[uint64][]*chain.Committee)
 	for _, a := range attestations {
-		bits := strings.Trim(
-			strings.Join(strings.Fields(fmt.Sprint(a.AggregationBits)), ","),
-			"[]",
-		)
 		if err := dbTx.Model(&orm.Attestation{}).
-			Create(&orm.Attestation{
-				BlockID:         blockID,
-				CommitteeIndex:  a.CommitteeIndex,
-				AggregationBits: bits,
-				SourceEpoch:     a.Source.Epoch,
-				SourceHash:      a.Source.Hash,
-				TargetEpoch:     a.Target.Epoch,
-				TargetHash:      a.Target.Hash,
-				Signature:       a.Signature,
-			}).Error; err != nil {
+			Create(newAttestation(blockID, a)).
+			Error; err != nil {
 			return err
 		}
 
@@ -89,12 +91,28 @@ func processAttestations(
 		for _, c := range cs {
 			if c.CommitteeIndex == a.CommitteeIndex {
 				for _, ab := range a.AggregationBits {
+					if err := j.validator(
+						"idx = ?",
+						c.ValidatorIndexes[ab],
+					); err != nil {
+						return err
+					}
+
 					if err := dbTx.Model(&orm.Validator{}).
 						Where("idx = ?", c.ValidatorIndexes[ab]).
 						Update("attest_block_id", blockID).
 						Error; err != nil {
 						return err
 					}
+
+					if err := recordAttestation(
+						dbTx,
+						j,
+						c.ValidatorIndexes[ab],
+						a.Slot,
+					); err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -103,22 +121,43 @@ func processAttestations(
 	return nil
 }
 
+func newAttestation(blockID uint64, a *gateway.Attestation) *orm.Attestation {
+	bits := strings.Trim(
+		strings.Join(strings.Fields(fmt.Sprint(a.AggregationBits)), ","),
+		"[]",
+	)
+
+	return &orm.Attestation{
+		BlockID:         blockID,
+		CommitteeIndex:  a.CommitteeIndex,
+		AggregationBits: bits,
+		SourceEpoch:     a.Source.Epoch,
+		SourceHash:      a.Source.Hash,
+		TargetEpoch:     a.Target.Epoch,
+		TargetHash:      a.Target.Hash,
+		Signature:       a.Signature,
+	}
+}
+
 func createBlock(dbTx *gorm.DB, block *gateway.BlockResp) (uint64, error) {
-	b := &orm.Block{
+	b := newBlock(block)
+	if err := dbTx.Model(&orm.Block{}).Create(b).Error; err != nil {
+		return 0, err
+	}
+
+	return b.ID, nil
+}
+
+func newBlock(block *gateway.BlockResp) *orm.Block {
+	return &orm.Block{
 		Slot:              block.Slot,
 		Hash:              block.BlockHash,
 		ParentHash:        block.ParentHash,
 		StateHash:         block.StateHash,
-		ProposalIndex:     block.ProposerIndex,
+		ProposalIndex:     &block.ProposerIndex,
 		ProposalSignature: block.ProposerSignature,
 		RandaoReveal:      block.RandaoReveal,
 		Graffiti:          block.Graffiti,
 		Timestamp:         block.Timestamp,
 	}
-
-	if err := dbTx.Model(&orm.Block{}).Create(b).Error; err != nil {
-		return 0, err
-	}
-
-	return b.ID, nil
 }
@@ -0,0 +1,179 @@
+package indexer
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// terminalContractStatuses are the storage contract statuses a contract
+// never leaves, so the per epoch refresh skips them.
+var terminalContractStatuses = []int32{
+	int32(pbc.StorageStatus_STORAGE_INVALID),
+	int32(pbc.StorageStatus_ZOMBIE),
+}
+
+// refreshStorageContract reloads the storage contract from the node at
+// the given block and records a status transition if the status
+// changed. The per epoch refresh and the verifier journal the changes
+// with the indexed head block, see headJournal, and pass a nil journal
+// only before the first block.
+func refreshStorageContract(
+	ctx context.Context,
+	node chain.NodeClient,
+	dbTx *gorm.DB,
+	j *journal,
+	sc *orm.StorageContract,
+	blockHash string,
+	slot uint64,
+	txID uint64,
+) error {
+	resp, err := node.StorageContract(
+		ctx,
+		sc.CommitTransaction.Hash,
+		blockHash,
+	)
+	if err != nil {
+		return err
+	}
+
+	updates := make(map[string]interface{})
+	status := pbc.StorageStatus_value[resp.Status]
+	if status != sc.Status {
+		updates["status"] = status
+	}
+
+	if resp.Auditor != "" && sc.AuditorID == 0 {
+		auditorID, err := getAccountIDByPublicKey(dbTx, resp.Auditor)
+		if err != nil {
+			return err
+		}
+
+		updates["auditor_id"] = auditorID
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if j != nil {
+		if err := j.storageContract(sc.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTx.Model(&orm.StorageContract{}).
+		Where("id = ?", sc.ID).
+		Updates(updates).
+		Error; err != nil {
+		return err
+	}
+
+	if status == sc.Status {
+		return nil
+	}
+
+	return createContractStatus(dbTx, j, sc.ID, sc.Status, status, slot, txID)
+}
+
+func createContractStatus(
+	dbTx *gorm.DB,
+	j *journal,
+	contractID uint64,
+	prev int32,
+	status int32,
+	slot uint64,
+	txID uint64,
+) error {
+	scs := &orm.StorageContractStatus{
+		ContractID:    contractID,
+		PrevStatus:    prev,
+		Status:        status,
+		Slot:          slot,
+		TransactionID: txID,
+	}
+	if err := dbTx.Model(&orm.StorageContractStatus{}).
+		Create(scs).
+		Error; err != nil {
+		return err
+	}
+
+	if err := publishContractStatusEvent(
+		dbTx,
+		contractID,
+		prev,
+		status,
+		slot,
+		txID,
+	); err != nil {
+		return err
+	}
+
+	if j == nil {
+		return nil
+	}
+
+	return j.created(entityContractStatus, scs.ID)
+}
+
+// updateAllStorageContracts refreshes every storage contract not in a
+// terminal status at the given block, which catches the transitions no
+// transaction triggers, such as expiry at the end slot or slashing for
+// missed proofs. The changes are journaled with j, so rolling back the
+// head block reverts them.
+func updateAllStorageContracts(
+	ctx context.Context,
+	node chain.NodeClient,
+	dbTx *gorm.DB,
+	j *journal,
+	slot uint64,
+	blockHash string,
+) error {
+	lastID := uint64(0)
+	for {
+		scs := make([]*orm.StorageContract, 0)
+		if err := dbTx.Model(&orm.StorageContract{}).
+			Preload("CommitTransaction").
+			Where("id > ?", lastID).
+			Where("status not in ?", terminalContractStatuses).
+			Order("id asc").
+			Limit(defaultPageSize).
+			Find(&scs).
+			Error; err != nil {
+			return err
+		}
+
+		for _, sc := range scs {
+			if err := refreshStorageContract(
+				ctx,
+				node,
+				dbTx,
+				j,
+				sc,
+				blockHash,
+				slot,
+				0,
+			); err != nil {
+				if chain.IsUnavailable(err) {
+					return err
+				}
+
+				log.Warn("Error refreshing storage contract",
+					"commit_tx_hash", sc.CommitTransaction.Hash,
+					"error", err,
+				)
+			}
+		}
+
+		if len(scs) < defaultPageSize {
+			return nil
+		}
+		lastID = scs[len(scs)-1].ID
+	}
+}
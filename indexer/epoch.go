@@ -2,6 +2,7 @@ package indexer
 
 import (
 	"context"
+	"math"
 	"strings"
 
 	"gorm.io/gorm"
@@ -15,22 +16,49 @@ import (
 
 const defaultPageSize = 100
 
+// dbEpoch caps an epoch to the signed 64-bit range of the postgres and
+// sqlite integer columns, which only affects FarFutureEpoch.
+func dbEpoch(epoch uint64) uint64 {
+	if epoch > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return epoch
+}
+
 func processEpoch(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	slot uint64,
+	blockHash string,
 ) error {
-	if err := updateAllValidators(ctx, node, dbTx); err != nil {
+	j, err := headJournal(dbTx, blockHash, slot)
+	if err != nil {
 		return err
 	}
 
-	return updateAllAuditors(ctx, node, dbTx)
+	if err := updateAllValidators(ctx, node, dbTx, j, slot); err != nil {
+		return err
+	}
+
+	if err := updateAllAuditors(ctx, node, dbTx, j, slot); err != nil {
+		return err
+	}
+
+	if err := updateEpochDuties(ctx, node, dbTx, slot); err != nil {
+		return err
+	}
+
+	return updateAllStorageContracts(ctx, node, dbTx, j, slot, blockHash)
 }
 
 func updateAllValidators(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
+	slot uint64,
 ) error {
 	nextPageToken := ""
 	for ok := true; ok; ok = nextPageToken != "" {
@@ -44,6 +72,8 @@ func updateAllValidators(
 				ctx,
 				node,
 				dbTx,
+				j,
+				slot,
 				v.PublicKey,
 			); err != nil {
 				return err
@@ -59,8 +89,8 @@ func updateAllValidators(
 				Updates(
 					map[string]interface{}{
 						"status":           pbc.ValidatorStatus_value[v.Status],
-						"activation_epoch": v.ActivationEpoch,
-						"exit_epoch":       v.ExitEpoch,
+						"activation_epoch": dbEpoch(v.ActivationEpoch),
+						"exit_epoch":       dbEpoch(v.ExitEpoch),
 					},
 				).Error; err != nil {
 				return err
@@ -75,8 +105,10 @@ func updateAllValidators(
 
 func updateAllAuditors(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
+	slot uint64,
 ) error {
 	nextPageToken := ""
 	for ok := true; ok; ok = nextPageToken != "" {
@@ -94,6 +126,8 @@ func updateAllAuditors(
 				ctx,
 				node,
 				dbTx,
+				j,
+				slot,
 				a.PublicKey,
 			); err != nil {
 				return err
@@ -109,8 +143,8 @@ func updateAllAuditors(
 				Updates(
 					map[string]interface{}{
 						"status":           pbc.AuditorStatus_value[a.Status],
-						"activation_epoch": a.ActivationEpoch,
-						"exit_epoch":       a.ExitEpoch,
+						"activation_epoch": dbEpoch(a.ActivationEpoch),
+						"exit_epoch":       dbEpoch(a.ExitEpoch),
 					},
 				).Error; err != nil {
 				return err
@@ -123,19 +157,81 @@ func updateAllAuditors(
 	return nil
 }
 
+// resetAccountBalance reconciles the account balance with the node,
+// which picks up changes not derived from transactions such as rewards
+// and penalties. The difference is written to the account ledger. The
+// change is journaled with the indexed head block, see headJournal, so
+// rolling the block back reverts it.
 func resetAccountBalance(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
+	slot uint64,
 	pk string,
 ) error {
-	account, err := node.Account(ctx, pk)
+	remote, err := node.Account(ctx, pk)
 	if err != nil {
 		return err
 	}
 
-	return dbTx.Model(&orm.Account{}).
-		Where("public_key", pk).
-		Update("balance", account.Balance).
+	account := &orm.Account{}
+	if err := dbTx.Model(&orm.Account{}).
+		Where("public_key = ?", pk).
+		First(account).
+		Error; err != nil {
+		return err
+	}
+
+	if remote.Balance == account.Balance {
+		return nil
+	}
+
+	delta := int64(remote.Balance) - int64(account.Balance)
+	if j != nil {
+		if err := j.accountByID(account.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTx.Model(&orm.Account{}).
+		Where("id = ?", account.ID).
+		Update("balance", remote.Balance).
+		Error; err != nil {
+		return err
+	}
+
+	if j != nil {
+		return j.ledger(account.ID, orm.LedgerReconciliation, "", delta)
+	}
+
+	return dbTx.Model(&orm.AccountLedger{}).
+		Create(&orm.AccountLedger{
+			AccountID: account.ID,
+			Kind:      orm.LedgerReconciliation,
+			Slot:      slot,
+			Delta:     delta,
+		}).
 		Error
 }
+
+// headJournal returns the journal of the indexed head block for the
+// state reconciled with the node at slot, nil before the first block.
+// Its ledger rows keep the reconciled slot, which may be past the slot
+// of the block.
+func headJournal(dbTx *gorm.DB, hash string, slot uint64) (*journal, error) {
+	bs := make([]*orm.Block, 0)
+	if err := dbTx.Model(&orm.Block{}).
+		Where("hash = ?", hash).
+		Limit(1).
+		Find(&bs).
+		Error; err != nil {
+		return nil, err
+	}
+
+	if len(bs) == 0 {
+		return nil, nil
+	}
+
+	return newJournal(dbTx, bs[0].ID, slot), nil
+}
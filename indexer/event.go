@@ -0,0 +1,194 @@
+package indexer
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// eventRetention is how long published events are kept for clients
+// resuming a stream.
+const eventRetention = 24 * time.Hour
+
+// publishEvent appends an event to the outbox. It must run in the DB
+// transaction committing the data the event announces.
+func publishEvent(dbTx *gorm.DB, kind string, slot uint64, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return dbTx.Model(&orm.Event{}).
+		Create(&orm.Event{
+			Kind:    kind,
+			Slot:    slot,
+			Payload: b,
+		}).
+		Error
+}
+
+// publishBlockEvents publishes the block and its transactions.
+func publishBlockEvents(
+	dbTx *gorm.DB,
+	blockID uint64,
+	block *gateway.BlockResp,
+) error {
+	if err := publishEvent(dbTx, orm.EventBlock, block.Slot, &orm.BlockEvent{
+		Slot:          block.Slot,
+		Hash:          block.BlockHash,
+		ParentHash:    block.ParentHash,
+		ProposerIndex: block.ProposerIndex,
+		TxCount:       len(block.Txs),
+		Timestamp:     block.Timestamp,
+	}); err != nil {
+		return err
+	}
+
+	txs := make([]*orm.Transaction, 0)
+	if err := dbTx.Model(&orm.Transaction{}).
+		Preload("ToAccount").
+		Where("block_id = ?", blockID).
+		Order("position asc").
+		Find(&txs).
+		Error; err != nil {
+		return err
+	}
+
+	for _, tx := range txs {
+		to := ""
+		if tx.ToAccount != nil {
+			to = tx.ToAccount.PublicKey
+		}
+
+		accounts := make([]string, 0)
+		if err := dbTx.Model(&orm.TransactionParticipant{}).
+			Joins("join accounts on accounts.id = transaction_participants.account_id").
+			Where("transaction_participants.transaction_id = ?", tx.ID).
+			Distinct().
+			Pluck("accounts.public_key", &accounts).
+			Error; err != nil {
+			return err
+		}
+
+		if err := publishEvent(
+			dbTx,
+			orm.EventTransaction,
+			block.Slot,
+			&orm.TransactionEvent{
+				Hash:     tx.Hash,
+				Slot:     block.Slot,
+				Position: tx.Position,
+				Type:     block.Txs[tx.Position].Type,
+				From:     block.Txs[tx.Position].From,
+				To:       to,
+				Accounts: accounts,
+				Amount:   tx.Amount,
+				GasPrice: tx.GasPrice,
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// publishContractStatusEvent publishes a status transition of a storage
+// contract.
+func publishContractStatusEvent(
+	dbTx *gorm.DB,
+	contractID uint64,
+	prev int32,
+	status int32,
+	slot uint64,
+	txID uint64,
+) error {
+	sc := &orm.StorageContract{}
+	if err := dbTx.Model(&orm.StorageContract{}).
+		Preload("Owner").
+		Preload("Depot").
+		Preload("Auditor").
+		Preload("CommitTransaction").
+		Where("id = ?", contractID).
+		First(sc).
+		Error; err != nil {
+		return err
+	}
+
+	e := &orm.ContractStatusEvent{
+		Hash:       sc.CommitTransaction.Hash,
+		Owner:      sc.Owner.PublicKey,
+		Depot:      sc.Depot.PublicKey,
+		PrevStatus: pbc.StorageStatus_name[prev],
+		Status:     pbc.StorageStatus_name[status],
+		Slot:       slot,
+	}
+	if sc.Auditor != nil {
+		e.Auditor = sc.Auditor.PublicKey
+	}
+
+	if txID != 0 {
+		if err := dbTx.Model(&orm.Transaction{}).
+			Where("id = ?", txID).
+			Pluck("hash", &e.TxHash).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	return publishEvent(dbTx, orm.EventContractStatus, slot, e)
+}
+
+// pruneEvents removes the events past the retention. Events the webhook
+// worker hasn't turned into deliveries yet are kept whatever their age,
+// so a stalled worker holds the pruning back instead of losing them.
+func pruneEvents(dbTx *gorm.DB) error {
+	cursors := make([]uint64, 0)
+	if err := dbTx.Model(&orm.WebhookCursor{}).
+		Pluck("last_event_id", &cursors).
+		Error; err != nil {
+		return err
+	}
+
+	expired := time.Now().Add(-eventRetention)
+	if len(cursors) == 0 {
+		heldEvents.Set(0)
+		return dbTx.Where("created_at < ?", expired).
+			Delete(&orm.Event{}).
+			Error
+	}
+
+	last := cursors[0]
+	for _, c := range cursors[1:] {
+		if c < last {
+			last = c
+		}
+	}
+
+	held := int64(0)
+	if err := dbTx.Model(&orm.Event{}).
+		Where("created_at < ? and id > ?", expired, last).
+		Count(&held).
+		Error; err != nil {
+		return err
+	}
+
+	heldEvents.Set(float64(held))
+	if held > 0 {
+		log.Warn("Keeping expired events not read by the webhook worker",
+			"events", held,
+			"last_event_id", last,
+		)
+	}
+
+	return dbTx.Where("created_at < ? and id <= ?", expired, last).
+		Delete(&orm.Event{}).
+		Error
+}
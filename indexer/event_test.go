@@ -0,0 +1,127 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestPruneEvents(t *testing.T) {
+	cases := []struct {
+		name    string
+		cursors []uint64
+		kept    []uint64
+	}{
+		{
+			name: "without webhook cursor",
+			kept: []uint64{4},
+		},
+		{
+			name:    "cursor past the expired events",
+			cursors: []uint64{4},
+			kept:    []uint64{4},
+		},
+		{
+			name:    "cursor behind the expired events",
+			cursors: []uint64{1},
+			kept:    []uint64{2, 3, 4},
+		},
+		{
+			name:    "slowest cursor",
+			cursors: []uint64{3, 2},
+			kept:    []uint64{3, 4},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := newDB(t)
+
+			// Events 1 to 3 are past the retention.
+			expired := time.Now().Add(-eventRetention - time.Hour)
+			for _, created := range []time.Time{expired, expired, expired, time.Now()} {
+				if err := db.Create(&orm.Event{
+					Kind:      orm.EventBlock,
+					Payload:   []byte("{}"),
+					CreatedAt: created,
+				}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, last := range c.cursors {
+				if err := db.Create(&orm.WebhookCursor{
+					LastEventID: last,
+				}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := pruneEvents(db); err != nil {
+				t.Fatal(err)
+			}
+
+			kept := make([]uint64, 0)
+			if err := db.Model(&orm.Event{}).
+				Order("id asc").
+				Pluck("id", &kept).
+				Error; err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(kept, c.kept) {
+				t.Errorf("kept events %v, want %v", kept, c.kept)
+			}
+		})
+	}
+}
+
+func TestEventKinds(t *testing.T) {
+	kinds := []string{
+		orm.EventBlock,
+		orm.EventTransaction,
+		orm.EventFinality,
+		orm.EventReorg,
+		orm.EventContractStatus,
+		orm.EventMissedAttestation,
+	}
+
+	db := newDB(t)
+
+	// SQLite doesn't enforce the width, check the declared one, which
+	// every dialect shares.
+	cts, err := db.Migrator().ColumnTypes(&orm.Event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	width := int64(0)
+	for _, ct := range cts {
+		if ct.Name() == "kind" {
+			width, _ = ct.Length()
+		}
+	}
+
+	for _, kind := range kinds {
+		if int64(len(kind)) > width {
+			t.Errorf("events.kind width %d, %s is %d long", width, kind, len(kind))
+		}
+
+		if err := publishEvent(db, kind, 1, struct{}{}); err != nil {
+			t.Fatalf("publishing a %s event: %v", kind, err)
+		}
+	}
+
+	stored := make([]string, 0)
+	if err := db.Model(&orm.Event{}).
+		Order("id asc").
+		Pluck("kind", &stored).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored, kinds) {
+		t.Errorf("stored kinds %v, want %v", stored, kinds)
+	}
+}
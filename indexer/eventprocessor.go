@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/photon-storage/go-common/log"
+	"github.com/photon-storage/go-photon/chain/gateway"
 	"github.com/photon-storage/go-photon/crypto/sha256"
 	"github.com/photon-storage/go-photon/sak/time/slots"
 	pbc "github.com/photon-storage/photon-proto/consensus"
@@ -15,35 +16,49 @@ import (
 	"github.com/photon-storage/photon-explorer/database/orm"
 )
 
+// Config defines the indexer configuration.
+type Config struct {
+	// RefreshInterval is the polling interval in seconds.
+	RefreshInterval uint64 `yaml:"refresh_interval"`
+	// PrefetchDepth is the number of upcoming slots fetched concurrently.
+	PrefetchDepth int `yaml:"prefetch_depth"`
+	// MaxReorgDepth is the max number of blocks unwound by a single reorg,
+	// 0 means unlimited. Reorgs never cross the finalized slot.
+	MaxReorgDepth uint64 `yaml:"max_reorg_depth"`
+}
+
 // EventProcessor is the processor for synchronizing photon change events.
 type EventProcessor struct {
-	ctx             context.Context
-	cancel          context.CancelFunc
-	refreshInterval uint64
-	db              *gorm.DB
-	node            *chain.NodeClient
+	ctx      context.Context
+	cancel   context.CancelFunc
+	cfg      Config
+	db       *gorm.DB
+	node     chain.NodeClient
+	prefetch *prefetcher
+	paused   bool
 }
 
 // NewEventProcessor returns the new instance of EventProcessor.
 func NewEventProcessor(
 	ctx context.Context,
-	refreshInterval uint64,
-	nodeEndpoint string,
+	cfg Config,
+	node chain.NodeClient,
 	db *gorm.DB,
 ) *EventProcessor {
 	ctx, cancel := context.WithCancel(ctx)
 	return &EventProcessor{
-		ctx:             ctx,
-		cancel:          cancel,
-		refreshInterval: refreshInterval,
-		db:              db,
-		node:            chain.NewNodeClient(nodeEndpoint),
+		ctx:      ctx,
+		cancel:   cancel,
+		cfg:      cfg,
+		db:       db,
+		node:     node,
+		prefetch: newPrefetcher(node, cfg.PrefetchDepth),
 	}
 }
 
 // Run executing the timing task of processing chain data.
 func (e *EventProcessor) Run() {
-	ticker := time.NewTicker(time.Duration(e.refreshInterval) * time.Second)
+	ticker := time.NewTicker(time.Duration(e.cfg.RefreshInterval) * time.Second)
 	defer ticker.Stop()
 
 	nextSlot, currentHash := uint64(0), sha256.Zero.Hex()
@@ -65,7 +80,7 @@ func (e *EventProcessor) Run() {
 			break
 		}
 
-		if err := e.db.Transaction(func(dbTx *gorm.DB) error {
+		if err := e.transaction("genesis", func(dbTx *gorm.DB) error {
 			if err := dbTx.Model(&orm.ChainStatus{}).
 				Create(&orm.ChainStatus{
 					NextSlot:    0,
@@ -94,13 +109,21 @@ func (e *EventProcessor) Run() {
 
 		cs, err := e.node.ChainStatus(e.ctx)
 		if err != nil {
+			if chain.IsUnavailable(err) {
+				e.pause(err)
+				continue
+			}
+
 			log.Error("Error requesting chain status from photon node",
 				"error", err,
 			)
 			continue
 		}
 
+		e.resume()
+
 		headSlot := cs.Best.Slot
+		reportHead(nextSlot, headSlot)
 		if nextSlot > headSlot {
 			continue
 		}
@@ -114,11 +137,14 @@ func (e *EventProcessor) Run() {
 
 			}
 
-			if err := e.db.Transaction(func(dbTx *gorm.DB) error {
+			prevSlot, start := nextSlot, time.Now()
+			e.prefetch.schedule(e.ctx, nextSlot, headSlot)
+			if err := e.transaction("slot", func(dbTx *gorm.DB) error {
 				hash, slot, err := processSlot(
 					e.ctx,
-					e.node,
+					e.prefetch,
 					dbTx,
+					e.cfg.MaxReorgDepth,
 					currentHash,
 					nextSlot,
 				)
@@ -134,23 +160,74 @@ func (e *EventProcessor) Run() {
 					); err != nil {
 						return err
 					}
+
+					if err := pruneJournal(dbTx, cs.Finalized.Slot); err != nil {
+						return err
+					}
+
+					if err := publishEvent(
+						dbTx,
+						orm.EventFinality,
+						cs.Finalized.Slot,
+						&orm.FinalityEvent{
+							Slot: cs.Finalized.Slot,
+							Hash: cs.Finalized.Hash,
+						},
+					); err != nil {
+						return err
+					}
+
+					if err := pruneEvents(dbTx); err != nil {
+						return err
+					}
 				}
 
 				currentHash = hash
 				nextSlot = slot
 				return nil
 			}); err != nil {
-				log.Error("Error processing chain events",
-					"slot", nextSlot,
-					"current_hash", currentHash,
-					"error", err,
-				)
+				if chain.IsUnavailable(err) {
+					e.pause(err)
+				} else {
+					log.Error("Error processing chain events",
+						"slot", nextSlot,
+						"current_hash", currentHash,
+						"error", err,
+					)
+				}
+
+				// The slot transaction is rolled back as a whole, restore
+				// the cursor from what was actually committed.
+				if ns, ch, err := chainStatus(e.db); err == nil {
+					nextSlot, currentHash = ns, ch
+				}
+				e.prefetch.reset()
 				break
 			}
 
+			observeSince(slotDuration, start)
+			lastIndexedTime.SetToCurrentTime()
+			reportHead(nextSlot, headSlot)
+
+			if nextSlot <= prevSlot {
+				// Rolled back, the prefetched slots may be on the
+				// abandoned branch.
+				e.prefetch.reset()
+				reorgCount.Inc()
+				reorgDepth.Observe(float64(prevSlot - nextSlot))
+			} else {
+				e.prefetch.advance(nextSlot)
+			}
+
 			if slots.IsEpochStart(pbc.Slot(nextSlot - 1)) {
-				if err := e.db.Transaction(func(dbTx *gorm.DB) error {
-					return processEpoch(e.ctx, e.node, dbTx)
+				if err := e.transaction("epoch", func(dbTx *gorm.DB) error {
+					return processEpoch(
+						e.ctx,
+						e.node,
+						dbTx,
+						nextSlot-1,
+						currentHash,
+					)
 				}); err != nil {
 					log.Error("Error processing epoch",
 						"slot", nextSlot-1,
@@ -162,6 +239,38 @@ func (e *EventProcessor) Run() {
 	}
 }
 
+// pause stops issuing node calls until the next tick once the node is
+// unreachable or the client circuit breaker is open.
+func (e *EventProcessor) pause(err error) {
+	if e.paused {
+		return
+	}
+
+	e.paused = true
+	nodeAvailable.Set(0)
+	log.Warn("Photon node unavailable, pausing indexing", "error", err)
+}
+
+func (e *EventProcessor) resume() {
+	nodeAvailable.Set(1)
+	if !e.paused {
+		return
+	}
+
+	e.paused = false
+	log.Info("Photon node available again, resuming indexing")
+}
+
+// transaction runs fn in a database transaction, reporting its duration
+// under op.
+func (e *EventProcessor) transaction(
+	op string,
+	fn func(dbTx *gorm.DB) error,
+) error {
+	defer observeSince(dbTransactionDuration.WithLabelValues(op), time.Now())
+	return e.db.Transaction(fn)
+}
+
 // Stop exits event processor
 func (e *EventProcessor) Stop() {
 	e.cancel()
@@ -169,8 +278,9 @@ func (e *EventProcessor) Stop() {
 
 func processSlot(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	maxReorgDepth uint64,
 	hash string,
 	slot uint64,
 ) (string, uint64, error) {
@@ -180,13 +290,18 @@ func processSlot(
 	}
 
 	if nextBlock.BlockHash == sha256.Zero.Hex() {
+		// The scheduled proposer of an empty slot is kept as a missed
+		// proposal when the node reports it.
 		if err := dbTx.Model(&orm.Block{}).
-			Create(&orm.Block{Slot: nextBlock.Slot}).
+			Create(&orm.Block{
+				Slot:          nextBlock.Slot,
+				ProposalIndex: missedProposer(nextBlock),
+			}).
 			Error; err != nil {
 			return "", 0, err
 		}
 
-		return hash, slot + 1, updateChainSlot(dbTx, slot)
+		return hash, slot + 1, updateChainSlot(dbTx, slot+1)
 	}
 
 	if nextBlock.ParentHash != hash {
@@ -195,12 +310,14 @@ func processSlot(
 			"current block hash", hash,
 		)
 
-		rb, err := node.BlockByHash(ctx, hash)
-		if err != nil {
-			return "", 0, err
-		}
-
-		return rollbackBlock(ctx, node, dbTx, rb)
+		return processReorg(
+			ctx,
+			node,
+			dbTx,
+			maxReorgDepth,
+			hash,
+			nextBlock,
+		)
 	}
 
 	return processBlock(ctx, node, dbTx, nextBlock)
@@ -241,3 +358,16 @@ func updateChainSlot(db *gorm.DB, slot uint64) error {
 		Update("next_slot", slot).
 		Error
 }
+
+// missedProposer returns the proposer the node reports for an empty slot,
+// nil when the node leaves it unset. An unset proposer reads as 0, so a
+// zero proposer is unknown, which leaves the missed proposals of
+// validator 0 unattributed rather than charging it with every empty slot.
+func missedProposer(b *gateway.BlockResp) *uint64 {
+	if b.ProposerIndex == 0 {
+		return nil
+	}
+
+	index := b.ProposerIndex
+	return &index
+}
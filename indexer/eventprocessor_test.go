@@ -0,0 +1,47 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestProcessEmptySlot(t *testing.T) {
+	cases := []struct {
+		name string
+		opts []chaintest.BlockOption
+		want string
+	}{
+		{
+			name: "reported proposer",
+			opts: []chaintest.BlockOption{chaintest.WithProposer(3)},
+			want: "3",
+		},
+		{
+			name: "unset proposer",
+			want: "unknown",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := newDB(t)
+			gw := chaintest.NewGateway()
+			defer gw.Close()
+
+			gw.AddBlock()
+			gw.AddEmptySlot(c.opts...)
+			indexSlots(t, gw.Client(), db)
+
+			b := &orm.Block{}
+			if err := db.Where("slot = ?", 1).First(b).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			if got := proposerString(b.ProposalIndex); got != c.want {
+				t.Errorf("proposal index %s, want %s", got, c.want)
+			}
+		})
+	}
+}
@@ -39,7 +39,7 @@ func processGenesisValidators(dbTx *gorm.DB) error {
 			Deposit:         vm[pk],
 			Status:          int32(pbc.ValidatorStatus_VALIDATOR_ACTIVE),
 			ActivationEpoch: 0,
-			ExitEpoch:       uint64(config.Consensus().FarFutureEpoch),
+			ExitEpoch:       dbEpoch(uint64(config.Consensus().FarFutureEpoch)),
 		}
 
 		if err := dbTx.Model(&orm.Validator{}).Create(v).Error; err != nil {
@@ -65,6 +65,16 @@ func processGenesisBalances(dbTx *gorm.DB) error {
 		if err := dbTx.Model(&orm.Account{}).Create(account).Error; err != nil {
 			return err
 		}
+
+		if err := dbTx.Model(&orm.AccountLedger{}).
+			Create(&orm.AccountLedger{
+				AccountID: account.ID,
+				Kind:      orm.LedgerGenesis,
+				Delta:     int64(account.Balance),
+			}).
+			Error; err != nil {
+			return err
+		}
 	}
 
 	return nil
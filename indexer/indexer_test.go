@@ -0,0 +1,186 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	"github.com/photon-storage/go-photon/crypto/sha256"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/database/sqlite"
+)
+
+// Public keys of the test accounts queried from the node, which checks
+// they are valid BLS keys: the G1 generator and its negation.
+const (
+	alicePK = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	bobPK   = "b7f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Create(&orm.ChainStatus{
+		CurrentHash: sha256.Zero.Hex(),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// createAccount inserts an account funded by a genesis ledger row.
+func createAccount(t *testing.T, db *gorm.DB, pk string, balance uint64) {
+	a := &orm.Account{PublicKey: pk, Balance: balance}
+	if err := db.Create(a).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Create(&orm.AccountLedger{
+		AccountID: a.ID,
+		Kind:      orm.LedgerGenesis,
+		Delta:     int64(balance),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// indexSlots processes the slots up to the node head one DB transaction
+// at a time, the way EventProcessor.Run does, without the epoch work.
+func indexSlots(t *testing.T, node chain.NodeClient, db *gorm.DB) {
+	ctx := context.Background()
+	cs, err := node.ChainStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		nextSlot, currentHash, err := chainStatus(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if nextSlot > cs.Best.Slot {
+			return
+		}
+
+		if err := db.Transaction(func(dbTx *gorm.DB) error {
+			_, _, err := processSlot(ctx, node, dbTx, 0, currentHash, nextSlot)
+			return err
+		}); err != nil {
+			t.Fatalf("slot %d: %v", nextSlot, err)
+		}
+	}
+}
+
+// count returns the number of rows of the model matching the query.
+func count(t *testing.T, db *gorm.DB, model any, query string, args ...any) int64 {
+	n := int64(0)
+	if err := db.Model(model).Where(query, args...).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+// checkLedger fails unless the ledger of every account sums up to its
+// balance.
+func checkLedger(t *testing.T, db *gorm.DB) {
+	accounts := make([]*orm.Account, 0)
+	if err := db.Find(&accounts).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range accounts {
+		sum := int64(0)
+		if err := db.Model(&orm.AccountLedger{}).
+			Select("coalesce(sum(delta), 0)").
+			Where("account_id = ?", a.ID).
+			Scan(&sum).
+			Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if sum != int64(a.Balance) {
+			t.Errorf("account %s ledger sum = %d, balance %d",
+				a.PublicKey, sum, a.Balance)
+		}
+	}
+}
+
+// alloc sets the pointer to a new value and returns it, which fills the
+// typed payloads of a gateway transaction.
+func alloc[T any](p **T) *T {
+	*p = new(T)
+	return *p
+}
+
+func transferTx(hash string, from string, to string, amount uint64) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_BALANCE_TRANSFER.String(),
+		From:     from,
+		GasPrice: 1,
+	}
+	bt := alloc(&tx.BalanceTransfer)
+	bt.To = to
+	bt.Amount = amount
+
+	return tx
+}
+
+func commitTx(hash string, owner string, depot string) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_OBJECT_COMMIT.String(),
+		From:     owner,
+		GasPrice: 1,
+	}
+	oc := alloc(&tx.ObjectCommit)
+	oc.Owner = owner
+	oc.Depot = depot
+	oc.Hash = "object/" + hash
+
+	return tx
+}
+
+func porTx(hash string, depot string, commitTxHash string) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_OBJECT_POR.String(),
+		From:     depot,
+		GasPrice: 1,
+	}
+	alloc(&tx.ObjectPoR).CommitTxHash = commitTxHash
+
+	return tx
+}
+
+func auditTx(hash string, auditor string, depot string, commitTxHash string) *gateway.Tx {
+	tx := &gateway.Tx{
+		TxHash:   hash,
+		Type:     pbc.TxType_OBJECT_AUDIT.String(),
+		From:     auditor,
+		GasPrice: 1,
+	}
+	oa := alloc(&tx.ObjectAudit)
+	oa.CommitTxHash = commitTxHash
+	oa.Hash = "object/" + commitTxHash
+	oa.Auditor = auditor
+	oa.Depot = depot
+
+	return tx
+}
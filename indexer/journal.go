@@ -0,0 +1,269 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+const (
+	entityAccount             = "accounts"
+	entityValidator           = "validators"
+	entityAuditor             = "auditors"
+	entityStorageContract     = "storage_contracts"
+	entityTransactionContract = "transaction_contracts"
+	entityStorageProof        = "storage_proofs"
+	entityContractStatus      = "storage_contract_statuses"
+	entityParticipation       = "validator_participations"
+)
+
+// journal records the state a block changes. Before a row is modified
+// for the first time in the block its pre-state is stored, and rows the
+// block inserts are marked as created. Rolling the block back applies
+// the entries in reverse order. Balance changes are also written to the
+// account ledger.
+type journal struct {
+	dbTx    *gorm.DB
+	blockID uint64
+	slot    uint64
+	seen    map[string]bool
+}
+
+func newJournal(dbTx *gorm.DB, blockID uint64, slot uint64) *journal {
+	return &journal{
+		dbTx:    dbTx,
+		blockID: blockID,
+		slot:    slot,
+		seen:    make(map[string]bool),
+	}
+}
+
+// accountByID snapshots the account with the id.
+func (j *journal) accountByID(id uint64) error {
+	a := &orm.Account{}
+	if err := j.dbTx.Model(&orm.Account{}).
+		Where("id = ?", id).
+		First(a).
+		Error; err != nil {
+		return err
+	}
+
+	return j.record(entityAccount, a.ID, a)
+}
+
+// validator snapshots the validators matching the query.
+func (j *journal) validator(query any, args ...any) error {
+	vs := make([]*orm.Validator, 0)
+	if err := j.dbTx.Model(&orm.Validator{}).
+		Where(query, args...).
+		Find(&vs).
+		Error; err != nil {
+		return err
+	}
+
+	for _, v := range vs {
+		if err := j.record(entityValidator, v.ID, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storageContract snapshots the storage contract with the id.
+func (j *journal) storageContract(id uint64) error {
+	sc := &orm.StorageContract{}
+	if err := j.dbTx.Model(&orm.StorageContract{}).
+		Where("id = ?", id).
+		First(sc).
+		Error; err != nil {
+		return err
+	}
+
+	return j.record(entityStorageContract, sc.ID, sc)
+}
+
+// ledger records a balance change of the account made by the block.
+func (j *journal) ledger(
+	accountID uint64,
+	kind string,
+	txHash string,
+	delta int64,
+) error {
+	if delta == 0 {
+		return nil
+	}
+
+	return j.dbTx.Model(&orm.AccountLedger{}).Create(&orm.AccountLedger{
+		AccountID: accountID,
+		BlockID:   j.blockID,
+		Kind:      kind,
+		Slot:      j.slot,
+		TxHash:    txHash,
+		Delta:     delta,
+	}).Error
+}
+
+// auditor snapshots the auditors matching the query.
+func (j *journal) auditor(query any, args ...any) error {
+	as := make([]*orm.Auditor, 0)
+	if err := j.dbTx.Model(&orm.Auditor{}).
+		Where(query, args...).
+		Find(&as).
+		Error; err != nil {
+		return err
+	}
+
+	for _, a := range as {
+		if err := j.record(entityAuditor, a.ID, a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// created marks a row inserted by the block.
+func (j *journal) created(entity string, id uint64) error {
+	j.seen[journalKey(entity, id)] = true
+	return j.dbTx.Model(&orm.StateJournal{}).Create(&orm.StateJournal{
+		BlockID:  j.blockID,
+		Entity:   entity,
+		EntityID: id,
+		Created:  true,
+	}).Error
+}
+
+func (j *journal) record(entity string, id uint64, row any) error {
+	key := journalKey(entity, id)
+	if j.seen[key] {
+		return nil
+	}
+	j.seen[key] = true
+
+	pre, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	return j.dbTx.Model(&orm.StateJournal{}).Create(&orm.StateJournal{
+		BlockID:  j.blockID,
+		Entity:   entity,
+		EntityID: id,
+		PreState: pre,
+	}).Error
+}
+
+func journalKey(entity string, id uint64) string {
+	return fmt.Sprintf("%s/%d", entity, id)
+}
+
+// revertJournal applies the inverse of every journal entry of the block
+// in reverse order and removes the entries. The accounts created by the
+// block are still referenced by its transactions, so they are returned
+// for the caller to delete once the transactions are gone.
+func revertJournal(dbTx *gorm.DB, blockID uint64) ([]uint64, error) {
+	entries := make([]*orm.StateJournal, 0)
+	if err := dbTx.Model(&orm.StateJournal{}).
+		Where("block_id = ?", blockID).
+		Order("id desc").
+		Find(&entries).
+		Error; err != nil {
+		return nil, err
+	}
+
+	var accounts []uint64
+	for _, e := range entries {
+		row, err := journalRow(e.Entity)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Created && e.Entity == entityAccount {
+			accounts = append(accounts, e.EntityID)
+			continue
+		}
+
+		if e.Created {
+			if err := dbTx.Unscoped().
+				Where("id = ?", e.EntityID).
+				Delete(row).
+				Error; err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if err := json.Unmarshal(e.PreState, row); err != nil {
+			return nil, err
+		}
+
+		if err := restoreRow(dbTx, row); err != nil {
+			return nil, err
+		}
+	}
+
+	return accounts, dbTx.Where("block_id = ?", blockID).
+		Delete(&orm.StateJournal{}).
+		Error
+}
+
+// restoreRow writes back the pre-state of a row. The zero AuditorID of
+// a storage contract without auditor is written as null, which the
+// foreign key to accounts requires.
+func restoreRow(dbTx *gorm.DB, row any) error {
+	sc, ok := row.(*orm.StorageContract)
+	if !ok || sc.AuditorID != 0 {
+		return dbTx.Omit(clause.Associations).Save(row).Error
+	}
+
+	if err := dbTx.Omit(clause.Associations, "auditor_id").
+		Save(sc).
+		Error; err != nil {
+		return err
+	}
+
+	return dbTx.Model(&orm.StorageContract{}).
+		Where("id = ?", sc.ID).
+		Update("auditor_id", nil).
+		Error
+}
+
+func journalRow(entity string) (any, error) {
+	switch entity {
+	case entityAccount:
+		return &orm.Account{}, nil
+	case entityValidator:
+		return &orm.Validator{}, nil
+	case entityAuditor:
+		return &orm.Auditor{}, nil
+	case entityStorageContract:
+		return &orm.StorageContract{}, nil
+	case entityTransactionContract:
+		return &orm.TransactionContract{}, nil
+	case entityStorageProof:
+		return &orm.StorageProof{}, nil
+	case entityContractStatus:
+		return &orm.StorageContractStatus{}, nil
+	case entityParticipation:
+		return &orm.ValidatorParticipation{}, nil
+	default:
+		return nil, fmt.Errorf("unknown journal entity %s", entity)
+	}
+}
+
+// pruneJournal drops the journal of finalized blocks, which can never be
+// rolled back.
+func pruneJournal(dbTx *gorm.DB, finalizedSlot uint64) error {
+	return dbTx.Where(
+		"block_id in (?)",
+		dbTx.Model(&orm.Block{}).
+			Select("id").
+			Where("slot <= ?", finalizedSlot),
+	).Delete(&orm.StateJournal{}).Error
+}
@@ -0,0 +1,120 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestJournal(t *testing.T) {
+	db := newDB(t)
+	createAccount(t, db, alicePK, 100)
+
+	b := &orm.Block{Slot: 1, Hash: "b1"}
+	if err := db.Create(b).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	alice := &orm.Account{}
+	if err := db.Where("public_key = ?", alicePK).First(alice).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var created []uint64
+	if err := db.Transaction(func(dbTx *gorm.DB) error {
+		j := newJournal(dbTx, b.ID, b.Slot)
+
+		// Only the state before the first change of the block is kept.
+		for _, balance := range []uint64{50, 20} {
+			if err := j.accountByID(alice.ID); err != nil {
+				return err
+			}
+
+			if err := dbTx.Model(alice).Update("balance", balance).Error; err != nil {
+				return err
+			}
+		}
+
+		bob := &orm.Account{PublicKey: bobPK}
+		if err := dbTx.Create(bob).Error; err != nil {
+			return err
+		}
+		created = append(created, bob.ID)
+		if err := j.created(entityAccount, bob.ID); err != nil {
+			return err
+		}
+
+		v := &orm.Validator{AccountID: bob.ID}
+		if err := dbTx.Create(v).Error; err != nil {
+			return err
+		}
+
+		return j.created(entityValidator, v.ID)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := count(t, db, &orm.StateJournal{}, "block_id = ?", b.ID); n != 3 {
+		t.Fatalf("%d journal entries, want 3", n)
+	}
+
+	var accounts []uint64
+	if err := db.Transaction(func(dbTx *gorm.DB) error {
+		var err error
+		accounts, err = revertJournal(dbTx, b.ID)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Created accounts are left for the caller, after the transactions.
+	if !reflect.DeepEqual(accounts, created) {
+		t.Errorf("created accounts = %v, want %v", accounts, created)
+	}
+
+	if err := db.First(alice, alice.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if alice.Balance != 100 {
+		t.Errorf("alice balance = %d, want 100", alice.Balance)
+	}
+
+	if n := count(t, db, &orm.Validator{}, "1 = 1"); n != 0 {
+		t.Errorf("%d created validators kept", n)
+	}
+	if n := count(t, db, &orm.StateJournal{}, "1 = 1"); n != 0 {
+		t.Errorf("%d journal entries kept", n)
+	}
+}
+
+func TestPruneJournal(t *testing.T) {
+	db := newDB(t)
+	for _, slot := range []uint64{1, 2, 3} {
+		b := &orm.Block{Slot: slot}
+		if err := db.Create(b).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if err := newJournal(db, b.ID, slot).created(entityAccount, slot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pruneJournal(db, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	kept := make([]uint64, 0)
+	if err := db.Model(&orm.StateJournal{}).
+		Pluck("entity_id", &kept).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(kept, []uint64{3}) {
+		t.Errorf("journal kept for %v, want the unfinalized slot 3", kept)
+	}
+}
@@ -0,0 +1,120 @@
+package indexer
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsNamespace = "photon_explorer"
+
+var (
+	indexedHeadSlot = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "head_slot",
+		Help:      "Last slot indexed.",
+	})
+	nodeHeadSlot = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "node_head_slot",
+		Help:      "Head slot reported by the photon node.",
+	})
+	headLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "lag_slots",
+		Help:      "Slots the index is behind the photon node head.",
+	})
+	lastIndexedTime = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "last_indexed_timestamp_seconds",
+		Help: "Unix time a slot was last indexed, stalling while the " +
+			"indexer makes no progress.",
+	})
+	nodeAvailable = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "node_available",
+		Help:      "Whether the photon node can be reached, 0 or 1.",
+	})
+	slotDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "slot_duration_seconds",
+		Help:      "Time to index a slot, node calls included.",
+	})
+	reorgCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "reorgs_total",
+		Help:      "Chain reorganizations unwound.",
+	})
+	reorgDepth = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "reorg_depth",
+		Help:      "Slots unwound by a reorganization.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+	})
+	dbTransactionDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "db",
+			Name:      "transaction_duration_seconds",
+			Help:      "Duration of the indexer database transactions.",
+		},
+		[]string{"operation"},
+	)
+	verifyDrift = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "verify",
+			Name:      "drift",
+			Help: "Indexed fields that differed from the node in the last " +
+				"verification.",
+		},
+		[]string{"entity"},
+	)
+	heldEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "indexer",
+		Name:      "held_events",
+		Help: "Events past the retention kept since the webhook worker " +
+			"hasn't read them yet.",
+	})
+	verifyRepairs = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "verify",
+			Name:      "repairs_total",
+			Help:      "Indexed fields overwritten with the node state.",
+		},
+		[]string{"entity"},
+	)
+)
+
+// reportHead updates the head gauges from the next slot to index and
+// the node head.
+func reportHead(nextSlot uint64, nodeHead uint64) {
+	indexed := uint64(0)
+	if nextSlot > 0 {
+		indexed = nextSlot - 1
+	}
+
+	lag := uint64(0)
+	if nodeHead > indexed {
+		lag = nodeHead - indexed
+	}
+
+	indexedHeadSlot.Set(float64(indexed))
+	nodeHeadSlot.Set(float64(nodeHead))
+	headLag.Set(float64(lag))
+}
+
+func observeSince(o prometheus.Observer, start time.Time) {
+	o.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,198 @@
+package indexer
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/config/config"
+	"github.com/photon-storage/go-photon/sak/time/slots"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// recordAttestation marks the attestation duty of the validator in the
+// epoch of slot as included by the block being indexed. Only the first
+// inclusion counts.
+func recordAttestation(
+	dbTx *gorm.DB,
+	j *journal,
+	validatorIndex uint64,
+	slot uint64,
+) error {
+	epoch := uint64(slots.ToEpoch(pbc.Slot(slot)))
+	ps := make([]*orm.ValidatorParticipation, 0)
+	if err := dbTx.Model(&orm.ValidatorParticipation{}).
+		Where("validator_idx = ? and epoch = ?", validatorIndex, epoch).
+		Limit(1).
+		Find(&ps).
+		Error; err != nil {
+		return err
+	}
+
+	if len(ps) == 0 {
+		p := &orm.ValidatorParticipation{
+			ValidatorIndex:  validatorIndex,
+			Epoch:           epoch,
+			AttestationSlot: slot,
+			Attested:        true,
+			InclusionSlot:   j.slot,
+		}
+		if err := dbTx.Model(&orm.ValidatorParticipation{}).
+			Create(p).
+			Error; err != nil {
+			return err
+		}
+
+		return j.created(entityParticipation, p.ID)
+	}
+
+	p := ps[0]
+	if p.Attested {
+		return nil
+	}
+
+	if err := j.record(entityParticipation, p.ID, p); err != nil {
+		return err
+	}
+
+	return dbTx.Model(&orm.ValidatorParticipation{}).
+		Where("id = ?", p.ID).
+		Updates(map[string]interface{}{
+			"attestation_slot": slot,
+			"attested":         true,
+			"inclusion_slot":   j.slot,
+		}).
+		Error
+}
+
+// updateEpochDuties records the attestation duty of every validator in
+// the epoch two before the one starting at slot, so validators whose
+// attestations were never included show up as missed, and publishes an
+// event for each of them. The attestations of an epoch can still be
+// included during the next one, so its duties are only settled once
+// that epoch is over.
+func updateEpochDuties(
+	ctx context.Context,
+	node chain.NodeClient,
+	dbTx *gorm.DB,
+	slot uint64,
+) error {
+	epoch := uint64(slots.ToEpoch(pbc.Slot(slot)))
+	if epoch < 2 {
+		return nil
+	}
+
+	settled := epoch - 2
+	settledIndexes := make([]uint64, 0)
+	if err := dbTx.Model(&orm.ValidatorParticipation{}).
+		Where("epoch = ?", settled).
+		Pluck("validator_idx", &settledIndexes).
+		Error; err != nil {
+		return err
+	}
+
+	recorded := make(map[uint64]bool, len(settledIndexes))
+	for _, idx := range settledIndexes {
+		recorded[idx] = true
+	}
+
+	slotsPerEpoch := uint64(config.Consensus().SlotsPerEpoch)
+	start := settled * slotsPerEpoch
+	missed := make([]*orm.ValidatorParticipation, 0)
+	for s := start; s < start+slotsPerEpoch; s++ {
+		cs, err := node.Committees(ctx, s)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range cs {
+			for _, idx := range c.ValidatorIndexes {
+				if recorded[idx] {
+					continue
+				}
+
+				recorded[idx] = true
+				missed = append(missed, &orm.ValidatorParticipation{
+					ValidatorIndex:  idx,
+					Epoch:           settled,
+					AttestationSlot: s,
+				})
+			}
+		}
+	}
+
+	if len(missed) == 0 {
+		return nil
+	}
+
+	if err := dbTx.Model(&orm.ValidatorParticipation{}).
+		CreateInBatches(missed, defaultPageSize).
+		Error; err != nil {
+		return err
+	}
+
+	missedIndexes := make([]uint64, 0, len(missed))
+	for _, p := range missed {
+		missedIndexes = append(missedIndexes, p.ValidatorIndex)
+	}
+
+	pks, err := validatorPublicKeys(dbTx, missedIndexes)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range missed {
+		if err := publishEvent(
+			dbTx,
+			orm.EventMissedAttestation,
+			slot,
+			&orm.MissedAttestationEvent{
+				ValidatorIndex:  p.ValidatorIndex,
+				PublicKey:       pks[p.ValidatorIndex],
+				Epoch:           settled,
+				AttestationSlot: p.AttestationSlot,
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatorPublicKeys returns the public keys of the validators with the
+// given indexes, keyed by index. Validators not indexed yet are left out.
+func validatorPublicKeys(
+	dbTx *gorm.DB,
+	indexes []uint64,
+) (map[uint64]string, error) {
+	pks := make(map[uint64]string, len(indexes))
+	for from := 0; from < len(indexes); from += defaultPageSize {
+		to := from + defaultPageSize
+		if to > len(indexes) {
+			to = len(indexes)
+		}
+
+		rows := make([]*struct {
+			Idx       uint64
+			PublicKey string
+		}, 0)
+		if err := dbTx.Model(&orm.Validator{}).
+			Select("validators.idx, accounts.public_key").
+			Joins("join accounts on accounts.id = validators.account_id").
+			Where("validators.idx in ?", indexes[from:to]).
+			Scan(&rows).
+			Error; err != nil {
+			return nil, err
+		}
+
+		for _, r := range rows {
+			pks[r.Idx] = r.PublicKey
+		}
+	}
+
+	return pks, nil
+}
@@ -0,0 +1,79 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/photon-storage/go-photon/config/config"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestUpdateEpochDuties(t *testing.T) {
+	db := newDB(t)
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	// Validator 0 attests slot 1, included late in epoch 1. Validator 1
+	// never attests slot 2, its duty is the first of its committees.
+	spe := uint64(config.Consensus().SlotsPerEpoch)
+	gw.SetCommittees(1, []*chain.Committee{{ValidatorIndexes: []uint64{0}}})
+	gw.SetCommittees(2, []*chain.Committee{{ValidatorIndexes: []uint64{1}}})
+	gw.SetCommittees(3, []*chain.Committee{{ValidatorIndexes: []uint64{1}}})
+
+	createAccount(t, db, bobPK, 0)
+	bob, err := getAccountIDByPublicKey(db, bobPK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&orm.Validator{AccountID: bob, Index: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := updateEpochDuties(ctx, gw.Client(), db, spe); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, &orm.ValidatorParticipation{}, "1 = 1"); n != 0 {
+		t.Errorf("%d duties settled while still includable", n)
+	}
+
+	if err := recordAttestation(db, newJournal(db, 1, spe+1), 0, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := updateEpochDuties(ctx, gw.Client(), db, 2*spe); err != nil {
+		t.Fatal(err)
+	}
+
+	ps := make([]*orm.ValidatorParticipation, 0)
+	if err := db.Order("validator_idx asc").Find(&ps).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || !ps[0].Attested || ps[0].InclusionSlot != spe+1 ||
+		ps[1].Attested || ps[1].Epoch != 0 || ps[1].AttestationSlot != 2 {
+		t.Errorf("duties %+v %+v", ps[0], ps[len(ps)-1])
+	}
+
+	events := make([]*orm.Event, 0)
+	if err := db.Where("kind = ?", orm.EventMissedAttestation).
+		Find(&events).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("%d missed attestation events, want 1", len(events))
+	}
+
+	e := &orm.MissedAttestationEvent{}
+	if err := json.Unmarshal(events[0].Payload, e); err != nil {
+		t.Fatal(err)
+	}
+	if e.ValidatorIndex != 1 || e.Epoch != 0 || e.PublicKey != bobPK {
+		t.Errorf("missed attestation of validator %d %s in epoch %d, want 1 0",
+			e.ValidatorIndex, e.PublicKey, e.Epoch)
+	}
+}
@@ -0,0 +1,232 @@
+package indexer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain"
+)
+
+const (
+	defaultPrefetchDepth = 16
+	// committeeRetention is how many slots behind the next slot committees
+	// stay cached, since attestations are included in later blocks.
+	committeeRetention = 64
+)
+
+// slotFetch holds the node data of one slot fetched ahead of indexing.
+type slotFetch struct {
+	done      chan struct{}
+	block     *gateway.BlockResp
+	contracts []string
+	err       error
+}
+
+// prefetcher fetches blocks, attestation committees and storage
+// contracts of upcoming slots concurrently. It implements
+// chain.NodeClient so processSlot consumes prefetched data through the
+// same calls it would send to the node, which keeps DB writes ordered
+// and the parent hash checks unchanged. Anything not prefetched, or
+// whose prefetch failed, falls through to the node.
+type prefetcher struct {
+	chain.NodeClient
+
+	depth      int
+	mu         sync.Mutex
+	gen        uint64
+	slots      map[uint64]*slotFetch
+	committees map[uint64][]*chain.Committee
+	contracts  map[string]*gateway.StorageResp
+}
+
+func newPrefetcher(node chain.NodeClient, depth int) *prefetcher {
+	if depth <= 0 {
+		depth = defaultPrefetchDepth
+	}
+
+	return &prefetcher{
+		NodeClient: node,
+		depth:      depth,
+		slots:      make(map[uint64]*slotFetch),
+		committees: make(map[uint64][]*chain.Committee),
+		contracts:  make(map[string]*gateway.StorageResp),
+	}
+}
+
+// schedule starts fetching the slots in [from, to] that fit in the
+// prefetch window and are not in flight yet.
+func (p *prefetcher) schedule(ctx context.Context, from, to uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for slot := from; slot <= to && slot < from+uint64(p.depth); slot++ {
+		if _, ok := p.slots[slot]; ok {
+			continue
+		}
+
+		f := &slotFetch{done: make(chan struct{})}
+		p.slots[slot] = f
+		go p.fetch(ctx, p.gen, slot, f)
+	}
+}
+
+func (p *prefetcher) fetch(
+	ctx context.Context,
+	gen uint64,
+	slot uint64,
+	f *slotFetch,
+) {
+	defer close(f.done)
+
+	b, err := p.NodeClient.BlockBySlot(ctx, slot)
+	if err != nil {
+		f.err = err
+		return
+	}
+	f.block = b
+
+	for _, a := range b.Attestations {
+		p.mu.Lock()
+		_, ok := p.committees[a.Slot]
+		p.mu.Unlock()
+		if ok {
+			continue
+		}
+
+		cs, err := p.NodeClient.Committees(ctx, a.Slot)
+		if err != nil {
+			f.err = err
+			return
+		}
+
+		p.mu.Lock()
+		if p.gen == gen {
+			p.committees[a.Slot] = cs
+		}
+		p.mu.Unlock()
+	}
+
+	for _, tx := range b.Txs {
+		if tx.Type != pbc.TxType_OBJECT_COMMIT.String() {
+			continue
+		}
+
+		sc, err := p.NodeClient.StorageContract(ctx, tx.TxHash, b.BlockHash)
+		if err != nil {
+			f.err = err
+			return
+		}
+
+		key := contractKey(tx.TxHash, b.BlockHash)
+		p.mu.Lock()
+		if p.gen == gen {
+			p.contracts[key] = sc
+		}
+		p.mu.Unlock()
+		f.contracts = append(f.contracts, key)
+	}
+}
+
+// advance drops the data of slots before next, which are indexed.
+func (p *prefetcher) advance(next uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for slot, f := range p.slots {
+		if slot >= next {
+			continue
+		}
+
+		select {
+		case <-f.done:
+			for _, key := range f.contracts {
+				delete(p.contracts, key)
+			}
+		default:
+		}
+		delete(p.slots, slot)
+	}
+
+	for slot := range p.committees {
+		if slot+committeeRetention < next {
+			delete(p.committees, slot)
+		}
+	}
+}
+
+// reset drops everything prefetched. It is called whenever indexing
+// fails or rolls back, since prefetched data may belong to a branch the
+// node no longer follows.
+func (p *prefetcher) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.gen++
+	p.slots = make(map[uint64]*slotFetch)
+	p.committees = make(map[uint64][]*chain.Committee)
+	p.contracts = make(map[string]*gateway.StorageResp)
+}
+
+// BlockBySlot returns the prefetched block of the slot.
+func (p *prefetcher) BlockBySlot(
+	ctx context.Context,
+	slot uint64,
+) (*gateway.BlockResp, error) {
+	p.mu.Lock()
+	f, ok := p.slots[slot]
+	p.mu.Unlock()
+
+	if ok {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-f.done:
+		}
+
+		if f.block != nil {
+			return f.block, nil
+		}
+	}
+
+	return p.NodeClient.BlockBySlot(ctx, slot)
+}
+
+// Committees returns the prefetched committees of the slot.
+func (p *prefetcher) Committees(
+	ctx context.Context,
+	slot uint64,
+) ([]*chain.Committee, error) {
+	p.mu.Lock()
+	cs, ok := p.committees[slot]
+	p.mu.Unlock()
+
+	if ok {
+		return cs, nil
+	}
+
+	return p.NodeClient.Committees(ctx, slot)
+}
+
+// StorageContract returns the prefetched storage contract of the tx.
+func (p *prefetcher) StorageContract(
+	ctx context.Context,
+	txHash string,
+	blockHash string,
+) (*gateway.StorageResp, error) {
+	p.mu.Lock()
+	sc, ok := p.contracts[contractKey(txHash, blockHash)]
+	p.mu.Unlock()
+
+	if ok {
+		return sc, nil
+	}
+
+	return p.NodeClient.StorageContract(ctx, txHash, blockHash)
+}
+
+func contractKey(txHash string, blockHash string) string {
+	return txHash + "/" + blockHash
+}
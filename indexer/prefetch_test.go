@@ -0,0 +1,124 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+)
+
+func TestPrefetcher(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, gw *chaintest.Gateway, p *prefetcher)
+	}{
+		{
+			name: "serves the prefetched slot data",
+			run: func(t *testing.T, gw *chaintest.Gateway, p *prefetcher) {
+				b := gw.Block(1)
+				prefetch(p, 0, 1)
+
+				// The node is down, everything comes from the prefetch.
+				gw.FailNext("", 100)
+				got, err := p.BlockBySlot(context.Background(), 1)
+				if err != nil || got.BlockHash != b.BlockHash {
+					t.Fatalf("block = %v, %v, want %s", got, err, b.BlockHash)
+				}
+
+				cs, err := p.Committees(context.Background(), 0)
+				if err != nil || len(cs) != 1 {
+					t.Fatalf("committees = %v, %v, want one", cs, err)
+				}
+
+				sc, err := p.StorageContract(context.Background(), "t1", b.BlockHash)
+				if err != nil || sc.ObjectHash != "object/t1" {
+					t.Fatalf("storage contract = %v, %v", sc, err)
+				}
+			},
+		},
+		{
+			name: "falls through to the node after a failed fetch",
+			run: func(t *testing.T, gw *chaintest.Gateway, p *prefetcher) {
+				gw.FailNext("/block", 1)
+				prefetch(p, 1, 1)
+
+				got, err := p.BlockBySlot(context.Background(), 1)
+				if err != nil || got.BlockHash != gw.Block(1).BlockHash {
+					t.Fatalf("block = %v, %v, want the node one", got, err)
+				}
+			},
+		},
+		{
+			name: "reset drops the abandoned branch",
+			run: func(t *testing.T, gw *chaintest.Gateway, p *prefetcher) {
+				prefetch(p, 0, 1)
+
+				gw.Fork(1)
+				b := gw.AddBlock()
+				p.reset()
+
+				got, err := p.BlockBySlot(context.Background(), 1)
+				if err != nil || got.BlockHash != b.BlockHash {
+					t.Fatalf("block = %v, %v, want %s", got, err, b.BlockHash)
+				}
+
+				if len(p.contracts) != 0 || len(p.committees) != 0 {
+					t.Fatalf("%d contracts %d committees kept after reset",
+						len(p.contracts), len(p.committees))
+				}
+			},
+		},
+		{
+			name: "advance drops the indexed slots",
+			run: func(t *testing.T, gw *chaintest.Gateway, p *prefetcher) {
+				prefetch(p, 0, 1)
+				p.advance(2)
+
+				if len(p.slots) != 0 || len(p.contracts) != 0 {
+					t.Fatalf("%d slots %d contracts kept after advance",
+						len(p.slots), len(p.contracts))
+				}
+
+				// Committees stay cached for later attestations.
+				if len(p.committees) != 1 {
+					t.Fatalf("%d committees cached, want 1", len(p.committees))
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gw := chaintest.NewGateway()
+			defer gw.Close()
+
+			gw.SetCommittees(0, []*chain.Committee{{ValidatorIndexes: []uint64{0}}})
+			gw.SetStorageContract("t1", &gateway.StorageResp{
+				Owner:      "alice",
+				Depot:      "depot",
+				ObjectHash: "object/t1",
+			})
+			gw.AddBlock()
+			gw.AddBlock(
+				chaintest.WithTxs(commitTx("t1", "alice", "depot")),
+				chaintest.WithAttestations(&gateway.Attestation{Slot: 0}),
+			)
+
+			c.run(t, gw, newPrefetcher(gw.Client(), 0))
+		})
+	}
+}
+
+// prefetch schedules the slots in [from, to] and waits for them.
+func prefetch(p *prefetcher, from uint64, to uint64) {
+	p.schedule(context.Background(), from, to)
+	for slot := from; slot <= to; slot++ {
+		p.mu.Lock()
+		f := p.slots[slot]
+		p.mu.Unlock()
+		<-f.done
+	}
+}
@@ -0,0 +1,1046 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	"github.com/photon-storage/go-photon/config/config"
+	"github.com/photon-storage/go-photon/crypto/sha256"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// Reindex change actions.
+const (
+	ReindexCreated = "created"
+	ReindexUpdated = "updated"
+	ReindexDeleted = "deleted"
+)
+
+// Entities of the reindex changes besides the journaled ones.
+const (
+	entityBlock       = "blocks"
+	entityTransaction = "transactions"
+	entityAttestation = "attestations"
+)
+
+var (
+	errReindexDryRun   = errors.New("reindex dry run")
+	errReindexRange    = errors.New("invalid reindex range")
+	errReindexMismatch = errors.New("indexed block differs from the node")
+)
+
+// ReindexChange is a row a reindex rebuilt differently from the index.
+type ReindexChange struct {
+	// Slot is the slot of the rebuilt block, unset for the recomputed
+	// state.
+	Slot   uint64
+	Entity string
+	// Key identifies the row, a block or transaction hash or a public
+	// key.
+	Key    string
+	Action string
+	Detail string
+}
+
+// ReindexReport lists the changes of a reindex, to the rows of the
+// range and to the recomputed account, validator and auditor state.
+type ReindexReport struct {
+	FromSlot uint64
+	ToSlot   uint64
+	DryRun   bool
+	Changes  []*ReindexChange
+	State    []*ReindexChange
+}
+
+// Reindex rebuilds the blocks, transactions, attestations and contract
+// links of the slots from the node, then recomputes the state of the
+// accounts they involve: the balance, validator and auditor status from
+// the node, the nonce and deposits from the rebuilt transactions.
+//
+// The indexed hashes must match the node, a differing chain is left to
+// the reorg handling of the indexer. Rows are rebuilt in place so the
+// references from outside the range stay valid, and no event is
+// published for the historical changes. Balance corrections are written
+// to the ledger as reconciliations. The whole reindex runs in a single
+// DB transaction, which a dry run rolls back after reporting.
+//
+// The node state only matches the index at the node head, so the
+// reindex waits for the indexer to catch up and is retried if the node
+// head moves while it runs.
+func Reindex(
+	ctx context.Context,
+	node chain.NodeClient,
+	db *gorm.DB,
+	from uint64,
+	to uint64,
+	dryRun bool,
+) (*ReindexReport, error) {
+	for attempt := 1; ; attempt++ {
+		report := &ReindexReport{
+			FromSlot: from,
+			ToSlot:   to,
+			DryRun:   dryRun,
+		}
+
+		err := db.Transaction(func(dbTx *gorm.DB) error {
+			r := &reindexer{
+				ctx:      ctx,
+				node:     node,
+				dbTx:     dbTx,
+				report:   report,
+				accounts: make(map[uint64]bool),
+			}
+			if err := r.run(from, to); err != nil {
+				return err
+			}
+
+			if dryRun {
+				return errReindexDryRun
+			}
+
+			return nil
+		})
+		if err == nil || err == errReindexDryRun {
+			return report, nil
+		}
+
+		if err != errNotSynced || attempt == syncAttempts {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-time.After(syncWait):
+
+		}
+	}
+}
+
+type reindexer struct {
+	ctx    context.Context
+	node   chain.NodeClient
+	dbTx   *gorm.DB
+	report *ReindexReport
+	// accounts are the ids of the accounts whose state is recomputed.
+	accounts map[uint64]bool
+	headSlot uint64
+	// head journals the reconciled balances with the indexed head block.
+	head *journal
+}
+
+func (r *reindexer) change(slot uint64, entity, key, action, detail string) {
+	r.report.Changes = append(r.report.Changes, &ReindexChange{
+		Slot:   slot,
+		Entity: entity,
+		Key:    key,
+		Action: action,
+		Detail: detail,
+	})
+}
+
+func (r *reindexer) stateChange(entity, pk, action, detail string) {
+	r.report.State = append(r.report.State, &ReindexChange{
+		Entity: entity,
+		Key:    pk,
+		Action: action,
+		Detail: detail,
+	})
+}
+
+func (r *reindexer) run(from, to uint64) error {
+	// Touching the chain status first serializes the reindex with the
+	// slot transactions of a running indexer.
+	if err := r.dbTx.Model(&orm.ChainStatus{}).
+		Where("id = 1").
+		Update("updated_at", time.Now()).
+		Error; err != nil {
+		return err
+	}
+
+	nextSlot, hash, err := chainStatus(r.dbTx)
+	if err != nil {
+		return err
+	}
+
+	if from > to || to >= nextSlot {
+		return errors.Wrapf(
+			errReindexRange,
+			"slots %d to %d, next slot to index %d",
+			from,
+			to,
+			nextSlot,
+		)
+	}
+	r.headSlot = nextSlot - 1
+
+	if err := synced(r.ctx, r.node, nextSlot, hash); err != nil {
+		return err
+	}
+
+	if r.head, err = headJournal(r.dbTx, hash, r.headSlot); err != nil {
+		return err
+	}
+
+	for slot := from; slot <= to; slot++ {
+		select {
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+
+		default:
+
+		}
+
+		if err := r.slot(slot); err != nil {
+			return errors.Wrapf(err, "slot %d", slot)
+		}
+	}
+
+	if err := r.recomputeState(); err != nil {
+		return err
+	}
+
+	// The recomputed state is only that of the indexed head if the node
+	// head did not move meanwhile.
+	return synced(r.ctx, r.node, nextSlot, hash)
+}
+
+func (r *reindexer) slot(slot uint64) error {
+	nb, err := r.node.BlockBySlot(r.ctx, slot)
+	if err != nil {
+		return err
+	}
+
+	bs := make([]*orm.Block, 0)
+	if err := r.dbTx.Model(&orm.Block{}).
+		Where("slot = ?", slot).
+		Limit(1).
+		Find(&bs).
+		Error; err != nil {
+		return err
+	}
+
+	// The row of an empty slot has no hash.
+	hash := nb.BlockHash
+	if hash == sha256.Zero.Hex() {
+		hash = ""
+	}
+
+	if len(bs) > 0 && bs[0].Hash != hash {
+		return errors.Wrapf(
+			errReindexMismatch,
+			"indexed %q, node %q",
+			bs[0].Hash,
+			hash,
+		)
+	}
+
+	if nb.BlockHash == sha256.Zero.Hex() {
+		return r.missedSlot(bs, nb)
+	}
+
+	blockID := uint64(0)
+	if len(bs) == 0 {
+		if blockID, err = createBlock(r.dbTx, nb); err != nil {
+			return err
+		}
+
+		r.change(slot, entityBlock, nb.BlockHash, ReindexCreated, "")
+	} else {
+		blockID = bs[0].ID
+		b := newBlock(nb)
+		b.ID, b.CreatedAt, b.UpdatedAt = bs[0].ID, bs[0].CreatedAt, bs[0].UpdatedAt
+		if sameProposer(b.ProposalIndex, bs[0].ProposalIndex) {
+			b.ProposalIndex = bs[0].ProposalIndex
+		}
+
+		if *b != *bs[0] {
+			if err := r.dbTx.Save(b).Error; err != nil {
+				return err
+			}
+
+			r.change(slot, entityBlock, nb.BlockHash, ReindexUpdated, "")
+		}
+	}
+
+	if err := r.attestations(blockID, nb); err != nil {
+		return err
+	}
+
+	return r.transactions(blockID, nb)
+}
+
+// missedSlot rebuilds the row kept for an empty slot.
+func (r *reindexer) missedSlot(bs []*orm.Block, nb *gateway.BlockResp) error {
+	proposer := missedProposer(nb)
+	if len(bs) == 0 {
+		if err := r.dbTx.Model(&orm.Block{}).
+			Create(&orm.Block{
+				Slot:          nb.Slot,
+				ProposalIndex: proposer,
+			}).
+			Error; err != nil {
+			return err
+		}
+
+		r.change(nb.Slot, entityBlock, "", ReindexCreated, "missed slot")
+		return nil
+	}
+
+	if sameProposer(bs[0].ProposalIndex, proposer) {
+		return nil
+	}
+
+	if err := r.dbTx.Model(&orm.Block{}).
+		Where("id = ?", bs[0].ID).
+		Update("proposal_index", proposer).
+		Error; err != nil {
+		return err
+	}
+
+	r.change(
+		nb.Slot,
+		entityBlock,
+		"",
+		ReindexUpdated,
+		fmt.Sprintf(
+			"missed slot proposer %s -> %s",
+			proposerString(bs[0].ProposalIndex),
+			proposerString(proposer),
+		),
+	)
+	return nil
+}
+
+func sameProposer(a *uint64, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func proposerString(index *uint64) string {
+	if index == nil {
+		return "unknown"
+	}
+
+	return strconv.FormatUint(*index, 10)
+}
+
+// attestations replaces the attestations of the block.
+func (r *reindexer) attestations(blockID uint64, nb *gateway.BlockResp) error {
+	olds := make([]*orm.Attestation, 0)
+	if err := r.dbTx.Model(&orm.Attestation{}).
+		Where("block_id = ?", blockID).
+		Order("id asc").
+		Find(&olds).
+		Error; err != nil {
+		return err
+	}
+
+	changed := len(olds) != len(nb.Attestations)
+	for i := 0; i < len(olds) && !changed; i++ {
+		old := *olds[i]
+		old.ID, old.CreatedAt, old.UpdatedAt = 0, time.Time{}, time.Time{}
+		changed = *newAttestation(blockID, nb.Attestations[i]) != old
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := r.dbTx.Unscoped().
+		Where("block_id = ?", blockID).
+		Delete(&orm.Attestation{}).
+		Error; err != nil {
+		return err
+	}
+
+	for _, a := range nb.Attestations {
+		if err := r.dbTx.Model(&orm.Attestation{}).
+			Create(newAttestation(blockID, a)).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	r.change(
+		nb.Slot,
+		entityAttestation,
+		nb.BlockHash,
+		ReindexUpdated,
+		fmt.Sprintf(
+			"%d -> %d",
+			len(olds),
+			len(nb.Attestations),
+		),
+	)
+	return nil
+}
+
+// transactions rebuilds the transactions of the block, keeping the ids
+// of the ones already indexed.
+func (r *reindexer) transactions(blockID uint64, nb *gateway.BlockResp) error {
+	olds := make([]*orm.Transaction, 0)
+	if err := r.dbTx.Model(&orm.Transaction{}).
+		Where("block_id = ?", blockID).
+		Find(&olds).
+		Error; err != nil {
+		return err
+	}
+
+	byHash := make(map[string]*orm.Transaction)
+	for _, t := range olds {
+		byHash[t.Hash] = t
+	}
+
+	for i, tx := range nb.Txs {
+		fromID, err := r.ensureAccount(nb.Slot, tx.From)
+		if err != nil {
+			return err
+		}
+
+		if tx.Type == pbc.TxType_BALANCE_TRANSFER.String() {
+			if _, err := r.ensureAccount(nb.Slot, tx.BalanceTransfer.To); err != nil {
+				return err
+			}
+		}
+
+		t, err := newTransaction(fromID, blockID, uint64(i), tx)
+		if err != nil {
+			return err
+		}
+
+		if old, ok := byHash[tx.TxHash]; ok {
+			delete(byHash, tx.TxHash)
+			t.ID = old.ID
+			if t.FromAccountID != old.FromAccountID ||
+				t.Position != old.Position ||
+				t.GasPrice != old.GasPrice ||
+				t.Amount != old.Amount ||
+				t.Type != old.Type ||
+				string(t.Raw) != string(old.Raw) {
+				if err := r.dbTx.Model(&orm.Transaction{}).
+					Where("id = ?", t.ID).
+					Updates(map[string]interface{}{
+						"from_account_id": t.FromAccountID,
+						"position":        t.Position,
+						"gas_price":       t.GasPrice,
+						"amount":          t.Amount,
+						"type":            t.Type,
+						"raw":             t.Raw,
+					}).
+					Error; err != nil {
+					return err
+				}
+
+				r.accounts[old.FromAccountID] = true
+				r.change(nb.Slot, entityTransaction, tx.TxHash, ReindexUpdated, "")
+			}
+		} else {
+			if err := r.dbTx.Model(&orm.Transaction{}).
+				Create(t).
+				Error; err != nil {
+				return err
+			}
+
+			r.change(nb.Slot, entityTransaction, tx.TxHash, ReindexCreated, "")
+		}
+
+		if err := r.contractLinks(t.ID, fromID, tx, nb); err != nil {
+			return err
+		}
+
+		if err := updateTransactionCounterparty(r.dbTx, t.ID, tx); err != nil {
+			return err
+		}
+
+		if err := r.participants(t.ID, fromID, tx); err != nil {
+			return err
+		}
+	}
+
+	hashes := make([]string, 0, len(byHash))
+	for hash := range byHash {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	for _, hash := range hashes {
+		if err := r.deleteTransaction(byHash[hash].ID); err != nil {
+			return err
+		}
+
+		r.change(nb.Slot, entityTransaction, hash, ReindexDeleted, "")
+	}
+
+	return nil
+}
+
+// ensureAccount returns the id of the account, created when missing.
+func (r *reindexer) ensureAccount(slot uint64, pk string) (uint64, error) {
+	a := &orm.Account{PublicKey: pk}
+	if err := r.dbTx.Model(&orm.Account{}).
+		Where(a).
+		First(a).
+		Error; err != nil && err != gorm.ErrRecordNotFound {
+		return 0, err
+	} else if err == gorm.ErrRecordNotFound {
+		if err := r.dbTx.Model(&orm.Account{}).Create(a).Error; err != nil {
+			return 0, err
+		}
+
+		r.change(slot, entityAccount, pk, ReindexCreated, "")
+	}
+
+	r.accounts[a.ID] = true
+	return a.ID, nil
+}
+
+// contractLinks rebuilds the storage contracts the transaction is linked
+// to, creating the contract of a commit and the proof of a PoR when
+// missing.
+func (r *reindexer) contractLinks(
+	txID uint64,
+	fromID uint64,
+	tx *gateway.Tx,
+	nb *gateway.BlockResp,
+) error {
+	olds := make([]uint64, 0)
+	if err := r.dbTx.Model(&orm.TransactionContract{}).
+		Where("transaction_id = ?", txID).
+		Order("contract_id asc").
+		Pluck("contract_id", &olds).
+		Error; err != nil {
+		return err
+	}
+
+	scs := make([]*orm.StorageContract, 0)
+	switch tx.Type {
+	case pbc.TxType_OBJECT_COMMIT.String():
+		if err := r.dbTx.Model(&orm.StorageContract{}).
+			Where("commit_transaction_id = ?", txID).
+			Find(&scs).
+			Error; err != nil {
+			return err
+		}
+
+		if len(scs) == 0 {
+			sc, err := r.commitContract(txID, tx, nb)
+			if err != nil {
+				return err
+			}
+
+			scs = append(scs, sc)
+		}
+
+	case pbc.TxType_OBJECT_AUDIT.String():
+		if err := r.dbTx.Model(&orm.StorageContract{}).
+			Joins("join transactions as t on t.id = storage_contracts.commit_transaction_id").
+			Where("t.hash = ?", tx.ObjectAudit.CommitTxHash).
+			Limit(1).
+			Find(&scs).
+			Error; err != nil {
+			return err
+		}
+
+	case pbc.TxType_OBJECT_POR.String():
+		if err := r.dbTx.Model(&orm.StorageContract{}).
+			Joins("join transactions as t on t.id = storage_contracts.commit_transaction_id").
+			Where("t.hash = ?", tx.ObjectPoR.CommitTxHash).
+			Limit(1).
+			Find(&scs).
+			Error; err != nil {
+			return err
+		}
+
+		if len(scs) > 0 {
+			if err := r.proof(txID, fromID, scs[0].ID, nb); err != nil {
+				return err
+			}
+		}
+	}
+
+	news := make([]uint64, 0, len(scs))
+	for _, sc := range scs {
+		news = append(news, sc.ID)
+	}
+
+	if fmt.Sprint(olds) == fmt.Sprint(news) {
+		return nil
+	}
+
+	if err := r.dbTx.Unscoped().
+		Where("transaction_id = ?", txID).
+		Delete(&orm.TransactionContract{}).
+		Error; err != nil {
+		return err
+	}
+
+	for _, id := range news {
+		if err := r.dbTx.Model(&orm.TransactionContract{}).
+			Create(&orm.TransactionContract{
+				TransactionID: txID,
+				ContractID:    id,
+			}).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	r.change(
+		nb.Slot,
+		entityTransactionContract,
+		tx.TxHash,
+		ReindexUpdated,
+		fmt.Sprintf(
+			"contracts %v -> %v",
+			olds,
+			news,
+		),
+	)
+	return nil
+}
+
+// commitContract creates the missing storage contract of a commit with
+// its initial status.
+func (r *reindexer) commitContract(
+	txID uint64,
+	tx *gateway.Tx,
+	nb *gateway.BlockResp,
+) (*orm.StorageContract, error) {
+	resp, err := r.node.StorageContract(r.ctx, tx.TxHash, nb.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pk := range []string{resp.Owner, resp.Depot, resp.Auditor} {
+		if pk == "" {
+			continue
+		}
+
+		if _, err := r.ensureAccount(nb.Slot, pk); err != nil {
+			return nil, err
+		}
+	}
+
+	sc, err := newStorageContract(r.dbTx, txID, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.dbTx.Model(&orm.StorageContract{}).Create(sc).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.dbTx.Model(&orm.StorageContractStatus{}).
+		Create(&orm.StorageContractStatus{
+			ContractID:    sc.ID,
+			PrevStatus:    int32(pbc.StorageStatus_STORAGE_INVALID),
+			Status:        sc.Status,
+			Slot:          nb.Slot,
+			TransactionID: txID,
+		}).
+		Error; err != nil {
+		return nil, err
+	}
+
+	r.change(nb.Slot, entityStorageContract, tx.TxHash, ReindexCreated, "")
+	return sc, nil
+}
+
+// proof creates the missing storage proof of a PoR transaction.
+func (r *reindexer) proof(
+	txID uint64,
+	depotID uint64,
+	contractID uint64,
+	nb *gateway.BlockResp,
+) error {
+	count := int64(0)
+	if err := r.dbTx.Model(&orm.StorageProof{}).
+		Where("transaction_id = ?", txID).
+		Count(&count).
+		Error; err != nil || count > 0 {
+		return err
+	}
+
+	return r.dbTx.Model(&orm.StorageProof{}).
+		Create(&orm.StorageProof{
+			ContractID:    contractID,
+			TransactionID: txID,
+			DepotID:       depotID,
+			Slot:          nb.Slot,
+		}).
+		Error
+}
+
+// participants rebuilds the participants of the transaction and marks
+// their accounts for the state recomputation.
+func (r *reindexer) participants(
+	txID uint64,
+	fromID uint64,
+	tx *gateway.Tx,
+) error {
+	if err := r.markParticipants(txID); err != nil {
+		return err
+	}
+
+	if err := r.dbTx.Where("transaction_id = ?", txID).
+		Delete(&orm.TransactionParticipant{}).
+		Error; err != nil {
+		return err
+	}
+
+	if err := createTransactionParticipants(r.dbTx, txID, fromID, tx); err != nil {
+		return err
+	}
+
+	return r.markParticipants(txID)
+}
+
+func (r *reindexer) markParticipants(txID uint64) error {
+	ids := make([]uint64, 0)
+	if err := r.dbTx.Model(&orm.TransactionParticipant{}).
+		Where("transaction_id = ?", txID).
+		Pluck("account_id", &ids).
+		Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		r.accounts[id] = true
+	}
+
+	return nil
+}
+
+// deleteTransaction removes an indexed transaction the node does not
+// have, with the contracts it committed and every row linked to them.
+func (r *reindexer) deleteTransaction(txID uint64) error {
+	if err := r.markParticipants(txID); err != nil {
+		return err
+	}
+
+	contracts := r.dbTx.Model(&orm.StorageContract{}).
+		Select("id").
+		Where("commit_transaction_id = ?", txID)
+	for _, row := range []any{
+		&orm.TransactionContract{},
+		&orm.StorageProof{},
+		&orm.StorageContractStatus{},
+	} {
+		if err := r.dbTx.Unscoped().
+			Where("transaction_id = ? or contract_id in (?)", txID, contracts).
+			Delete(row).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	if err := r.dbTx.Unscoped().
+		Where("commit_transaction_id = ?", txID).
+		Delete(&orm.StorageContract{}).
+		Error; err != nil {
+		return err
+	}
+
+	if err := r.dbTx.Where("transaction_id = ?", txID).
+		Delete(&orm.TransactionParticipant{}).
+		Error; err != nil {
+		return err
+	}
+
+	return r.dbTx.Unscoped().
+		Where("id = ?", txID).
+		Delete(&orm.Transaction{}).
+		Error
+}
+
+// recomputeState reconciles the accounts involved in the range, with
+// their validator and auditor.
+func (r *reindexer) recomputeState() error {
+	ids := make([]uint64, 0, len(r.accounts))
+	for id := range r.accounts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		a := &orm.Account{}
+		if err := r.dbTx.Model(&orm.Account{}).
+			Where("id = ?", id).
+			First(a).
+			Error; err != nil {
+			return err
+		}
+
+		if err := r.account(a); err != nil {
+			return errors.Wrapf(err, "account %s", a.PublicKey)
+		}
+
+		if err := r.validator(a); err != nil {
+			return errors.Wrapf(err, "validator %s", a.PublicKey)
+		}
+
+		if err := r.auditor(a); err != nil {
+			return errors.Wrapf(err, "auditor %s", a.PublicKey)
+		}
+	}
+
+	return nil
+}
+
+// account reconciles the balance with the node and recounts the nonce
+// from the sent transactions.
+func (r *reindexer) account(a *orm.Account) error {
+	if err := resetAccountBalance(
+		r.ctx,
+		r.node,
+		r.dbTx,
+		r.head,
+		r.headSlot,
+		a.PublicKey,
+	); err != nil {
+		return err
+	}
+
+	updated := &orm.Account{}
+	if err := r.dbTx.Model(&orm.Account{}).
+		Where("id = ?", a.ID).
+		First(updated).
+		Error; err != nil {
+		return err
+	}
+
+	if updated.Balance != a.Balance {
+		r.stateChange(
+			entityAccount,
+			a.PublicKey,
+			ReindexUpdated,
+			fmt.Sprintf(
+				"balance %d -> %d",
+				a.Balance,
+				updated.Balance,
+			),
+		)
+	}
+
+	nonce := int64(0)
+	if err := r.dbTx.Model(&orm.Transaction{}).
+		Where("from_account_id = ?", a.ID).
+		Count(&nonce).
+		Error; err != nil {
+		return err
+	}
+
+	if uint64(nonce) == a.Nonce {
+		return nil
+	}
+
+	if err := r.dbTx.Model(&orm.Account{}).
+		Where("id = ?", a.ID).
+		Update("nonce", nonce).
+		Error; err != nil {
+		return err
+	}
+
+	r.stateChange(
+		entityAccount,
+		a.PublicKey,
+		ReindexUpdated,
+		fmt.Sprintf(
+			"nonce %d -> %d",
+			a.Nonce,
+			nonce,
+		),
+	)
+	return nil
+}
+
+// deposits sums the amounts of the deposit transactions of the account.
+func (r *reindexer) deposits(
+	accountID uint64,
+	txType pbc.TxType,
+) (uint64, int64, error) {
+	result := new(struct {
+		Amount uint64
+		Count  int64
+	})
+	if err := r.dbTx.Model(&orm.Transaction{}).
+		Select("coalesce(sum(amount), 0) as amount, count(*) as count").
+		Where("from_account_id = ? and type = ?", accountID, int32(txType)).
+		Scan(result).
+		Error; err != nil {
+		return 0, 0, err
+	}
+
+	return result.Amount, result.Count, nil
+}
+
+// validator recomputes the deposit of the validator of the account from
+// the genesis and its deposits, and its status from the node.
+func (r *reindexer) validator(a *orm.Account) error {
+	amount, count, err := r.deposits(a.ID, pbc.TxType_VALIDATOR_DEPOSIT)
+	if err != nil {
+		return err
+	}
+
+	genesis, ok := config.Consensus().GenesisConfig.Validators[a.PublicKey]
+	if !ok && count == 0 {
+		return nil
+	}
+
+	remote, err := r.node.Validator(r.ctx, a.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	v := &orm.Validator{
+		AccountID:       a.ID,
+		Index:           remote.Index,
+		Deposit:         genesis + amount,
+		Status:          pbc.ValidatorStatus_value[remote.Status],
+		ActivationEpoch: dbEpoch(remote.ActivationEpoch),
+		ExitEpoch:       dbEpoch(remote.ExitEpoch),
+	}
+
+	olds := make([]*orm.Validator, 0)
+	if err := r.dbTx.Model(&orm.Validator{}).
+		Where("account_id = ?", a.ID).
+		Limit(1).
+		Find(&olds).
+		Error; err != nil {
+		return err
+	}
+
+	if len(olds) == 0 {
+		if err := r.dbTx.Model(&orm.Validator{}).Create(v).Error; err != nil {
+			return err
+		}
+
+		r.stateChange(entityValidator, a.PublicKey, ReindexCreated, "")
+		return nil
+	}
+
+	old := olds[0]
+	if old.Index == v.Index &&
+		old.Deposit == v.Deposit &&
+		old.Status == v.Status &&
+		old.ActivationEpoch == v.ActivationEpoch &&
+		old.ExitEpoch == v.ExitEpoch {
+		return nil
+	}
+
+	if err := r.dbTx.Model(&orm.Validator{}).
+		Where("id = ?", old.ID).
+		Updates(map[string]interface{}{
+			"idx":              v.Index,
+			"deposit":          v.Deposit,
+			"status":           v.Status,
+			"activation_epoch": v.ActivationEpoch,
+			"exit_epoch":       v.ExitEpoch,
+		}).
+		Error; err != nil {
+		return err
+	}
+
+	r.stateChange(
+		entityValidator,
+		a.PublicKey,
+		ReindexUpdated,
+		fmt.Sprintf(
+			"deposit %d -> %d, status %d -> %d",
+			old.Deposit,
+			v.Deposit,
+			old.Status,
+			v.Status,
+		),
+	)
+	return nil
+}
+
+// auditor recomputes the deposit of the auditor of the account from its
+// deposits, and its status from the node.
+func (r *reindexer) auditor(a *orm.Account) error {
+	amount, count, err := r.deposits(a.ID, pbc.TxType_AUDITOR_DEPOSIT)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	remote, err := r.node.Auditor(r.ctx, a.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	au := &orm.Auditor{
+		AccountID:       a.ID,
+		Deposit:         amount,
+		Status:          pbc.AuditorStatus_value[remote.Status],
+		ActivationEpoch: dbEpoch(remote.ActivationEpoch),
+		ExitEpoch:       dbEpoch(remote.ExitEpoch),
+	}
+
+	olds := make([]*orm.Auditor, 0)
+	if err := r.dbTx.Model(&orm.Auditor{}).
+		Where("account_id = ?", a.ID).
+		Limit(1).
+		Find(&olds).
+		Error; err != nil {
+		return err
+	}
+
+	if len(olds) == 0 {
+		if err := r.dbTx.Model(&orm.Auditor{}).Create(au).Error; err != nil {
+			return err
+		}
+
+		r.stateChange(entityAuditor, a.PublicKey, ReindexCreated, "")
+		return nil
+	}
+
+	old := olds[0]
+	if old.Deposit == au.Deposit &&
+		old.Status == au.Status &&
+		old.ActivationEpoch == au.ActivationEpoch &&
+		old.ExitEpoch == au.ExitEpoch {
+		return nil
+	}
+
+	if err := r.dbTx.Model(&orm.Auditor{}).
+		Where("id = ?", old.ID).
+		Updates(map[string]interface{}{
+			"deposit":          au.Deposit,
+			"status":           au.Status,
+			"activation_epoch": au.ActivationEpoch,
+			"exit_epoch":       au.ExitEpoch,
+		}).
+		Error; err != nil {
+		return err
+	}
+
+	r.stateChange(
+		entityAuditor,
+		a.PublicKey,
+		ReindexUpdated,
+		fmt.Sprintf(
+			"deposit %d -> %d, status %d -> %d",
+			old.Deposit,
+			au.Deposit,
+			old.Status,
+			au.Status,
+		),
+	)
+	return nil
+}
@@ -0,0 +1,222 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestReindexWaitsForTheNodeHead(t *testing.T) {
+	defer func(attempts int) { syncAttempts = attempts }(syncAttempts)
+	syncAttempts = 1
+
+	db := newDB(t)
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	createAccount(t, db, alicePK, 100)
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(transferTx("t1", alicePK, bobPK, 10)))
+	indexSlots(t, gw.Client(), db)
+
+	// The node is one transfer ahead of the index.
+	gw.AddBlock(chaintest.WithTxs(transferTx("t2", alicePK, bobPK, 5)))
+	gw.SetAccount(alicePK, &gateway.AccountResp{Nonce: 2, Balance: 83})
+	gw.SetAccount(bobPK, &gateway.AccountResp{Balance: 15})
+
+	ctx := context.Background()
+	if _, err := Reindex(ctx, gw.Client(), db, 1, 1, false); err != errNotSynced {
+		t.Fatalf("reindex behind the node head error = %v, want %v",
+			err, errNotSynced)
+	}
+
+	indexSlots(t, gw.Client(), db)
+	report, err := Reindex(ctx, gw.Client(), db, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 0 || len(report.State) != 0 {
+		t.Errorf("reindex of a consistent index changed %d rows, %d states",
+			len(report.Changes), len(report.State))
+	}
+
+	for pk, balance := range map[string]uint64{alicePK: 83, bobPK: 15} {
+		a := &orm.Account{}
+		if err := db.Where("public_key = ?", pk).First(a).Error; err != nil {
+			t.Fatal(err)
+		}
+		if a.Balance != balance {
+			t.Errorf("%s balance = %d, want %d", pk, a.Balance, balance)
+		}
+	}
+	checkLedger(t, db)
+}
+
+func TestReindexMatchesFreshIndex(t *testing.T) {
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	gw.SetValidator(bobPK, &gateway.ValidatorResp{
+		PublicKey: bobPK,
+		Index:     1,
+		Status:    pbc.ValidatorStatus_VALIDATOR_ACTIVE.String(),
+	})
+	gw.SetStorageContract("c1", &gateway.StorageResp{
+		Owner:      alicePK,
+		Depot:      bobPK,
+		Auditor:    alicePK,
+		ObjectHash: "object/c1",
+		Status:     pbc.StorageStatus_OPEN.String(),
+	})
+	deposit := &gateway.Tx{
+		TxHash:   "d1",
+		Type:     pbc.TxType_VALIDATOR_DEPOSIT.String(),
+		From:     bobPK,
+		GasPrice: 1,
+	}
+	alloc(&deposit.ValidatorDeposit).Amount = 100
+	gw.AddBlock()
+	gw.AddBlock(chaintest.WithTxs(transferTx("t1", alicePK, bobPK, 10), deposit))
+	gw.AddBlock(chaintest.WithTxs(commitTx("c1", alicePK, bobPK)))
+	gw.AddBlock(chaintest.WithTxs(auditTx("a1", alicePK, bobPK, "c1")))
+	gw.AddEmptySlot(chaintest.WithProposer(2))
+
+	index := func() *gorm.DB {
+		db := newDB(t)
+		createAccount(t, db, alicePK, 1000)
+		createAccount(t, db, bobPK, 1000)
+		indexSlots(t, gw.Client(), db)
+		return db
+	}
+
+	// The node serves the balances of the fresh index.
+	fresh := index()
+	for _, pk := range []string{alicePK, bobPK} {
+		a := &orm.Account{}
+		if err := fresh.Where("public_key = ?", pk).First(a).Error; err != nil {
+			t.Fatal(err)
+		}
+		gw.SetAccount(pk, &gateway.AccountResp{Nonce: a.Nonce, Balance: a.Balance})
+	}
+
+	db := index()
+	alice, err := getAccountIDByPublicKey(db, alicePK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2 := &orm.Block{}
+	if err := db.Where("slot = ?", 2).First(b2).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, corrupt := range []*gorm.DB{
+		db.Model(&orm.Block{}).Where("slot = ?", 4).Update("proposal_index", 7),
+		db.Model(&orm.Transaction{}).Where("hash = ?", "t1").Update("amount", 999),
+		db.Where("role = ?", orm.RoleRecipient).Delete(&orm.TransactionParticipant{}),
+		db.Where("1 = 1").Delete(&orm.TransactionContract{}),
+		db.Model(&orm.Account{}).Where("id = ?", alice).Update("nonce", 42),
+		db.Create(&orm.Transaction{
+			BlockID:       b2.ID,
+			Hash:          "x1",
+			FromAccountID: alice,
+			Position:      1,
+			Raw:           []byte("{}"),
+		}),
+	} {
+		if corrupt.Error != nil {
+			t.Fatal(corrupt.Error)
+		}
+	}
+
+	report, err := Reindex(context.Background(), gw.Client(), db, 1, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) == 0 || len(report.State) == 0 {
+		t.Errorf("reindex reported %d changes, %d states",
+			len(report.Changes), len(report.State))
+	}
+
+	queries := []string{
+		`select slot, hash, proposal_index from blocks
+			where deleted_at is null order by slot`,
+		`select b.slot, t.position, t.hash, t.type, t.amount, t.gas_price,
+			f.public_key, coalesce(r.public_key, '')
+			from transactions t
+			join blocks b on b.id = t.block_id
+			join accounts f on f.id = t.from_account_id
+			left join accounts r on r.id = t.to_account_id
+			where t.deleted_at is null order by b.slot, t.position`,
+		`select t.hash, a.public_key, p.role from transaction_participants p
+			join transactions t on t.id = p.transaction_id
+			join accounts a on a.id = p.account_id
+			order by t.hash, p.role`,
+		`select t.hash, c.hash from transaction_contracts tc
+			join transactions t on t.id = tc.transaction_id
+			join storage_contracts sc on sc.id = tc.contract_id
+			join transactions c on c.id = sc.commit_transaction_id
+			order by t.hash, c.hash`,
+		`select c.hash, sc.status, coalesce(a.public_key, '')
+			from storage_contracts sc
+			join transactions c on c.id = sc.commit_transaction_id
+			left join accounts a on a.id = sc.auditor_id
+			order by c.hash`,
+		`select public_key, nonce, balance from accounts order by public_key`,
+		`select a.public_key, v.idx, v.deposit, v.status from validators v
+			join accounts a on a.id = v.account_id order by a.public_key`,
+	}
+	for _, q := range queries {
+		if got, want := dump(t, db, q), dump(t, fresh, q); !reflect.DeepEqual(got, want) {
+			t.Errorf("reindexed %v\nfresh %v\nfor %s", got, want, q)
+		}
+	}
+	checkLedger(t, db)
+}
+
+// dump returns the rows of the query one per line, which compares
+// databases regardless of the row ids.
+func dump(t *testing.T, db *gorm.DB, query string) []string {
+	rows, err := db.Raw(query).Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make([]string, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			t.Fatal(err)
+		}
+
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		lines = append(lines, fmt.Sprint(values))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return lines
+}
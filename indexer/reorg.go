@@ -0,0 +1,165 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+	"github.com/photon-storage/go-photon/chain/gateway"
+	"github.com/photon-storage/go-photon/crypto/sha256"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+var (
+	errReorgBeyondFinalized = errors.New("reorg reaches a finalized block")
+	errReorgTooDeep         = errors.New("reorg exceeds the max rollback depth")
+	errNoCommonAncestor     = errors.New("no indexed block is on the node chain")
+)
+
+// processReorg unwinds the indexed blocks that are no longer on the
+// node's canonical chain, as detected by newBlock not extending headHash.
+// It returns the hash and the next slot to index from the common
+// ancestor.
+func processReorg(
+	ctx context.Context,
+	node chain.NodeClient,
+	dbTx *gorm.DB,
+	maxDepth uint64,
+	headHash string,
+	newBlock *gateway.BlockResp,
+) (string, uint64, error) {
+	cs := &orm.ChainStatus{}
+	if err := dbTx.Model(cs).First(cs).Error; err != nil {
+		return "", 0, err
+	}
+
+	ancestor, orphaned, err := findCommonAncestor(
+		ctx,
+		node,
+		dbTx,
+		headHash,
+		cs.FinalizedSlot,
+		maxDepth,
+	)
+	if err != nil {
+		return "", 0, err
+	}
+
+	ancestorHash, ancestorSlot := ancestor.Hash, ancestor.Slot
+	nextSlot := ancestorSlot + 1
+	log.Warn("Chain reorg detected",
+		"depth", len(orphaned),
+		"old_head", headHash,
+		"new_block", newBlock.BlockHash,
+		"new_block_slot", newBlock.Slot,
+		"ancestor", ancestorHash,
+	)
+
+	for _, b := range orphaned {
+		if err := rollbackBlock(dbTx, b); err != nil {
+			return "", 0, err
+		}
+	}
+
+	if err := dbTx.Where("slot >= ?", nextSlot).
+		Delete(&orm.Block{}).
+		Error; err != nil {
+		return "", 0, err
+	}
+
+	oldHeadSlot := uint64(0)
+	if len(orphaned) > 0 {
+		oldHeadSlot = orphaned[0].Slot
+	}
+
+	if err := dbTx.Model(&orm.Reorg{}).Create(&orm.Reorg{
+		Depth:        uint64(len(orphaned)),
+		OldHeadSlot:  oldHeadSlot,
+		OldHeadHash:  headHash,
+		NewHeadSlot:  newBlock.Slot,
+		NewHeadHash:  newBlock.BlockHash,
+		AncestorSlot: ancestorSlot,
+		AncestorHash: ancestorHash,
+	}).Error; err != nil {
+		return "", 0, err
+	}
+
+	if err := publishEvent(dbTx, orm.EventReorg, newBlock.Slot, &orm.ReorgEvent{
+		Depth:        uint64(len(orphaned)),
+		OldHeadSlot:  oldHeadSlot,
+		OldHeadHash:  headHash,
+		NewHeadSlot:  newBlock.Slot,
+		NewHeadHash:  newBlock.BlockHash,
+		AncestorSlot: ancestorSlot,
+		AncestorHash: ancestorHash,
+	}); err != nil {
+		return "", 0, err
+	}
+
+	return ancestorHash, nextSlot, updateChainStatus(dbTx, nextSlot, ancestorHash)
+}
+
+// findCommonAncestor walks back from headHash through the locally stored
+// parent hashes until it meets a block the node still has at the same
+// slot. It returns that ancestor and the orphaned blocks from the head
+// down. Reaching the indexed genesis without an ancestor means the node
+// serves another chain, e.g. after a network reset, which is refused
+// rather than unwinding the whole index.
+func findCommonAncestor(
+	ctx context.Context,
+	node chain.NodeClient,
+	dbTx *gorm.DB,
+	headHash string,
+	finalizedSlot uint64,
+	maxDepth uint64,
+) (*orm.Block, []*orm.Block, error) {
+	var orphaned []*orm.Block
+	for hash := headHash; hash != sha256.Zero.Hex(); {
+		b := &orm.Block{}
+		if err := dbTx.Model(&orm.Block{}).
+			Where("hash = ?", hash).
+			First(b).
+			Error; err != nil {
+			return nil, nil, err
+		}
+
+		nb, err := node.BlockBySlot(ctx, b.Slot)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if nb.BlockHash == b.Hash {
+			return b, orphaned, nil
+		}
+
+		if finalizedSlot > 0 && b.Slot <= finalizedSlot {
+			return nil, nil, errors.Wrapf(
+				errReorgBeyondFinalized,
+				"slot %d, finalized slot %d",
+				b.Slot,
+				finalizedSlot,
+			)
+		}
+
+		orphaned = append(orphaned, b)
+		if maxDepth > 0 && uint64(len(orphaned)) > maxDepth {
+			return nil, nil, errors.Wrapf(
+				errReorgTooDeep,
+				"max depth %d",
+				maxDepth,
+			)
+		}
+
+		hash = b.ParentHash
+	}
+
+	return nil, nil, errors.Wrapf(
+		errNoCommonAncestor,
+		"%d blocks orphaned",
+		len(orphaned),
+	)
+}
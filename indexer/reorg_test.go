@@ -0,0 +1,115 @@
+package indexer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestFindCommonAncestor(t *testing.T) {
+	cases := []struct {
+		name      string
+		fork      uint64
+		finalized uint64
+		maxDepth  uint64
+		// ancestor is the slot of the common ancestor.
+		ancestor uint64
+		orphaned []uint64
+		err      error
+	}{
+		{
+			name:     "ancestor on the chain",
+			fork:     2,
+			ancestor: 1,
+			orphaned: []uint64{3, 2},
+		},
+		{
+			name: "whole chain orphaned",
+			fork: 0,
+			err:  errNoCommonAncestor,
+		},
+		{
+			name:     "within the max depth",
+			fork:     2,
+			maxDepth: 2,
+			ancestor: 1,
+			orphaned: []uint64{3, 2},
+		},
+		{
+			name:     "beyond the max depth",
+			fork:     1,
+			maxDepth: 2,
+			err:      errReorgTooDeep,
+		},
+		{
+			name:      "beyond the finalized slot",
+			fork:      1,
+			finalized: 1,
+			err:       errReorgBeyondFinalized,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := newDB(t)
+			gw := chaintest.NewGateway()
+			defer gw.Close()
+
+			for i := 0; i < 4; i++ {
+				gw.AddBlock()
+			}
+			indexSlots(t, gw.Client(), db)
+
+			// The new branch replaces every slot from the fork.
+			gw.Fork(c.fork)
+			for s := c.fork; s < 4; s++ {
+				gw.AddBlock()
+			}
+
+			_, headHash, err := chainStatus(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ancestor, orphaned, err := findCommonAncestor(
+				context.Background(),
+				gw.Client(),
+				db,
+				headHash,
+				c.finalized,
+				c.maxDepth,
+			)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("err = %v, want %v", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ancestor.Slot != c.ancestor {
+				t.Errorf("ancestor = %v, want slot %d", ancestor, c.ancestor)
+			}
+
+			if got := blockSlots(orphaned); !reflect.DeepEqual(got, c.orphaned) {
+				t.Errorf("orphaned slots = %v, want %v", got, c.orphaned)
+			}
+		})
+	}
+}
+
+func blockSlots(bs []*orm.Block) []uint64 {
+	slots := make([]uint64, len(bs))
+	for i, b := range bs {
+		slots[i] = b.Slot
+	}
+
+	return slots
+}
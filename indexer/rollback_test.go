@@ -0,0 +1,283 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	"github.com/photon-storage/go-photon/config/config"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestReorgRollback(t *testing.T) {
+	cases := []struct {
+		name string
+		// setup runs before slot 0 is indexed, update before slot 1.
+		setup  func(t *testing.T, db *gorm.DB, gw *chaintest.Gateway)
+		base   []chaintest.BlockOption
+		update func(gw *chaintest.Gateway)
+		block  []chaintest.BlockOption
+		check  func(t *testing.T, db *gorm.DB)
+	}{
+		{
+			name: "transfer to a new account",
+			block: []chaintest.BlockOption{
+				chaintest.WithTxs(transferTx("t1", "alice", "bob", 10)),
+			},
+			check: func(t *testing.T, db *gorm.DB) {
+				if n := count(t, db, &orm.Account{}, "public_key = ?", "bob"); n != 0 {
+					t.Errorf("account created by the orphaned block kept")
+				}
+
+				alice := &orm.Account{}
+				if err := db.Where("public_key = ?", "alice").
+					First(alice).
+					Error; err != nil {
+					t.Fatal(err)
+				}
+				if alice.Balance != 100 || alice.Nonce != 0 {
+					t.Errorf("alice balance %d nonce %d, want 100 0",
+						alice.Balance, alice.Nonce)
+				}
+			},
+		},
+		{
+			name: "object commit",
+			setup: func(t *testing.T, db *gorm.DB, gw *chaintest.Gateway) {
+				createAccount(t, db, "depot", 50)
+				gw.SetStorageContract("t1", &gateway.StorageResp{
+					Owner:      "alice",
+					Depot:      "depot",
+					ObjectHash: "object/t1",
+					Status:     pbc.StorageStatus_CREATED.String(),
+					Fee:        5,
+					Pledge:     7,
+				})
+			},
+			block: []chaintest.BlockOption{
+				chaintest.WithTxs(commitTx("t1", "alice", "depot")),
+			},
+			check: func(t *testing.T, db *gorm.DB) {
+				for _, model := range []any{
+					&orm.StorageContract{},
+					&orm.StorageContractStatus{},
+					&orm.TransactionContract{},
+				} {
+					if n := count(t, db, model, "1 = 1"); n != 0 {
+						t.Errorf("%d %T rows kept", n, model)
+					}
+				}
+			},
+		},
+		{
+			name: "contract refresh without an auditor",
+			setup: func(t *testing.T, db *gorm.DB, gw *chaintest.Gateway) {
+				createAccount(t, db, "depot", 50)
+				gw.SetStorageContract("t1", &gateway.StorageResp{
+					Owner:      "alice",
+					Depot:      "depot",
+					ObjectHash: "object/t1",
+					Status:     pbc.StorageStatus_CREATED.String(),
+				})
+			},
+			base: []chaintest.BlockOption{
+				chaintest.WithTxs(commitTx("t1", "alice", "depot")),
+			},
+			update: func(gw *chaintest.Gateway) {
+				gw.SetStorageContract("t1", &gateway.StorageResp{
+					Owner:      "alice",
+					Depot:      "depot",
+					ObjectHash: "object/t1",
+					Status:     pbc.StorageStatus_OPEN.String(),
+				})
+			},
+			block: []chaintest.BlockOption{
+				chaintest.WithTxs(porTx("t2", "depot", "t1")),
+			},
+			check: func(t *testing.T, db *gorm.DB) {
+				sc := &orm.StorageContract{}
+				if err := db.First(sc).Error; err != nil {
+					t.Fatal(err)
+				}
+				if sc.Status != int32(pbc.StorageStatus_CREATED) {
+					t.Errorf("contract status %d, want CREATED", sc.Status)
+				}
+				if n := count(t, db, &orm.StorageContract{}, "auditor_id is null"); n != 1 {
+					t.Errorf("contract auditor restored as non null")
+				}
+				if n := count(t, db, &orm.StorageContractStatus{}, "1 = 1"); n != 1 {
+					t.Errorf("%d contract statuses, want the commit one", n)
+				}
+				if n := count(t, db, &orm.StorageProof{}, "1 = 1"); n != 0 {
+					t.Errorf("%d orphaned proofs kept", n)
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := newDB(t)
+			gw := chaintest.NewGateway()
+			defer gw.Close()
+
+			createAccount(t, db, "alice", 100)
+			if c.setup != nil {
+				c.setup(t, db, gw)
+			}
+
+			gw.AddBlock(c.base...)
+			indexSlots(t, gw.Client(), db)
+			if c.update != nil {
+				c.update(gw)
+			}
+
+			gw.AddBlock(c.block...)
+			indexSlots(t, gw.Client(), db)
+
+			// Replace slot 1 with a longer branch.
+			gw.Fork(1)
+			gw.AddBlock()
+			head := gw.AddBlock()
+			indexSlots(t, gw.Client(), db)
+
+			nextSlot, currentHash, err := chainStatus(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if nextSlot != 3 || currentHash != head.BlockHash {
+				t.Errorf("chain status %d %s, want 3 %s",
+					nextSlot, currentHash, head.BlockHash)
+			}
+
+			blocks := db.Model(&orm.Block{}).Select("id")
+			if n := count(t, db, &orm.Transaction{}, "block_id not in (?)", blocks); n != 0 {
+				t.Errorf("%d orphaned transactions kept", n)
+			}
+			if n := count(t, db, &orm.StateJournal{}, "block_id not in (?)", blocks); n != 0 {
+				t.Errorf("%d orphaned journal entries left", n)
+			}
+			if n := count(t, db, &orm.Reorg{}, "depth = 1"); n != 1 {
+				t.Errorf("%d reorgs of depth 1 recorded, want 1", n)
+			}
+			checkLedger(t, db)
+
+			c.check(t, db)
+		})
+	}
+}
+
+func TestReorgRollbackReconciliation(t *testing.T) {
+	db := newDB(t)
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	createAccount(t, db, alicePK, 100)
+	gw.SetValidators(&gateway.ValidatorsResp{
+		Validators: []*gateway.ValidatorResp{{PublicKey: alicePK}},
+	})
+
+	// The block starting epoch 1 is rolled back after the epoch
+	// processing reconciled the balance of its sender with a reward.
+	spe := uint64(config.Consensus().SlotsPerEpoch)
+	for s := uint64(0); s < spe; s++ {
+		gw.AddBlock()
+	}
+	head := gw.AddBlock(chaintest.WithTxs(transferTx("t1", alicePK, bobPK, 10)))
+	indexSlots(t, gw.Client(), db)
+
+	gw.SetAccount(alicePK, &gateway.AccountResp{Nonce: 1, Balance: 109})
+	if err := db.Transaction(func(dbTx *gorm.DB) error {
+		return processEpoch(
+			context.Background(),
+			gw.Client(),
+			dbTx,
+			spe,
+			head.BlockHash,
+		)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checkLedger(t, db)
+
+	gw.Fork(spe)
+	gw.AddBlock()
+	gw.AddBlock()
+	indexSlots(t, gw.Client(), db)
+
+	alice := &orm.Account{}
+	if err := db.Where("public_key = ?", alicePK).First(alice).Error; err != nil {
+		t.Fatal(err)
+	}
+	if alice.Balance != 100 {
+		t.Errorf("alice balance = %d, want 100", alice.Balance)
+	}
+	checkLedger(t, db)
+}
+
+func TestReorgRollbackEpochRefresh(t *testing.T) {
+	db := newDB(t)
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	createAccount(t, db, "alice", 100)
+	createAccount(t, db, "depot", 50)
+	gw.SetStorageContract("t1", &gateway.StorageResp{
+		Owner:      "alice",
+		Depot:      "depot",
+		ObjectHash: "object/t1",
+		Status:     pbc.StorageStatus_CREATED.String(),
+	})
+
+	// The block starting epoch 1 is rolled back after the epoch
+	// processing refreshed the contract committed at slot 0.
+	spe := uint64(config.Consensus().SlotsPerEpoch)
+	gw.AddBlock(chaintest.WithTxs(commitTx("t1", "alice", "depot")))
+	for s := uint64(1); s <= spe; s++ {
+		gw.AddBlock()
+	}
+	indexSlots(t, gw.Client(), db)
+
+	gw.SetStorageContract("t1", &gateway.StorageResp{
+		Owner:      "alice",
+		Depot:      "depot",
+		ObjectHash: "object/t1",
+		Status:     pbc.StorageStatus_OPEN.String(),
+	})
+	if err := db.Transaction(func(dbTx *gorm.DB) error {
+		return processEpoch(
+			context.Background(),
+			gw.Client(),
+			dbTx,
+			spe,
+			gw.Block(spe).BlockHash,
+		)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := count(t, db, &orm.StorageContractStatus{}, "1 = 1"); n != 2 {
+		t.Fatalf("%d contract statuses after the refresh, want 2", n)
+	}
+
+	gw.Fork(spe)
+	gw.AddBlock()
+	gw.AddBlock()
+	indexSlots(t, gw.Client(), db)
+
+	sc := &orm.StorageContract{}
+	if err := db.First(sc).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sc.Status != int32(pbc.StorageStatus_CREATED) {
+		t.Errorf("contract status %d, want CREATED", sc.Status)
+	}
+	if n := count(t, db, &orm.StorageContractStatus{}, "1 = 1"); n != 1 {
+		t.Errorf("%d contract statuses, want the commit one", n)
+	}
+}
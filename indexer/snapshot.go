@@ -0,0 +1,131 @@
+package indexer
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/crypto/sha256"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/snapshot"
+)
+
+var (
+	errSnapshotWritten      = errors.New("snapshot written")
+	errSnapshotNotFinalized = errors.New("no finalized slot to snapshot")
+)
+
+// ExportSnapshot writes the snapshot of the index at the finalized slot
+// to w, from which a replica resumes indexing with no reorg to unwind.
+//
+// The blocks past the finalized slot are rolled back from their state
+// journal in a DB transaction that is itself rolled back once the
+// snapshot is written, so the index is left untouched. The transaction
+// holds the chain status row, which pauses a running indexer for the
+// duration of the export. The per epoch refreshes past the finalized
+// slot are kept, as a reorg keeps them.
+func ExportSnapshot(db *gorm.DB, w io.Writer) (*snapshot.Manifest, error) {
+	var m *snapshot.Manifest
+	if err := db.Transaction(func(dbTx *gorm.DB) error {
+		if err := dbTx.Model(&orm.ChainStatus{}).
+			Where("id = 1").
+			Update("updated_at", time.Now()).
+			Error; err != nil {
+			return err
+		}
+
+		if err := rewindToFinalized(dbTx); err != nil {
+			return err
+		}
+
+		var err error
+		if m, err = snapshot.Export(dbTx, w); err != nil {
+			return err
+		}
+
+		return errSnapshotWritten
+	}); err != nil && err != errSnapshotWritten {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// rewindToFinalized rolls back the indexed blocks past the finalized
+// slot and moves the chain status back to it. The rows of the unwound
+// blocks are deleted for good rather than kept as orphans.
+func rewindToFinalized(dbTx *gorm.DB) error {
+	cs := &orm.ChainStatus{}
+	if err := dbTx.Model(cs).First(cs).Error; err != nil {
+		return err
+	}
+
+	if cs.FinalizedHash == "" {
+		return errSnapshotNotFinalized
+	}
+
+	// The index may still be catching up with the finalized slot.
+	if cs.NextSlot <= cs.FinalizedSlot+1 {
+		return nil
+	}
+
+	blocks := make([]*orm.Block, 0)
+	if err := dbTx.Model(&orm.Block{}).
+		Where("slot > ?", cs.FinalizedSlot).
+		Order("slot desc").
+		Find(&blocks).
+		Error; err != nil {
+		return err
+	}
+
+	ids := make([]uint64, 0, len(blocks))
+	for _, b := range blocks {
+		if err := rollbackBlock(dbTx, b); err != nil {
+			return err
+		}
+		ids = append(ids, b.ID)
+	}
+
+	if len(ids) > 0 {
+		if err := dbTx.Where("block_id in ?", ids).
+			Delete(&orm.AccountLedger{}).
+			Error; err != nil {
+			return err
+		}
+
+		if err := dbTx.Unscoped().
+			Where("block_id in ?", ids).
+			Delete(&orm.Attestation{}).
+			Error; err != nil {
+			return err
+		}
+
+		if err := dbTx.Unscoped().
+			Where("id in ?", ids).
+			Delete(&orm.Block{}).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	heads := make([]*orm.Block, 0)
+	if err := dbTx.Model(&orm.Block{}).
+		Where("slot <= ?", cs.FinalizedSlot).
+		Where("hash <> ''").
+		Order("slot desc").
+		Limit(1).
+		Find(&heads).
+		Error; err != nil {
+		return err
+	}
+
+	hash := sha256.Zero.Hex()
+	if len(heads) > 0 {
+		hash = heads[0].Hash
+	}
+
+	return updateChainStatus(dbTx, cs.FinalizedSlot+1, hash)
+}
@@ -6,6 +6,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/photon-storage/go-common/log"
 	"github.com/photon-storage/go-photon/chain/gateway"
 	fieldparams "github.com/photon-storage/go-photon/config/fieldparams"
 	pbc "github.com/photon-storage/photon-proto/consensus"
@@ -16,8 +17,9 @@ import (
 
 func processTransactions(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
 	blockID uint64,
 	block *gateway.BlockResp,
 ) error {
@@ -35,7 +37,7 @@ func processTransactions(
 		gasUsage := uint64(0)
 		switch tx.Type {
 		case pbc.TxType_BALANCE_TRANSFER.String():
-			if err := processBalanceTransferTx(dbTx, tx); err != nil {
+			if err := processBalanceTransferTx(dbTx, j, tx); err != nil {
 				return err
 			}
 
@@ -45,27 +47,54 @@ func processTransactions(
 				ctx,
 				node,
 				dbTx,
+				j,
 				txID,
 				tx.TxHash,
 				block.BlockHash,
+				block.Slot,
 			); err != nil {
 				return err
 			}
 
 			gasUsage = fieldparams.ObjectCommitGas
 		case pbc.TxType_OBJECT_AUDIT.String():
-			if err := processObjectAuditTx(dbTx, txID, tx.ObjectAudit.Hash); err != nil {
+			if err := processObjectAuditTx(
+				ctx,
+				node,
+				dbTx,
+				j,
+				txID,
+				tx.ObjectAudit.CommitTxHash,
+				block,
+			); err != nil {
 				return err
 			}
 
 			gasUsage = fieldparams.ObjectAuditGas
+		case pbc.TxType_OBJECT_POR.String():
+			if err := processObjectPoRTx(
+				ctx,
+				node,
+				dbTx,
+				j,
+				txID,
+				fromID,
+				tx.ObjectPoR.CommitTxHash,
+				block,
+			); err != nil {
+				return err
+			}
+
+			gasUsage = fieldparams.ObjectPoRGas
 		case pbc.TxType_VALIDATOR_DEPOSIT.String():
 			if err := processValidatorDepositTx(
 				ctx,
 				node,
 				dbTx,
+				j,
 				tx.From,
 				tx.ValidatorDeposit.Amount,
+				tx.TxHash,
 			); err != nil {
 				return err
 			}
@@ -76,8 +105,10 @@ func processTransactions(
 				ctx,
 				node,
 				dbTx,
+				j,
 				tx.From,
 				tx.AuditorDeposit.Amount,
+				tx.TxHash,
 			); err != nil {
 				return err
 			}
@@ -85,6 +116,23 @@ func processTransactions(
 			gasUsage = fieldparams.AuditorDepositGas
 		}
 
+		if err := updateTransactionCounterparty(dbTx, txID, tx); err != nil {
+			return err
+		}
+
+		if err := createTransactionParticipants(
+			dbTx,
+			txID,
+			fromID,
+			tx,
+		); err != nil {
+			return err
+		}
+
+		if err := j.accountByID(fromID); err != nil {
+			return err
+		}
+
 		if err := dbTx.Model(&orm.Account{}).
 			Where("id", fromID).
 			Updates(map[string]interface{}{
@@ -93,6 +141,15 @@ func processTransactions(
 			}).Error; err != nil {
 			return err
 		}
+
+		if err := j.ledger(
+			fromID,
+			orm.LedgerGasFee,
+			tx.TxHash,
+			-int64(tx.GasPrice*gasUsage),
+		); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -100,22 +157,45 @@ func processTransactions(
 
 func processBalanceTransferTx(
 	dbTx *gorm.DB,
+	j *journal,
 	tx *gateway.Tx,
 ) error {
 	amount := int64(tx.BalanceTransfer.Amount)
-	if err := updateAccountBalance(dbTx, tx.From, -amount); err != nil {
+	if err := updateAccountBalance(
+		dbTx,
+		j,
+		tx.From,
+		-amount,
+		orm.LedgerTransferOut,
+		tx.TxHash,
+	); err != nil {
 		return err
 	}
 
 	to := &orm.Account{PublicKey: tx.BalanceTransfer.To}
 	if err := dbTx.Model(&orm.Account{}).
 		Where(to).
-		FirstOrCreate(to).
-		Error; err != nil {
+		First(to).
+		Error; err != nil && err != gorm.ErrRecordNotFound {
 		return err
+	} else if err == gorm.ErrRecordNotFound {
+		if err := dbTx.Model(&orm.Account{}).Create(to).Error; err != nil {
+			return err
+		}
+
+		if err := j.created(entityAccount, to.ID); err != nil {
+			return err
+		}
 	}
 
-	return updateAccountBalance(dbTx, to.PublicKey, amount)
+	return updateAccountBalance(
+		dbTx,
+		j,
+		to.PublicKey,
+		amount,
+		orm.LedgerTransferIn,
+		tx.TxHash,
+	)
 }
 
 func createTransaction(
@@ -125,68 +205,261 @@ func createTransaction(
 	position uint64,
 	tx *gateway.Tx,
 ) (uint64, error) {
-	raw, err := json.Marshal(tx)
+	ormTx, err := newTransaction(fromID, blockID, position, tx)
 	if err != nil {
 		return 0, err
 	}
 
-	ormTx := &orm.Transaction{
+	if err := dbTx.Model(&orm.Transaction{}).Create(ormTx).Error; err != nil {
+		return 0, err
+	}
+
+	return ormTx.ID, nil
+}
+
+func newTransaction(
+	fromID uint64,
+	blockID uint64,
+	position uint64,
+	tx *gateway.Tx,
+) (*orm.Transaction, error) {
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orm.Transaction{
 		BlockID:       blockID,
 		Hash:          tx.TxHash,
 		FromAccountID: fromID,
 		Position:      position,
 		GasPrice:      tx.GasPrice,
+		Amount:        transactionAmount(tx),
 		Type:          pbc.TxType_value[tx.Type],
 		Raw:           raw,
+	}, nil
+}
+
+// transactionAmount returns the amount of PHO a transaction moves, the
+// fee for object commits.
+func transactionAmount(tx *gateway.Tx) uint64 {
+	switch tx.Type {
+	case pbc.TxType_BALANCE_TRANSFER.String():
+		return tx.BalanceTransfer.Amount
+	case pbc.TxType_OBJECT_COMMIT.String():
+		return tx.ObjectCommit.Fee
+	case pbc.TxType_VALIDATOR_DEPOSIT.String():
+		return tx.ValidatorDeposit.Amount
+	case pbc.TxType_AUDITOR_DEPOSIT.String():
+		return tx.AuditorDeposit.Amount
 	}
 
-	if err := dbTx.Model(&orm.Transaction{}).Create(ormTx).Error; err != nil {
-		return 0, err
+	return 0
+}
+
+// updateTransactionCounterparty records the account on the other side of
+// a transaction: the recipient of a transfer, the owner or depot of an
+// object commit and the auditor or depot of an object audit, whichever
+// isn't the sender. It runs after the transaction is processed so the
+// recipient account of a transfer exists.
+func updateTransactionCounterparty(
+	dbTx *gorm.DB,
+	txID uint64,
+	tx *gateway.Tx,
+) error {
+	var candidates []string
+	switch tx.Type {
+	case pbc.TxType_BALANCE_TRANSFER.String():
+		candidates = []string{tx.BalanceTransfer.To}
+	case pbc.TxType_OBJECT_COMMIT.String():
+		candidates = []string{tx.ObjectCommit.Owner, tx.ObjectCommit.Depot}
+	case pbc.TxType_OBJECT_AUDIT.String():
+		candidates = []string{tx.ObjectAudit.Auditor, tx.ObjectAudit.Depot}
 	}
 
-	return ormTx.ID, nil
+	for _, pk := range candidates {
+		if pk == "" || pk == tx.From {
+			continue
+		}
+
+		accountID, err := getAccountIDByPublicKey(dbTx, pk)
+		if err == gorm.ErrRecordNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		return dbTx.Model(&orm.Transaction{}).
+			Where("id = ?", txID).
+			Update("to_account_id", accountID).
+			Error
+	}
+
+	return nil
+}
+
+// createTransactionParticipants records the accounts taking part in a
+// transaction: the sender, the recipient of a transfer, the auditor
+// named by an audit and the owner, depot and auditor of the storage
+// contracts the transaction is linked to.
+func createTransactionParticipants(
+	dbTx *gorm.DB,
+	txID uint64,
+	fromID uint64,
+	tx *gateway.Tx,
+) error {
+	var participants []*orm.TransactionParticipant
+	seen := make(map[orm.TransactionParticipant]bool)
+	add := func(accountID uint64, role string) {
+		key := orm.TransactionParticipant{AccountID: accountID, Role: role}
+		if accountID == 0 || seen[key] {
+			return
+		}
+
+		seen[key] = true
+		participants = append(participants, &orm.TransactionParticipant{
+			TransactionID: txID,
+			AccountID:     accountID,
+			Role:          role,
+		})
+	}
+
+	add(fromID, orm.RoleSender)
+
+	named := make(map[string]string)
+	switch tx.Type {
+	case pbc.TxType_BALANCE_TRANSFER.String():
+		named[orm.RoleRecipient] = tx.BalanceTransfer.To
+	case pbc.TxType_OBJECT_AUDIT.String():
+		named[orm.RoleAuditor] = tx.ObjectAudit.Auditor
+	}
+
+	for role, pk := range named {
+		if pk == "" {
+			continue
+		}
+
+		accountID, err := getAccountIDByPublicKey(dbTx, pk)
+		if err == gorm.ErrRecordNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		add(accountID, role)
+	}
+
+	scs := make([]*orm.StorageContract, 0)
+	if err := dbTx.Model(&orm.StorageContract{}).
+		Joins("join transaction_contracts as tc on tc.contract_id = storage_contracts.id").
+		Where("tc.transaction_id = ?", txID).
+		Find(&scs).
+		Error; err != nil {
+		return err
+	}
+
+	for _, sc := range scs {
+		add(sc.OwnerID, orm.RoleOwner)
+		add(sc.DepotID, orm.RoleDepot)
+		add(sc.AuditorID, orm.RoleAuditor)
+	}
+
+	return dbTx.Model(&orm.TransactionParticipant{}).
+		Create(participants).
+		Error
 }
 
 func processObjectCommitTx(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
 	txID uint64,
 	txHash string,
 	blockHash string,
+	slot uint64,
 ) error {
 	sc, err := node.StorageContract(ctx, txHash, blockHash)
 	if err != nil {
 		return err
 	}
 
-	ownerID, err := getAccountIDByPublicKey(dbTx, sc.Owner)
+	storage, err := newStorageContract(dbTx, txID, sc)
 	if err != nil {
 		return err
 	}
 
-	depotID, err := getAccountIDByPublicKey(dbTx, sc.Depot)
-	if err != nil {
+	if err := updateAccountBalance(
+		dbTx,
+		j,
+		sc.Owner,
+		-int64(sc.Fee),
+		orm.LedgerContractFee,
+		txHash,
+	); err != nil {
 		return err
 	}
 
-	auditorID := uint64(0)
-	if sc.Auditor != "" {
-		auditorID, err = getAccountIDByPublicKey(dbTx, sc.Auditor)
-		if err != nil {
-			return err
-		}
+	if err := updateAccountBalance(
+		dbTx,
+		j,
+		sc.Depot,
+		-int64(sc.Pledge),
+		orm.LedgerDepotPledge,
+		txHash,
+	); err != nil {
+		return err
 	}
 
-	if err := updateAccountBalance(dbTx, sc.Owner, -int64(sc.Fee)); err != nil {
+	if err := dbTx.Model(&orm.StorageContract{}).Create(storage).Error; err != nil {
 		return err
 	}
 
-	if err := updateAccountBalance(dbTx, sc.Depot, -int64(sc.Pledge)); err != nil {
+	if err := j.created(entityStorageContract, storage.ID); err != nil {
 		return err
 	}
 
-	storage := &orm.StorageContract{
+	if err := createContractStatus(
+		dbTx,
+		j,
+		storage.ID,
+		int32(pbc.StorageStatus_STORAGE_INVALID),
+		storage.Status,
+		slot,
+		txID,
+	); err != nil {
+		return err
+	}
+
+	return createTransactionContract(dbTx, j, txID, storage.ID)
+}
+
+// newStorageContract returns the storage contract committed by the
+// transaction with its accounts resolved.
+func newStorageContract(
+	dbTx *gorm.DB,
+	txID uint64,
+	sc *gateway.StorageResp,
+) (*orm.StorageContract, error) {
+	ownerID, err := getAccountIDByPublicKey(dbTx, sc.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	depotID, err := getAccountIDByPublicKey(dbTx, sc.Depot)
+	if err != nil {
+		return nil, err
+	}
+
+	auditorID := uint64(0)
+	if sc.Auditor != "" {
+		auditorID, err = getAccountIDByPublicKey(dbTx, sc.Auditor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &orm.StorageContract{
 		CommitTransactionID: txID,
 		OwnerID:             ownerID,
 		DepotID:             depotID,
@@ -198,43 +471,161 @@ func processObjectCommitTx(
 		Pledge:              sc.Pledge,
 		StartSlot:           sc.Start,
 		EndSlot:             sc.End,
+	}, nil
+}
+
+// processObjectAuditTx links the audit to the contract of its commit
+// transaction. The same object can be committed more than once, so the
+// object hash doesn't identify the contract.
+func processObjectAuditTx(
+	ctx context.Context,
+	node chain.NodeClient,
+	dbTx *gorm.DB,
+	j *journal,
+	txID uint64,
+	commitTxHash string,
+	block *gateway.BlockResp,
+) error {
+	sc, err := contractByCommitTx(dbTx, commitTxHash)
+	if err != nil {
+		return err
 	}
 
-	if err := dbTx.Model(&orm.StorageContract{}).Create(storage).Error; err != nil {
+	if sc == nil {
+		log.Warn("Storage contract of audit not found",
+			"commit_tx_hash", commitTxHash,
+		)
+		return nil
+	}
+
+	if err := createTransactionContract(dbTx, j, txID, sc.ID); err != nil {
 		return err
 	}
 
-	return dbTx.Model(&orm.TransactionContract{}).
-		Create(&orm.TransactionContract{
-			TransactionID: txID,
-			ContractID:    storage.ID,
-		}).Error
+	return refreshStorageContract(
+		ctx,
+		node,
+		dbTx,
+		j,
+		sc,
+		block.BlockHash,
+		block.Slot,
+		txID,
+	)
+}
+
+func processObjectPoRTx(
+	ctx context.Context,
+	node chain.NodeClient,
+	dbTx *gorm.DB,
+	j *journal,
+	txID uint64,
+	depotID uint64,
+	commitTxHash string,
+	block *gateway.BlockResp,
+) error {
+	sc, err := contractByCommitTx(dbTx, commitTxHash)
+	if err != nil {
+		return err
+	}
+
+	if sc == nil {
+		log.Warn("Storage contract of proof of retrievability not found",
+			"commit_tx_hash", commitTxHash,
+		)
+		return nil
+	}
+
+	if err := createTransactionContract(dbTx, j, txID, sc.ID); err != nil {
+		return err
+	}
+
+	proof := &orm.StorageProof{
+		ContractID:    sc.ID,
+		TransactionID: txID,
+		DepotID:       depotID,
+		Slot:          block.Slot,
+	}
+	if err := dbTx.Model(&orm.StorageProof{}).Create(proof).Error; err != nil {
+		return err
+	}
+
+	if err := j.created(entityStorageProof, proof.ID); err != nil {
+		return err
+	}
+
+	return refreshStorageContract(
+		ctx,
+		node,
+		dbTx,
+		j,
+		sc,
+		block.BlockHash,
+		block.Slot,
+		txID,
+	)
 }
 
-func processObjectAuditTx(dbTx *gorm.DB, txID uint64, hash string) error {
-	contractID := uint64(0)
+// contractByCommitTx returns the storage contract created by the commit
+// transaction, nil if it isn't indexed.
+func contractByCommitTx(
+	dbTx *gorm.DB,
+	commitTxHash string,
+) (*orm.StorageContract, error) {
+	scs := make([]*orm.StorageContract, 0)
 	if err := dbTx.Model(&orm.StorageContract{}).
-		Where("object_hash = ?", hash).
-		Pluck("id", &contractID).
+		Preload("CommitTransaction").
+		Joins("join transactions as t on t.id = storage_contracts.commit_transaction_id").
+		Where("t.hash = ?", commitTxHash).
+		Limit(1).
+		Find(&scs).
+		Error; err != nil {
+		return nil, err
+	}
+
+	if len(scs) == 0 {
+		return nil, nil
+	}
+
+	return scs[0], nil
+}
+
+func createTransactionContract(
+	dbTx *gorm.DB,
+	j *journal,
+	txID uint64,
+	contractID uint64,
+) error {
+	tc := &orm.TransactionContract{
+		TransactionID: txID,
+		ContractID:    contractID,
+	}
+	if err := dbTx.Model(&orm.TransactionContract{}).
+		Create(tc).
 		Error; err != nil {
 		return err
 	}
 
-	return dbTx.Model(&orm.TransactionContract{}).
-		Create(&orm.TransactionContract{
-			TransactionID: txID,
-			ContractID:    contractID,
-		}).Error
+	return j.created(entityTransactionContract, tc.ID)
 }
 
 func processValidatorDepositTx(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
 	pk string,
 	amount uint64,
+	txHash string,
 ) error {
-	if err := updateAccountBalance(dbTx, pk, -int64(amount)); err != nil {
+	if err := updateAccountBalance(
+		dbTx,
+		j,
+		pk,
+		-int64(amount),
+		orm.LedgerDeposit,
+		txHash,
+	); err != nil {
 		return err
 	}
 
@@ -249,6 +640,10 @@ func processValidatorDepositTx(
 		Error; err != nil && err != gorm.ErrRecordNotFound {
 		return err
 	} else if err == nil {
+		if err := j.validator("account_id = ?", accountID); err != nil {
+			return err
+		}
+
 		return dbTx.Model(&orm.Validator{}).
 			Where("account_id = ?", accountID).
 			Update("deposit", gorm.Expr("deposit + ?", amount)).
@@ -260,24 +655,38 @@ func processValidatorDepositTx(
 		return err
 	}
 
-	return dbTx.Model(&orm.Validator{}).Create(&orm.Validator{
+	v := &orm.Validator{
 		AccountID:       accountID,
 		Index:           validator.Index,
 		Deposit:         amount,
 		Status:          pbc.ValidatorStatus_value[validator.Status],
-		ActivationEpoch: validator.ActivationEpoch,
-		ExitEpoch:       validator.ExitEpoch,
-	}).Error
+		ActivationEpoch: dbEpoch(validator.ActivationEpoch),
+		ExitEpoch:       dbEpoch(validator.ExitEpoch),
+	}
+	if err := dbTx.Model(&orm.Validator{}).Create(v).Error; err != nil {
+		return err
+	}
+
+	return j.created(entityValidator, v.ID)
 }
 
 func processAuditorDepositTx(
 	ctx context.Context,
-	node *chain.NodeClient,
+	node chain.NodeClient,
 	dbTx *gorm.DB,
+	j *journal,
 	pk string,
 	amount uint64,
+	txHash string,
 ) error {
-	if err := updateAccountBalance(dbTx, pk, -int64(amount)); err != nil {
+	if err := updateAccountBalance(
+		dbTx,
+		j,
+		pk,
+		-int64(amount),
+		orm.LedgerDeposit,
+		txHash,
+	); err != nil {
 		return err
 	}
 
@@ -292,6 +701,10 @@ func processAuditorDepositTx(
 		Error; err != nil && err != gorm.ErrRecordNotFound {
 		return err
 	} else if err == nil {
+		if err := j.auditor("account_id = ?", accountID); err != nil {
+			return err
+		}
+
 		return dbTx.Model(&orm.Auditor{}).
 			Where("account_id = ?", accountID).
 			Update("deposit", gorm.Expr("deposit + ?", amount)).
@@ -303,11 +716,16 @@ func processAuditorDepositTx(
 		return err
 	}
 
-	return dbTx.Model(&orm.Auditor{}).Create(&orm.Auditor{
+	a := &orm.Auditor{
 		AccountID:       accountID,
 		Deposit:         amount,
 		Status:          pbc.AuditorStatus_value[auditor.Status],
-		ActivationEpoch: auditor.ActivationEpoch,
-		ExitEpoch:       auditor.ExitEpoch,
-	}).Error
+		ActivationEpoch: dbEpoch(auditor.ActivationEpoch),
+		ExitEpoch:       dbEpoch(auditor.ExitEpoch),
+	}
+	if err := dbTx.Model(&orm.Auditor{}).Create(a).Error; err != nil {
+		return err
+	}
+
+	return j.created(entityAuditor, a.ID)
 }
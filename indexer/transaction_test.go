@@ -0,0 +1,81 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestObjectAuditOfRecommittedObject(t *testing.T) {
+	db := newDB(t)
+	gw := chaintest.NewGateway()
+	defer gw.Close()
+
+	createAccount(t, db, "alice", 100)
+	createAccount(t, db, "depot", 50)
+	createAccount(t, db, "auditor", 50)
+	for _, hash := range []string{"c1", "c2"} {
+		gw.SetStorageContract(hash, &gateway.StorageResp{
+			Owner:      "alice",
+			Depot:      "depot",
+			ObjectHash: "object",
+			Status:     pbc.StorageStatus_CREATED.String(),
+		})
+	}
+
+	// The object is committed twice, the audit is of the first commit.
+	first, second := commitTx("c1", "alice", "depot"), commitTx("c2", "alice", "depot")
+	first.ObjectCommit.Hash, second.ObjectCommit.Hash = "object", "object"
+	gw.AddBlock(chaintest.WithTxs(first))
+	gw.AddBlock(chaintest.WithTxs(second))
+	indexSlots(t, gw.Client(), db)
+
+	gw.SetStorageContract("c1", &gateway.StorageResp{
+		Owner:      "alice",
+		Depot:      "depot",
+		Auditor:    "auditor",
+		ObjectHash: "object",
+		Status:     pbc.StorageStatus_OPEN.String(),
+	})
+	audit := auditTx("a1", "auditor", "depot", "c1")
+	audit.ObjectAudit.Hash = "object"
+	gw.AddBlock(chaintest.WithTxs(audit))
+	indexSlots(t, gw.Client(), db)
+
+	scs := make([]*orm.StorageContract, 0)
+	if err := db.Preload("CommitTransaction").
+		Order("id asc").
+		Find(&scs).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sc := range scs {
+		want := pbc.StorageStatus_CREATED
+		if sc.CommitTransaction.Hash == "c1" {
+			want = pbc.StorageStatus_OPEN
+		}
+		if sc.Status != int32(want) {
+			t.Errorf("contract of %s status %d, want %s",
+				sc.CommitTransaction.Hash, sc.Status, want)
+		}
+	}
+
+	linked := make([]string, 0)
+	if err := db.Model(&orm.TransactionContract{}).
+		Joins("join transactions as t on t.id = transaction_contracts.transaction_id").
+		Joins("join storage_contracts as sc on sc.id = transaction_contracts.contract_id").
+		Joins("join transactions as c on c.id = sc.commit_transaction_id").
+		Where("t.hash = ?", "a1").
+		Pluck("c.hash", &linked).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(linked) != 1 || linked[0] != "c1" {
+		t.Errorf("audit linked to the contracts of %v, want c1", linked)
+	}
+}
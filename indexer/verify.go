@@ -0,0 +1,616 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+var (
+	// syncAttempts is the number of times a verification batch or a
+	// reindex waits for the index to catch up with the node head before
+	// it fails.
+	syncAttempts = 30
+	syncWait     = 2 * time.Second
+
+	errNotSynced = errors.New("index not at the node head")
+)
+
+// VerifyConfig defines the consistency verification configuration.
+type VerifyConfig struct {
+	// Interval is the interval of the background verification, 0
+	// disables it.
+	Interval time.Duration `yaml:"interval"`
+	// SampleSize is the number of rows of every table checked by a run,
+	// continuing after the last row checked by the previous run. 0
+	// checks every row.
+	SampleSize int `yaml:"sample_size"`
+	// Repair overwrites the mismatching rows with the node state.
+	Repair bool `yaml:"repair"`
+}
+
+// Mismatch is a field of an indexed row that differs from the node.
+type Mismatch struct {
+	// Slot is the indexed head the row was compared at.
+	Slot   uint64
+	Entity string
+	// Key identifies the row, a public key or the commit transaction hash
+	// of a storage contract.
+	Key      string
+	Field    string
+	Indexed  string
+	Node     string
+	Repaired bool
+}
+
+// VerifyReport lists the mismatches found by a verification.
+type VerifyReport struct {
+	// Checked is the number of rows checked per table.
+	Checked    map[string]int
+	Mismatches []*Mismatch
+}
+
+// verifyTables are the tables compared with the node, in order. A table
+// checks the rows of its batch following lastID.
+var verifyTables = []struct {
+	entity string
+	verify func(b *verifyBatch, lastID uint64, limit int) error
+}{
+	{entityAccount, (*verifyBatch).accounts},
+	{entityValidator, (*verifyBatch).validators},
+	{entityAuditor, (*verifyBatch).auditors},
+	{entityStorageContract, (*verifyBatch).storageContracts},
+}
+
+// Verifier compares the accounts, validators, auditors and storage
+// contracts of the index with the node. The balances and nonces are
+// maintained incrementally from the transactions and the balances only
+// reconciled for the validators and auditors every epoch, the verifier
+// catches the drift of every other row.
+type Verifier struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    VerifyConfig
+	db     *gorm.DB
+	node   chain.NodeClient
+	// cursors are the last row ids checked per table by the sampled
+	// runs.
+	cursors map[string]uint64
+}
+
+// NewVerifier returns the new instance of Verifier.
+func NewVerifier(
+	ctx context.Context,
+	cfg VerifyConfig,
+	node chain.NodeClient,
+	db *gorm.DB,
+) *Verifier {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Verifier{
+		ctx:     ctx,
+		cancel:  cancel,
+		cfg:     cfg,
+		db:      db,
+		node:    node,
+		cursors: make(map[string]uint64),
+	}
+}
+
+// Run verifies at the configured interval until the verifier is stopped.
+func (v *Verifier) Run() {
+	ticker := time.NewTicker(v.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-v.ctx.Done():
+			return
+
+		case <-ticker.C:
+
+		}
+
+		report, err := v.Verify()
+		if err != nil {
+			if v.ctx.Err() == nil {
+				log.Error("Error verifying the index", "error", err)
+			}
+			continue
+		}
+
+		for _, m := range report.Mismatches {
+			log.Warn("Indexed state differs from the node",
+				"slot", m.Slot,
+				"entity", m.Entity,
+				"key", m.Key,
+				"field", m.Field,
+				"indexed", m.Indexed,
+				"node", m.Node,
+				"repaired", m.Repaired,
+			)
+		}
+	}
+}
+
+// Stop exits the verifier.
+func (v *Verifier) Stop() {
+	v.cancel()
+}
+
+// Verify compares a sample of every table, or every row when no sample
+// size is configured, with the node and repairs the mismatches if
+// configured.
+//
+// The node serves the account, validator and auditor state at its head
+// only, so rows are compared in batches while the indexed head matches
+// the node head, waiting for the index to catch up otherwise. A repairing
+// batch holds the chain status row, which keeps the indexer from
+// applying a slot in between.
+func (v *Verifier) Verify() (*VerifyReport, error) {
+	report := &VerifyReport{
+		Checked: make(map[string]int),
+	}
+
+	for _, t := range verifyTables {
+		lastID, remaining, mismatches := v.cursors[t.entity], v.cfg.SampleSize, 0
+		for v.cfg.SampleSize == 0 || remaining > 0 {
+			limit := defaultPageSize
+			if v.cfg.SampleSize > 0 && remaining < limit {
+				limit = remaining
+			}
+
+			b, err := v.batch(t.verify, lastID, limit)
+			if err != nil {
+				return nil, errors.Wrap(err, t.entity)
+			}
+
+			report.Checked[t.entity] += b.count
+			report.Mismatches = append(report.Mismatches, b.mismatches...)
+			mismatches += len(b.mismatches)
+			if v.cfg.Repair {
+				verifyRepairs.WithLabelValues(t.entity).
+					Add(float64(len(b.mismatches)))
+			}
+
+			// The end of the table wraps the next sampled run around.
+			if b.count < limit {
+				lastID = 0
+				break
+			}
+			lastID, remaining = b.lastID, remaining-b.count
+		}
+
+		v.cursors[t.entity] = lastID
+		verifyDrift.WithLabelValues(t.entity).Set(float64(mismatches))
+	}
+
+	return report, nil
+}
+
+// batch runs verify in a DB transaction at the indexed head, retrying
+// while the index is behind the node or the node head moves.
+func (v *Verifier) batch(
+	verify func(b *verifyBatch, lastID uint64, limit int) error,
+	lastID uint64,
+	limit int,
+) (*verifyBatch, error) {
+	for attempt := 1; ; attempt++ {
+		b := &verifyBatch{
+			ctx:    v.ctx,
+			node:   v.node,
+			repair: v.cfg.Repair,
+		}
+		err := v.db.Transaction(func(dbTx *gorm.DB) error {
+			if v.cfg.Repair {
+				if err := dbTx.Model(&orm.ChainStatus{}).
+					Where("id = 1").
+					Update("updated_at", time.Now()).
+					Error; err != nil {
+					return err
+				}
+			}
+
+			nextSlot, hash, err := chainStatus(dbTx)
+			if err != nil {
+				return err
+			}
+
+			if err := synced(v.ctx, v.node, nextSlot, hash); err != nil {
+				return err
+			}
+
+			b.dbTx, b.slot, b.hash = dbTx, nextSlot-1, hash
+			if err := verify(b, lastID, limit); err != nil {
+				return err
+			}
+
+			// The node state read by the batch is only consistent with
+			// the index if the node head did not move meanwhile.
+			return synced(v.ctx, v.node, nextSlot, hash)
+		})
+		if err == nil {
+			return b, nil
+		}
+
+		if err != errNotSynced || attempt == syncAttempts {
+			return nil, err
+		}
+
+		select {
+		case <-v.ctx.Done():
+			return nil, v.ctx.Err()
+
+		case <-time.After(syncWait):
+
+		}
+	}
+}
+
+// synced returns errNotSynced unless the node head is the last indexed
+// block, the only point where the node state matches the index.
+func synced(
+	ctx context.Context,
+	node chain.NodeClient,
+	nextSlot uint64,
+	hash string,
+) error {
+	cs, err := node.ChainStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	if nextSlot == 0 || cs.Best.Slot >= nextSlot || cs.Best.Hash != hash {
+		return errNotSynced
+	}
+
+	return nil
+}
+
+type verifyBatch struct {
+	ctx    context.Context
+	node   chain.NodeClient
+	dbTx   *gorm.DB
+	repair bool
+	// slot and hash are the indexed head.
+	slot       uint64
+	hash       string
+	lastID     uint64
+	count      int
+	mismatches []*Mismatch
+}
+
+func (b *verifyBatch) next(id uint64) {
+	b.lastID = id
+	b.count++
+}
+
+func (b *verifyBatch) mismatch(
+	entity string,
+	key string,
+	field string,
+	indexed interface{},
+	node interface{},
+) {
+	b.mismatches = append(b.mismatches, &Mismatch{
+		Slot:     b.slot,
+		Entity:   entity,
+		Key:      key,
+		Field:    field,
+		Indexed:  fmt.Sprint(indexed),
+		Node:     fmt.Sprint(node),
+		Repaired: b.repair,
+	})
+}
+
+// nodeError returns err if the node is unavailable. Any other error only
+// skips the row, as the per epoch refresh does.
+func nodeError(entity string, key string, err error) error {
+	if chain.IsUnavailable(err) {
+		return err
+	}
+
+	log.Warn("Error requesting the node state of an indexed row",
+		"entity", entity,
+		"key", key,
+		"error", err,
+	)
+	return nil
+}
+
+func (b *verifyBatch) accounts(lastID uint64, limit int) error {
+	as := make([]*orm.Account, 0)
+	if err := b.dbTx.Model(&orm.Account{}).
+		Where("id > ?", lastID).
+		Order("id asc").
+		Limit(limit).
+		Find(&as).
+		Error; err != nil {
+		return err
+	}
+
+	for _, a := range as {
+		b.next(a.ID)
+		remote, err := b.node.Account(b.ctx, a.PublicKey)
+		if err != nil {
+			if err := nodeError(entityAccount, a.PublicKey, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if remote.Nonce != a.Nonce {
+			b.mismatch(entityAccount, a.PublicKey, "nonce", a.Nonce, remote.Nonce)
+		}
+
+		if remote.Balance != a.Balance {
+			b.mismatch(
+				entityAccount,
+				a.PublicKey,
+				"balance",
+				a.Balance,
+				remote.Balance,
+			)
+		}
+
+		if !b.repair || remote.Nonce == a.Nonce && remote.Balance == a.Balance {
+			continue
+		}
+
+		j, err := headJournal(b.dbTx, b.hash, b.slot)
+		if err != nil {
+			return err
+		}
+
+		if err := resetAccountBalance(
+			b.ctx,
+			b.node,
+			b.dbTx,
+			j,
+			b.slot,
+			a.PublicKey,
+		); err != nil {
+			return err
+		}
+
+		if remote.Nonce == a.Nonce {
+			continue
+		}
+
+		if j != nil {
+			if err := j.accountByID(a.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := b.dbTx.Model(&orm.Account{}).
+			Where("id = ?", a.ID).
+			Update("nonce", remote.Nonce).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *verifyBatch) validators(lastID uint64, limit int) error {
+	vs := make([]*orm.Validator, 0)
+	if err := b.dbTx.Model(&orm.Validator{}).
+		Preload("Account").
+		Where("id > ?", lastID).
+		Order("id asc").
+		Limit(limit).
+		Find(&vs).
+		Error; err != nil {
+		return err
+	}
+
+	for _, v := range vs {
+		b.next(v.ID)
+		pk := v.Account.PublicKey
+		remote, err := b.node.Validator(b.ctx, pk)
+		if err != nil {
+			if err := nodeError(entityValidator, pk, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		updates := make(map[string]interface{})
+		if remote.Index != v.Index {
+			b.mismatch(entityValidator, pk, "index", v.Index, remote.Index)
+			updates["idx"] = remote.Index
+		}
+
+		if status := pbc.ValidatorStatus_value[remote.Status]; status != v.Status {
+			b.mismatch(
+				entityValidator,
+				pk,
+				"status",
+				pbc.ValidatorStatus(v.Status),
+				remote.Status,
+			)
+			updates["status"] = status
+		}
+
+		b.epochs(entityValidator, pk, updates,
+			v.ActivationEpoch, remote.ActivationEpoch,
+			v.ExitEpoch, remote.ExitEpoch,
+		)
+		if !b.repair || len(updates) == 0 {
+			continue
+		}
+
+		if err := b.dbTx.Model(&orm.Validator{}).
+			Where("id = ?", v.ID).
+			Updates(updates).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *verifyBatch) auditors(lastID uint64, limit int) error {
+	as := make([]*orm.Auditor, 0)
+	if err := b.dbTx.Model(&orm.Auditor{}).
+		Preload("Account").
+		Where("id > ?", lastID).
+		Order("id asc").
+		Limit(limit).
+		Find(&as).
+		Error; err != nil {
+		return err
+	}
+
+	for _, a := range as {
+		b.next(a.ID)
+		pk := a.Account.PublicKey
+		remote, err := b.node.Auditor(b.ctx, pk)
+		if err != nil {
+			if err := nodeError(entityAuditor, pk, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		updates := make(map[string]interface{})
+		if status := pbc.AuditorStatus_value[remote.Status]; status != a.Status {
+			b.mismatch(
+				entityAuditor,
+				pk,
+				"status",
+				pbc.AuditorStatus(a.Status),
+				remote.Status,
+			)
+			updates["status"] = status
+		}
+
+		b.epochs(entityAuditor, pk, updates,
+			a.ActivationEpoch, remote.ActivationEpoch,
+			a.ExitEpoch, remote.ExitEpoch,
+		)
+		if !b.repair || len(updates) == 0 {
+			continue
+		}
+
+		if err := b.dbTx.Model(&orm.Auditor{}).
+			Where("id = ?", a.ID).
+			Updates(updates).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// epochs compares the activation and exit epochs, the node ones capped
+// as stored.
+func (b *verifyBatch) epochs(
+	entity string,
+	pk string,
+	updates map[string]interface{},
+	activation uint64,
+	remoteActivation uint64,
+	exit uint64,
+	remoteExit uint64,
+) {
+	if remoteActivation = dbEpoch(remoteActivation); remoteActivation != activation {
+		b.mismatch(entity, pk, "activation_epoch", activation, remoteActivation)
+		updates["activation_epoch"] = remoteActivation
+	}
+
+	if remoteExit = dbEpoch(remoteExit); remoteExit != exit {
+		b.mismatch(entity, pk, "exit_epoch", exit, remoteExit)
+		updates["exit_epoch"] = remoteExit
+	}
+}
+
+func (b *verifyBatch) storageContracts(lastID uint64, limit int) error {
+	scs := make([]*orm.StorageContract, 0)
+	if err := b.dbTx.Model(&orm.StorageContract{}).
+		Preload("CommitTransaction").
+		Preload("Auditor").
+		Where("id > ?", lastID).
+		Order("id asc").
+		Limit(limit).
+		Find(&scs).
+		Error; err != nil {
+		return err
+	}
+
+	for _, sc := range scs {
+		b.next(sc.ID)
+		hash := sc.CommitTransaction.Hash
+		remote, err := b.node.StorageContract(b.ctx, hash, b.hash)
+		if err != nil {
+			if err := nodeError(entityStorageContract, hash, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		differs := false
+		if status := pbc.StorageStatus_value[remote.Status]; status != sc.Status {
+			differs = true
+			b.mismatch(
+				entityStorageContract,
+				hash,
+				"status",
+				pbc.StorageStatus(sc.Status),
+				remote.Status,
+			)
+		}
+
+		auditor := ""
+		if sc.Auditor != nil {
+			auditor = sc.Auditor.PublicKey
+		}
+
+		if remote.Auditor != "" && remote.Auditor != auditor {
+			b.mismatch(
+				entityStorageContract,
+				hash,
+				"auditor",
+				auditor,
+				remote.Auditor,
+			)
+			// refreshStorageContract only assigns a missing auditor.
+			differs, sc.AuditorID = true, 0
+		}
+
+		if !b.repair || !differs {
+			continue
+		}
+
+		j, err := headJournal(b.dbTx, b.hash, b.slot)
+		if err != nil {
+			return err
+		}
+
+		if err := refreshStorageContract(
+			b.ctx,
+			b.node,
+			b.dbTx,
+			j,
+			sc,
+			b.hash,
+			b.slot,
+			0,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-photon/chain/gateway"
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/chain/chaintest"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestVerify(t *testing.T) {
+	for _, repair := range []bool{false, true} {
+		t.Run(fmt.Sprintf("repair %t", repair), func(t *testing.T) {
+			db := newDB(t)
+			gw := chaintest.NewGateway()
+			defer gw.Close()
+
+			createAccount(t, db, alicePK, 1000)
+			createAccount(t, db, bobPK, 1000)
+			contract := &gateway.StorageResp{
+				Owner:      alicePK,
+				Depot:      bobPK,
+				ObjectHash: "object/c1",
+				Status:     pbc.StorageStatus_CREATED.String(),
+			}
+			gw.SetStorageContract("c1", contract)
+			gw.AddBlock(chaintest.WithTxs(commitTx("c1", alicePK, bobPK)))
+			head := gw.AddBlock()
+			indexSlots(t, gw.Client(), db)
+
+			bob, err := getAccountIDByPublicKey(db, bobPK)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Create(&orm.Validator{
+				AccountID: bob,
+				Index:     1,
+				Status:    int32(pbc.ValidatorStatus_VALIDATOR_ACTIVE),
+			}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			// The node disagrees on the nonce and balance of alice, the
+			// status of the validator and the status of the contract.
+			alice := account(t, db, alicePK)
+			gw.SetAccount(alicePK, &gateway.AccountResp{
+				Nonce:   alice.Nonce + 1,
+				Balance: alice.Balance + 50,
+			})
+			gw.SetAccount(bobPK, &gateway.AccountResp{
+				Nonce:   account(t, db, bobPK).Nonce,
+				Balance: account(t, db, bobPK).Balance,
+			})
+			gw.SetValidator(bobPK, &gateway.ValidatorResp{
+				PublicKey: bobPK,
+				Index:     1,
+				Status:    pbc.ValidatorStatus_VALIDATOR_EXITED.String(),
+			})
+			opened := *contract
+			opened.Status = pbc.StorageStatus_OPEN.String()
+			gw.SetStorageContract("c1", &opened)
+
+			v := NewVerifier(
+				context.Background(),
+				VerifyConfig{Repair: repair},
+				gw.Client(),
+				db,
+			)
+			report, err := v.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []string{
+				"accounts balance",
+				"accounts nonce",
+				"storage_contracts status",
+				"validators status",
+			}
+			if got := mismatches(report, repair); !reflect.DeepEqual(got, want) {
+				t.Fatalf("mismatches %v, want %v", got, want)
+			}
+
+			sc := &orm.StorageContract{}
+			if err := db.First(sc).Error; err != nil {
+				t.Fatal(err)
+			}
+			val := &orm.Validator{}
+			if err := db.First(val).Error; err != nil {
+				t.Fatal(err)
+			}
+			repaired := account(t, db, alicePK)
+			if got := repaired.Nonce == alice.Nonce+1 &&
+				repaired.Balance == alice.Balance+50 &&
+				val.Status == int32(pbc.ValidatorStatus_VALIDATOR_EXITED) &&
+				sc.Status == int32(pbc.StorageStatus_OPEN); got != repair {
+				t.Fatalf("alice %+v validator status %d contract status %d "+
+					"repaired %t, want %t",
+					repaired, val.Status, sc.Status, got, repair)
+			}
+
+			if !repair {
+				return
+			}
+
+			report, err = v.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Mismatches) != 0 {
+				t.Errorf("mismatches %v after the repair", mismatches(report, true))
+			}
+
+			if n := count(t, db, &orm.StorageContractStatus{},
+				"status = ?", int32(pbc.StorageStatus_OPEN)); n != 1 {
+				t.Errorf("%d contract transitions recorded, want 1", n)
+			}
+
+			// The repairs are journaled with the head block, rolling it
+			// back reverts them.
+			b := &orm.Block{}
+			if err := db.Where("hash = ?", head.BlockHash).First(b).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Transaction(func(dbTx *gorm.DB) error {
+				_, err := revertJournal(dbTx, b.ID)
+				return err
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := db.First(sc).Error; err != nil {
+				t.Fatal(err)
+			}
+			reverted := account(t, db, alicePK)
+			if reverted.Nonce != alice.Nonce || reverted.Balance != alice.Balance ||
+				sc.Status != int32(pbc.StorageStatus_CREATED) {
+				t.Errorf("alice %+v contract status %d after the rollback",
+					reverted, sc.Status)
+			}
+		})
+	}
+}
+
+// account returns the indexed account of the public key.
+func account(t *testing.T, db *gorm.DB, pk string) *orm.Account {
+	a := &orm.Account{}
+	if err := db.Where("public_key = ?", pk).First(a).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+// mismatches returns the entity and field of the reported mismatches,
+// sorted, noting the ones whose repaired flag differs from repaired.
+func mismatches(report *VerifyReport, repaired bool) []string {
+	fields := make([]string, 0)
+	for _, m := range report.Mismatches {
+		f := m.Entity + " " + m.Field
+		if m.Repaired != repaired {
+			f += " (repaired " + fmt.Sprint(m.Repaired) + ")"
+		}
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
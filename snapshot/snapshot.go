@@ -0,0 +1,440 @@
+// Package snapshot writes and reads the portable snapshots of the indexed
+// database, which bootstrap a new explorer replica without replaying the
+// chain from genesis.
+//
+// A snapshot is a gzip compressed tar archive. Its first entry is
+// manifest.json, describing the chain status and the schema version the
+// snapshot was taken at, followed by one <table>.jsonl entry per table
+// holding a JSON encoded row per line, in an order that satisfies the
+// foreign keys. The manifest records the number of rows and the sha256
+// checksum of every table entry.
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+const (
+	// FormatVersion is the version of the snapshot layout.
+	FormatVersion = 1
+
+	manifestFile = "manifest.json"
+	batchSize    = 500
+)
+
+var (
+	// ErrChecksum is returned by Import when a table entry differs from
+	// the manifest.
+	ErrChecksum = errors.New("snapshot checksum mismatch")
+	// ErrSchemaMismatch is returned by Import when the snapshot was taken
+	// at another schema version than the database.
+	ErrSchemaMismatch = errors.New("snapshot schema version mismatch")
+	// ErrNotEmpty is returned by Import when the database already has
+	// indexed rows.
+	ErrNotEmpty = errors.New("database is not empty")
+)
+
+// Table describes a table entry of a snapshot.
+type Table struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Rows   int64  `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes a snapshot.
+type Manifest struct {
+	Format        int       `json:"format"`
+	SchemaVersion uint64    `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	// NextSlot and CurrentHash are the chain status the indexer resumes
+	// from.
+	NextSlot      uint64   `json:"next_slot"`
+	CurrentHash   string   `json:"current_hash"`
+	FinalizedSlot uint64   `json:"finalized_slot"`
+	FinalizedHash string   `json:"finalized_hash"`
+	Tables        []*Table `json:"tables"`
+}
+
+// tables are the tables of a snapshot in insertion order, each with a
+// constructor of an empty slice of its rows. The state journal, the event
+// outbox and the webhooks are local to a replica and left out.
+var tables = []struct {
+	name string
+	rows func() interface{}
+}{
+	{"accounts", func() interface{} { return &[]*orm.Account{} }},
+	{"blocks", func() interface{} { return &[]*orm.Block{} }},
+	{"chain_status", func() interface{} { return &[]*orm.ChainStatus{} }},
+	{"account_ledgers", func() interface{} { return &[]*orm.AccountLedger{} }},
+	{"attestations", func() interface{} { return &[]*orm.Attestation{} }},
+	{"auditors", func() interface{} { return &[]*orm.Auditor{} }},
+	{"validators", func() interface{} { return &[]*orm.Validator{} }},
+	{"transactions", func() interface{} { return &[]*orm.Transaction{} }},
+	{"storage_contracts", func() interface{} { return &[]*orm.StorageContract{} }},
+	{"storage_contract_statuses", func() interface{} {
+		return &[]*orm.StorageContractStatus{}
+	}},
+	{"storage_proofs", func() interface{} { return &[]*orm.StorageProof{} }},
+	{"transaction_contracts", func() interface{} {
+		return &[]*orm.TransactionContract{}
+	}},
+	{"transaction_participants", func() interface{} {
+		return &[]*orm.TransactionParticipant{}
+	}},
+	{"validator_participations", func() interface{} {
+		return &[]*orm.ValidatorParticipation{}
+	}},
+	{"reorgs", func() interface{} { return &[]*orm.Reorg{} }},
+}
+
+// Export writes the snapshot of the rows visible to dbTx to w. dbTx
+// should be a transaction for the snapshot to be consistent. The table
+// entries are staged in temporary files, so that the manifest with their
+// checksums comes first in the archive.
+func Export(dbTx *gorm.DB, w io.Writer) (*Manifest, error) {
+	version, err := schemaVersion(dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := &orm.ChainStatus{}
+	if err := dbTx.Model(cs).First(cs).Error; err != nil {
+		return nil, errors.Wrap(err, "query chain status")
+	}
+
+	m := &Manifest{
+		Format:        FormatVersion,
+		SchemaVersion: version,
+		CreatedAt:     time.Now().UTC(),
+		NextSlot:      cs.NextSlot,
+		CurrentHash:   cs.CurrentHash,
+		FinalizedSlot: cs.FinalizedSlot,
+		FinalizedHash: cs.FinalizedHash,
+	}
+
+	files := make([]*os.File, 0, len(tables))
+	defer func() {
+		for _, f := range files {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	for _, t := range tables {
+		f, err := os.CreateTemp("", "snapshot-*.jsonl")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+
+		table, err := dump(dbTx, t.name, t.rows(), f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "export %s", t.name)
+		}
+		m.Tables = append(m.Tables, table)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeEntry(tw, manifestFile, int64(len(manifest)), m.CreatedAt,
+		bytes.NewReader(manifest)); err != nil {
+		return nil, err
+	}
+
+	for i, f := range files {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		if err := writeEntry(tw, m.Tables[i].File, info.Size(), m.CreatedAt,
+			f); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return m, gz.Close()
+}
+
+// dump writes the rows of a table to w as JSON lines in id order, soft
+// deleted rows included.
+func dump(
+	dbTx *gorm.DB,
+	name string,
+	rows interface{},
+	w io.Writer,
+) (*Table, error) {
+	table := &Table{Name: name, File: name + ".jsonl"}
+	h := sha256.New()
+	bw := bufio.NewWriter(io.MultiWriter(w, h))
+	enc := json.NewEncoder(bw)
+	if err := dbTx.Unscoped().
+		FindInBatches(rows, batchSize, func(_ *gorm.DB, _ int) error {
+			rs := reflect.ValueOf(rows).Elem()
+			for i := 0; i < rs.Len(); i++ {
+				if err := enc.Encode(rs.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			table.Rows += int64(rs.Len())
+			return nil
+		}).
+		Error; err != nil {
+		return nil, err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	table.SHA256 = checksum(h)
+
+	return table, nil
+}
+
+func writeEntry(
+	tw *tar.Writer,
+	name string,
+	size int64,
+	modTime time.Time,
+	r io.Reader,
+) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(tw, r)
+	return err
+}
+
+// Import loads the snapshot read from r into db, which must have the
+// schema version of the snapshot and no indexed rows. The rows keep
+// their ids and are inserted in a single transaction, rolled back if any
+// table differs from the manifest.
+func Import(db *gorm.DB, r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "read snapshot")
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	m, err := readManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTarget(db, m); err != nil {
+		return nil, err
+	}
+
+	byFile := make(map[string]*Table)
+	for _, t := range m.Tables {
+		byFile[t.File] = t
+	}
+
+	rows := make(map[string]func() interface{})
+	for _, t := range tables {
+		rows[t.name] = t.rows
+	}
+
+	if err := db.Transaction(func(dbTx *gorm.DB) error {
+		loaded := make(map[string]bool)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return errors.Wrap(err, "read snapshot")
+			}
+
+			t, ok := byFile[hdr.Name]
+			if !ok || loaded[t.Name] || rows[t.Name] == nil {
+				return fmt.Errorf("unexpected snapshot entry %s", hdr.Name)
+			}
+
+			if err := load(dbTx, t, rows[t.Name](), tr); err != nil {
+				return errors.Wrapf(err, "import %s", t.Name)
+			}
+			loaded[t.Name] = true
+		}
+
+		for _, t := range m.Tables {
+			if !loaded[t.Name] {
+				return fmt.Errorf("snapshot misses table %s", t.Name)
+			}
+		}
+
+		if dbTx.Dialector.Name() != "postgres" {
+			return nil
+		}
+
+		// Postgres sequences do not follow explicit ids.
+		for _, t := range m.Tables {
+			if err := dbTx.Exec(fmt.Sprintf(
+				`SELECT setval(pg_get_serial_sequence('%s', 'id'), `+
+					`COALESCE((SELECT MAX("id") FROM "%s"), 0) + 1, false)`,
+				t.Name,
+				t.Name,
+			)).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, errors.Wrap(err, "read snapshot")
+	}
+
+	if hdr.Name != manifestFile {
+		return nil, fmt.Errorf("snapshot starts with %s, not the manifest",
+			hdr.Name)
+	}
+
+	m := &Manifest{}
+	if err := json.NewDecoder(tr).Decode(m); err != nil {
+		return nil, errors.Wrap(err, "decode snapshot manifest")
+	}
+
+	if m.Format != FormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format %d", m.Format)
+	}
+
+	return m, nil
+}
+
+// checkTarget returns an error unless db has the schema version of the
+// snapshot and no row in the snapshot tables.
+func checkTarget(db *gorm.DB, m *Manifest) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	if version != m.SchemaVersion {
+		return errors.Wrapf(
+			ErrSchemaMismatch,
+			"snapshot %d, database %d",
+			m.SchemaVersion,
+			version,
+		)
+	}
+
+	for _, t := range tables {
+		count := int64(0)
+		if err := db.Table(t.name).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return errors.Wrapf(ErrNotEmpty, "%d rows in %s", count, t.name)
+		}
+	}
+
+	return nil
+}
+
+// load inserts the rows of a table entry read from r in batches, checking
+// the entry against the manifest.
+func load(dbTx *gorm.DB, t *Table, rows interface{}, r io.Reader) error {
+	h := sha256.New()
+	dec := json.NewDecoder(io.TeeReader(r, h))
+	rs := reflect.ValueOf(rows).Elem()
+	elem := rs.Type().Elem().Elem()
+	count := int64(0)
+	for {
+		row := reflect.New(elem)
+		err := dec.Decode(row.Interface())
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if err == nil {
+			rs.Set(reflect.Append(rs, row))
+			count++
+		}
+
+		if rs.Len() == batchSize || (err == io.EOF && rs.Len() > 0) {
+			if err := dbTx.Create(rows).Error; err != nil {
+				return err
+			}
+			rs.SetLen(0)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if count != t.Rows || checksum(h) != t.SHA256 {
+		return errors.Wrapf(ErrChecksum, "%d rows, manifest %d", count, t.Rows)
+	}
+
+	return nil
+}
+
+// schemaVersion returns the last migration applied to db.
+func schemaVersion(db *gorm.DB) (uint64, error) {
+	ss, err := migration.List(db)
+	if err != nil {
+		return 0, err
+	}
+
+	version := uint64(0)
+	for _, s := range ss {
+		if s.Applied {
+			version = s.Version
+		}
+	}
+
+	return version, nil
+}
+
+func checksum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
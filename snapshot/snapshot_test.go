@@ -0,0 +1,212 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/photon-explorer/database/migration"
+	"github.com/photon-storage/photon-explorer/database/orm"
+	"github.com/photon-storage/photon-explorer/database/sqlite"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := sqlite.NewSQLiteDB(sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migration.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestExportImport(t *testing.T) {
+	src := newDB(t)
+	for _, row := range []interface{}{
+		&orm.ChainStatus{
+			NextSlot:      3,
+			CurrentHash:   "b2",
+			FinalizedSlot: 1,
+			FinalizedHash: "b1",
+		},
+		&orm.Account{PublicKey: "alice", Balance: 100},
+		&orm.Account{PublicKey: "bob", Balance: 50, Nonce: 1},
+		&orm.Block{Slot: 1, Hash: "b1"},
+		&orm.Block{Slot: 2, Hash: "b2", ParentHash: "b1"},
+		&orm.Transaction{
+			BlockID:       2,
+			Hash:          "t1",
+			FromAccountID: 2,
+			Raw:           []byte("{}"),
+		},
+		&orm.StorageContract{
+			CommitTransactionID: 1,
+			OwnerID:             2,
+			DepotID:             1,
+			ObjectHash:          "o1",
+		},
+		&orm.Attestation{BlockID: 2, CommitteeIndex: 1},
+	} {
+		if err := src.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := src.Delete(&orm.Attestation{}, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	m, err := Export(src, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.NextSlot != 3 || m.CurrentHash != "b2" || m.SchemaVersion == 0 {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	dst := newDB(t)
+	if _, err := Import(dst, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := &orm.ChainStatus{}
+	if err := dst.First(cs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if cs.NextSlot != 3 || cs.CurrentHash != "b2" {
+		t.Errorf("chain status = %d %s, want 3 b2", cs.NextSlot, cs.CurrentHash)
+	}
+
+	bob := &orm.Account{}
+	if err := dst.Where("public_key = ?", "bob").First(bob).Error; err != nil {
+		t.Fatal(err)
+	}
+	if bob.ID != 2 || bob.Balance != 50 || bob.Nonce != 1 {
+		t.Errorf("bob = %+v", bob)
+	}
+
+	auditors := int64(0)
+	if err := dst.Model(&orm.StorageContract{}).
+		Where("auditor_id is null").
+		Count(&auditors).
+		Error; err != nil {
+		t.Fatal(err)
+	}
+	if auditors != 1 {
+		t.Errorf("contracts without auditor = %d, want 1", auditors)
+	}
+
+	at := &orm.Attestation{}
+	if err := dst.Unscoped().First(at).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !at.DeletedAt.Valid {
+		t.Errorf("soft deleted attestation restored")
+	}
+
+	// New rows continue after the imported ids.
+	carol := &orm.Account{PublicKey: "carol"}
+	if err := dst.Create(carol).Error; err != nil {
+		t.Fatal(err)
+	}
+	if carol.ID != 3 {
+		t.Errorf("new account id = %d, want 3", carol.ID)
+	}
+
+	if _, err := Import(dst, bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrNotEmpty) {
+		t.Errorf("import into a populated database error = %v", err)
+	}
+}
+
+func TestImportChecksum(t *testing.T) {
+	src := newDB(t)
+	if err := src.Create(&orm.ChainStatus{NextSlot: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Create(&orm.Account{PublicKey: "alice"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := Export(src, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := rewrite(t, buf.Bytes(), "accounts.jsonl", func(b []byte) []byte {
+		return bytes.Replace(b, []byte("alice"), []byte("mallory"), 1)
+	})
+
+	dst := newDB(t)
+	if _, err := Import(dst, bytes.NewReader(tampered)); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("import of a tampered snapshot error = %v", err)
+	}
+
+	count := int64(0)
+	if err := dst.Model(&orm.Account{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d accounts left by the failed import", count)
+	}
+}
+
+// rewrite returns the snapshot with the content of the named entry
+// replaced by edit.
+func rewrite(
+	t *testing.T,
+	snapshot []byte,
+	name string,
+	edit func([]byte) []byte,
+) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(snapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hdr.Name == name {
+			b = edit(b)
+		}
+
+		if err := writeEntry(tw, hdr.Name, int64(len(b)), time.Now(),
+			bytes.NewReader(b)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
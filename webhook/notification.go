@@ -0,0 +1,180 @@
+// Package webhook turns the events of the indexer outbox into
+// notifications and delivers them to the registered webhooks.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	pbc "github.com/photon-storage/photon-proto/consensus"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+// Kinds are the notification kinds a webhook can subscribe to.
+var Kinds = map[string]bool{
+	orm.WebhookTransaction:       true,
+	orm.WebhookFundsReceived:     true,
+	orm.WebhookContractStatus:    true,
+	orm.WebhookMissedAttestation: true,
+	orm.WebhookReorg:             true,
+}
+
+// Notification is an outbox event as seen by webhooks, with the fields
+// the webhook filters apply to.
+type Notification struct {
+	Kind     string
+	Accounts []string
+	Contract string
+	TxType   string
+	Data     json.RawMessage
+}
+
+// Payload is the JSON body posted to a webhook.
+type Payload struct {
+	EventID uint64          `json:"event_id"`
+	Kind    string          `json:"kind"`
+	Slot    uint64          `json:"slot"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Notifications returns the notifications of an outbox event, none for
+// the events webhooks can't subscribe to.
+func Notifications(e *orm.Event) ([]*Notification, error) {
+	switch e.Kind {
+	case orm.EventTransaction:
+		tx := &orm.TransactionEvent{}
+		if err := json.Unmarshal(e.Payload, tx); err != nil {
+			return nil, err
+		}
+
+		ns := []*Notification{{
+			Kind:     orm.WebhookTransaction,
+			Accounts: tx.Accounts,
+			TxType:   tx.Type,
+			Data:     e.Payload,
+		}}
+		if tx.Type == pbc.TxType_BALANCE_TRANSFER.String() && tx.To != "" {
+			ns = append(ns, &Notification{
+				Kind:     orm.WebhookFundsReceived,
+				Accounts: []string{tx.To},
+				TxType:   tx.Type,
+				Data:     e.Payload,
+			})
+		}
+
+		return ns, nil
+
+	case orm.EventContractStatus:
+		cs := &orm.ContractStatusEvent{}
+		if err := json.Unmarshal(e.Payload, cs); err != nil {
+			return nil, err
+		}
+
+		accounts := []string{cs.Owner, cs.Depot}
+		if cs.Auditor != "" {
+			accounts = append(accounts, cs.Auditor)
+		}
+
+		return []*Notification{{
+			Kind:     orm.WebhookContractStatus,
+			Accounts: accounts,
+			Contract: cs.Hash,
+			Data:     e.Payload,
+		}}, nil
+
+	case orm.EventMissedAttestation:
+		ma := &orm.MissedAttestationEvent{}
+		if err := json.Unmarshal(e.Payload, ma); err != nil {
+			return nil, err
+		}
+
+		return []*Notification{{
+			Kind:     orm.WebhookMissedAttestation,
+			Accounts: []string{ma.PublicKey},
+			Data:     e.Payload,
+		}}, nil
+
+	case orm.EventReorg:
+		return []*Notification{{
+			Kind: orm.WebhookReorg,
+			Data: e.Payload,
+		}}, nil
+	}
+
+	return nil, nil
+}
+
+// Match reports whether the filters of w select n. Reorgs only filter
+// on the kind, since any notification before them may be void.
+func Match(w *orm.Webhook, n *Notification) bool {
+	if !contains(List(w.EventKinds), n.Kind) {
+		return false
+	}
+
+	if n.Kind == orm.WebhookReorg {
+		return true
+	}
+
+	if accounts := List(w.Accounts); len(accounts) > 0 {
+		found := false
+		for _, a := range n.Accounts {
+			if contains(accounts, a) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if contracts := List(w.Contracts); len(contracts) > 0 && n.Contract != "" &&
+		!contains(contracts, n.Contract) {
+		return false
+	}
+
+	if types := List(w.TxTypes); len(types) > 0 && n.TxType != "" &&
+		!contains(types, n.TxType) {
+		return false
+	}
+
+	return true
+}
+
+// List splits a comma separated filter, dropping empty items.
+func List(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// Sign returns the signature of a delivery, the hex HMAC-SHA256 of the
+// timestamp and the body joined by a dot, keyed by the webhook secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+func TestNotifications(t *testing.T) {
+	payload, err := json.Marshal(&orm.TransactionEvent{
+		Hash:     "tx",
+		Type:     "BALANCE_TRANSFER",
+		From:     "alice",
+		To:       "bob",
+		Accounts: []string{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns, err := Notifications(&orm.Event{
+		Kind:    orm.EventTransaction,
+		Payload: payload,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ns) != 2 ||
+		ns[0].Kind != orm.WebhookTransaction ||
+		ns[1].Kind != orm.WebhookFundsReceived ||
+		len(ns[1].Accounts) != 1 || ns[1].Accounts[0] != "bob" {
+		t.Fatalf("unexpected notifications %+v", ns)
+	}
+
+	ns, err = Notifications(&orm.Event{Kind: orm.EventBlock})
+	if err != nil || len(ns) != 0 {
+		t.Fatalf("block event gave notifications %+v, error %v", ns, err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	funds := &Notification{
+		Kind:     orm.WebhookFundsReceived,
+		Accounts: []string{"bob"},
+		TxType:   "BALANCE_TRANSFER",
+	}
+	status := &Notification{
+		Kind:     orm.WebhookContractStatus,
+		Accounts: []string{"alice", "depot"},
+		Contract: "c1",
+	}
+	reorg := &Notification{Kind: orm.WebhookReorg}
+
+	testCases := []struct {
+		name string
+		hook *orm.Webhook
+		n    *Notification
+		want bool
+	}{
+		{
+			name: "kind not subscribed",
+			hook: &orm.Webhook{EventKinds: "contract_status"},
+			n:    funds,
+			want: false,
+		},
+		{
+			name: "watched account",
+			hook: &orm.Webhook{EventKinds: "funds_received", Accounts: "carol, bob"},
+			n:    funds,
+			want: true,
+		},
+		{
+			name: "other account",
+			hook: &orm.Webhook{EventKinds: "funds_received", Accounts: "carol"},
+			n:    funds,
+			want: false,
+		},
+		{
+			name: "other tx type",
+			hook: &orm.Webhook{EventKinds: "funds_received", TxTypes: "OBJECT_COMMIT"},
+			n:    funds,
+			want: false,
+		},
+		{
+			name: "contract filter ignored without contract",
+			hook: &orm.Webhook{EventKinds: "funds_received", Contracts: "c1"},
+			n:    funds,
+			want: true,
+		},
+		{
+			name: "watched contract",
+			hook: &orm.Webhook{EventKinds: "contract_status", Contracts: "c1"},
+			n:    status,
+			want: true,
+		},
+		{
+			name: "other contract",
+			hook: &orm.Webhook{EventKinds: "contract_status", Contracts: "c2"},
+			n:    status,
+			want: false,
+		},
+		{
+			name: "reorg ignores filters",
+			hook: &orm.Webhook{EventKinds: "reorg", Accounts: "carol"},
+			n:    reorg,
+			want: true,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Match(c.hook, c.n); got != c.want {
+				t.Errorf("match = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	a := Sign("secret", 1, []byte("{}"))
+	if a != Sign("secret", 1, []byte("{}")) {
+		t.Fatal("signature is not deterministic")
+	}
+
+	if a == Sign("other", 1, []byte("{}")) ||
+		a == Sign("secret", 2, []byte("{}")) ||
+		a == Sign("secret", 1, []byte("[]")) {
+		t.Fatal("signature ignores an input")
+	}
+}
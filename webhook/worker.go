@@ -0,0 +1,345 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/photon-storage/go-common/log"
+
+	"github.com/photon-storage/photon-explorer/database/orm"
+)
+
+const (
+	batchSize      = 100
+	maxErrorLength = 512
+)
+
+// Config defines the webhook delivery configuration.
+type Config struct {
+	// PollInterval is the interval the outbox and the pending deliveries
+	// are polled at.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// Timeout bounds a single delivery attempt.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAttempts is the number of attempts before a delivery is dead.
+	MaxAttempts int `yaml:"max_attempts"`
+	// InitialBackoff is the delay before the first retry. It doubles on
+	// every retry up to MaxBackoff.
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+}
+
+// DefaultConfig returns the webhook configuration used when a field is
+// not set.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:   2 * time.Second,
+		Timeout:        10 * time.Second,
+		MaxAttempts:    8,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Hour,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.PollInterval == 0 {
+		c.PollInterval = d.PollInterval
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = d.Timeout
+	}
+
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = d.MaxAttempts
+	}
+
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = d.InitialBackoff
+	}
+
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = d.MaxBackoff
+	}
+
+	return c
+}
+
+// Worker turns the committed outbox events into deliveries of the
+// matching webhooks and sends them.
+type Worker struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    Config
+	db     *gorm.DB
+	client *http.Client
+}
+
+// NewWorker returns the new instance of Worker.
+func NewWorker(ctx context.Context, cfg Config, db *gorm.DB) *Worker {
+	ctx, cancel := context.WithCancel(ctx)
+	cfg = cfg.withDefaults()
+	return &Worker{
+		ctx:    ctx,
+		cancel: cancel,
+		cfg:    cfg,
+		db:     db,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Run polls until the worker is stopped.
+func (w *Worker) Run() {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+
+		case <-ticker.C:
+
+		}
+
+		for {
+			n, err := w.enqueue()
+			if err != nil {
+				log.Error("Error enqueuing webhook deliveries", "error", err)
+				break
+			}
+
+			if n < batchSize {
+				break
+			}
+		}
+
+		if err := w.deliver(); err != nil {
+			log.Error("Error delivering webhooks", "error", err)
+		}
+	}
+}
+
+// Stop exits the worker.
+func (w *Worker) Stop() {
+	w.cancel()
+}
+
+// enqueue creates the deliveries of the next batch of outbox events and
+// advances the cursor in the same transaction. It returns the number of
+// events read.
+func (w *Worker) enqueue() (int, error) {
+	count := 0
+	err := w.db.Transaction(func(dbTx *gorm.DB) error {
+		cursor := &orm.WebhookCursor{}
+		if err := dbTx.Model(&orm.WebhookCursor{}).
+			First(cursor).
+			Error; err == gorm.ErrRecordNotFound {
+			// Start from the current end of the outbox rather than
+			// notifying about history.
+			if err := dbTx.Model(&orm.Event{}).
+				Select("coalesce(max(id), 0)").
+				Scan(&cursor.LastEventID).
+				Error; err != nil {
+				return err
+			}
+
+			return dbTx.Model(&orm.WebhookCursor{}).Create(cursor).Error
+		} else if err != nil {
+			return err
+		}
+
+		es := make([]*orm.Event, 0)
+		if err := dbTx.Model(&orm.Event{}).
+			Where("id > ?", cursor.LastEventID).
+			Order("id asc").
+			Limit(batchSize).
+			Find(&es).
+			Error; err != nil {
+			return err
+		}
+
+		count = len(es)
+		if count == 0 {
+			return nil
+		}
+
+		hooks := make([]*orm.Webhook, 0)
+		if err := dbTx.Model(&orm.Webhook{}).Find(&hooks).Error; err != nil {
+			return err
+		}
+
+		for _, e := range es {
+			ns, err := Notifications(e)
+			if err != nil {
+				log.Warn("Skipping malformed outbox event",
+					"event_id", e.ID,
+					"error", err,
+				)
+				continue
+			}
+
+			for _, n := range ns {
+				for _, h := range hooks {
+					if !Match(h, n) {
+						continue
+					}
+
+					body, err := json.Marshal(&Payload{
+						EventID: e.ID,
+						Kind:    n.Kind,
+						Slot:    e.Slot,
+						Data:    n.Data,
+					})
+					if err != nil {
+						return err
+					}
+
+					if err := dbTx.Model(&orm.WebhookDelivery{}).
+						Create(&orm.WebhookDelivery{
+							WebhookID:     h.ID,
+							EventID:       e.ID,
+							Kind:          n.Kind,
+							Payload:       body,
+							Status:        orm.DeliveryPending,
+							NextAttemptAt: time.Now(),
+						}).
+						Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return dbTx.Model(&orm.WebhookCursor{}).
+			Where("id = ?", cursor.ID).
+			Update("last_event_id", es[len(es)-1].ID).
+			Error
+	})
+
+	return count, err
+}
+
+// deliver sends the pending deliveries that are due.
+func (w *Worker) deliver() error {
+	ds := make([]*orm.WebhookDelivery, 0)
+	if err := w.db.Model(&orm.WebhookDelivery{}).
+		Preload("Webhook").
+		Where("status = ? and next_attempt_at <= ?",
+			orm.DeliveryPending,
+			time.Now(),
+		).
+		Order("id asc").
+		Limit(batchSize).
+		Find(&ds).
+		Error; err != nil {
+		return err
+	}
+
+	for _, d := range ds {
+		select {
+		case <-w.ctx.Done():
+			return nil
+
+		default:
+
+		}
+
+		updates := map[string]interface{}{"attempts": d.Attempts + 1}
+		if d.Webhook == nil {
+			// The webhook was deleted.
+			updates["status"] = orm.DeliveryDead
+			updates["last_error"] = "webhook deleted"
+		} else if code, err := w.send(d); err == nil {
+			updates["status"] = orm.DeliveryDelivered
+			updates["response_code"] = code
+			updates["last_error"] = ""
+		} else {
+			msg := err.Error()
+			if len(msg) > maxErrorLength {
+				msg = msg[:maxErrorLength]
+			}
+
+			updates["response_code"] = code
+			updates["last_error"] = msg
+			if d.Attempts+1 >= w.cfg.MaxAttempts {
+				updates["status"] = orm.DeliveryDead
+				log.Warn("Webhook delivery dead",
+					"webhook_id", d.WebhookID,
+					"delivery_id", d.ID,
+					"error", err,
+				)
+			} else {
+				updates["next_attempt_at"] = time.Now().
+					Add(w.backoff(d.Attempts + 1))
+			}
+		}
+
+		if err := w.db.Model(&orm.WebhookDelivery{}).
+			Where("id = ?", d.ID).
+			Updates(updates).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send posts a delivery, signed with the webhook secret. It returns the
+// response status code, 0 if there was no response.
+func (w *Worker) send(d *orm.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(w.ctx, w.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		d.Webhook.URL,
+		bytes.NewReader(d.Payload),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Photon-Event", d.Kind)
+	req.Header.Set("X-Photon-Delivery", strconv.FormatUint(d.ID, 10))
+	req.Header.Set("X-Photon-Timestamp", strconv.FormatInt(ts, 10))
+	req.Header.Set("X-Photon-Signature",
+		"sha256="+Sign(d.Webhook.Secret, ts, d.Payload),
+	)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the given retry attempt, doubling from
+// InitialBackoff up to MaxBackoff.
+func (w *Worker) backoff(attempt int) time.Duration {
+	d := w.cfg.InitialBackoff << (attempt - 1)
+	if d <= 0 || d > w.cfg.MaxBackoff {
+		d = w.cfg.MaxBackoff
+	}
+
+	return d
+}